@@ -1,17 +1,28 @@
 package api
 
 import (
+	"fmt"
+	"net/http"
+
+	"github.com/ficontini/gotasks/service"
 	"github.com/ficontini/gotasks/types"
 	"github.com/gofiber/fiber/v2"
 )
 
-func AdminAuth(c *fiber.Ctx) error {
-	user, ok := c.Context().UserValue("user").(*types.User)
-	if !ok {
-		return ErrUnAuthorized()
-	}
-	if !user.IsAdmin {
-		return ErrUnAuthorized()
+// RequirePermission lets users through when one of their roles grants the permission.
+// Personal access tokens have no scope for it, so they are always rejected.
+func RequirePermission(policy *service.Policy, permission types.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Context().UserValue("user").(*types.User)
+		if !ok {
+			return ErrUnAuthorized()
+		}
+		if err := checkScope(c); err != nil {
+			return err
+		}
+		if err := policy.Authorize(user, permission); err != nil {
+			return NewError(http.StatusForbidden, fmt.Sprintf("user is missing the %s permission", permission))
+		}
+		return c.Next()
 	}
-	return c.Next()
 }
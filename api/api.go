@@ -25,17 +25,29 @@ func NewResourceResponse(data any, results int, page int64) ResourceResponse {
 }
 
 type Handler struct {
-	Auth    *AuthHandler
-	User    *UserHandler
-	Task    *TaskHandler
-	Project *ProjectHandler
+	Auth      *AuthHandler
+	SSO       *SSOHandler
+	User      *UserHandler
+	Task      *TaskHandler
+	Project   *ProjectHandler
+	Milestone *MilestoneHandler
+	Template  *TemplateHandler
+	Team      *TeamHandler
+	Role      *RoleHandler
+	Workspace *WorkspaceHandler
 }
 
 func NewHandler(svc *service.Service) *Handler {
 	return &Handler{
-		Auth:    NewAuthHandler(svc.Auth),
-		User:    NewUserHandler(svc.User),
-		Task:    NewTaskHandler(svc.Task),
-		Project: NewProjectHandler(svc.Project),
+		Auth:      NewAuthHandler(svc.Auth),
+		SSO:       NewSSOHandler(svc.SSO, svc.Auth),
+		User:      NewUserHandler(svc.User),
+		Task:      NewTaskHandler(svc.Task),
+		Project:   NewProjectHandler(svc.Project),
+		Milestone: NewMilestoneHandler(svc.Milestone),
+		Template:  NewTemplateHandler(svc.Template),
+		Team:      NewTeamHandler(svc.Team),
+		Role:      NewRoleHandler(svc.Policy, svc.User),
+		Workspace: NewWorkspaceHandler(svc.Workspace),
 	}
 }
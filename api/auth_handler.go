@@ -2,7 +2,9 @@ package api
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/ficontini/gotasks/service"
 	"github.com/ficontini/gotasks/types"
@@ -28,24 +30,130 @@ func (h *AuthHandler) HandleAuthenticate(c *fiber.Ctx) error {
 	if errors := params.Validate(); len(errors) > 0 {
 		return c.Status(http.StatusBadRequest).JSON(errors)
 	}
-	auth, err := h.authService.AuthenticateUser(c.Context(), &params)
+	params.IP = c.IP()
+	params.UserAgent = c.Get(fiber.HeaderUserAgent)
+	auth, challenge, err := h.authService.AuthenticateUser(c.Context(), &params)
 	if err != nil {
+		var lockout *service.LoginLockoutError
 		switch {
+		case errors.As(err, &lockout):
+			return tooManyAttempts(c, lockout)
 		case errors.Is(err, service.ErrInvalidCredentials):
 			return ErrInvalidCredentials()
 		case errors.Is(err, service.ErrForbidden):
 			return ErrForbidden()
+		case errors.Is(err, service.ErrEmailNotVerified):
+			return ErrEmailNotVerified()
 
 		default:
 			return err
 		}
 	}
+	if challenge != nil {
+		return c.JSON(challenge)
+	}
+	return sendTokens(c, h.authService, auth)
+}
+func (h *AuthHandler) HandleAuthenticateTwoFactor(c *fiber.Ctx) error {
+	var params types.TwoFactorAuthParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	params.IP = c.IP()
+	params.UserAgent = c.Get(fiber.HeaderUserAgent)
+	auth, err := h.authService.AuthenticateTwoFactor(c.Context(), &params)
+	if err != nil {
+		var lockout *service.LoginLockoutError
+		switch {
+		case errors.As(err, &lockout):
+			return tooManyAttempts(c, lockout)
+		case errors.Is(err, service.ErrInvalidChallengeToken),
+			errors.Is(err, service.ErrInvalidTwoFactorCode):
+			return NewError(http.StatusUnauthorized, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return ErrForbidden()
+		default:
+			return err
+		}
+	}
+	return sendTokens(c, h.authService, auth)
+}
+func tooManyAttempts(c *fiber.Ctx, lockout *service.LoginLockoutError) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(lockout.RetryAfter.Seconds()))))
+	return NewError(http.StatusTooManyRequests, lockout.Error())
+}
+func (h *AuthHandler) HandleRefresh(c *fiber.Ctx) error {
+	var params types.RefreshParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	auth, err := h.authService.RefreshAuth(c.Context(), params.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRefreshToken),
+			errors.Is(err, service.ErrRefreshTokenReused):
+			return NewError(http.StatusUnauthorized, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return ErrForbidden()
+		default:
+			return err
+		}
+	}
+	return sendTokens(c, h.authService, auth)
+}
+
+// HandleImpersonate signs the admin in as the user, without a refresh token.
+func (h *AuthHandler) HandleImpersonate(c *fiber.Ctx) error {
+	admin, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	auth, err := h.authService.Impersonate(c.Context(), admin, c.Params("id"), c.IP(), c.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			return ErrResourceNotFound(err.Error())
+		case errors.Is(err, service.ErrImpersonationForbidden):
+			return NewError(http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return ErrForbidden()
+		default:
+			return err
+		}
+	}
 	token, err := h.authService.CreateTokenFromAuth(auth)
 	if err != nil {
 		return err
 	}
+	return c.JSON(&types.ImpersonationResponse{
+		Token:          token,
+		ExpirationTime: auth.ExpirationTime,
+	})
+}
+func sendTokens(c *fiber.Ctx, authService service.AuthServicer, auth *types.Auth) error {
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		return err
+	}
+	refreshToken, err := authService.CreateRefreshToken(auth)
+	if err != nil {
+		return err
+	}
 	resp := &types.AuthResponse{
-		Token: token,
+		Token:        token,
+		RefreshToken: refreshToken,
 	}
 	return c.JSON(resp)
 }
+
+// HandleGetJWKS publishes the keys access tokens can be verified with.
+func (h *AuthHandler) HandleGetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.authService.JWKS())
+}
@@ -2,16 +2,27 @@ package api
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ficontini/gotasks/db/fixtures"
+	"github.com/ficontini/gotasks/mailer"
 	"github.com/ficontini/gotasks/service"
 	"github.com/ficontini/gotasks/types"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestAuthenticateSuccess(t *testing.T) {
@@ -23,7 +34,7 @@ func TestAuthenticateSuccess(t *testing.T) {
 		store       = db.Store()
 		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		authHandler = NewAuthHandler(authService)
 		params      = types.AuthParams{
 			Email:    user.Email,
@@ -52,7 +63,7 @@ func TestAuthenticateWrongWithPasswordFailure(t *testing.T) {
 		store       = db.Store()
 		user        = fixtures.AddUser(store, "james", "foo", "supersecurepassword", false, true)
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		authHandler = NewAuthHandler(authService)
 		params      = types.AuthParams{
 			Email:    user.Email,
@@ -65,3 +76,285 @@ func TestAuthenticateWrongWithPasswordFailure(t *testing.T) {
 	resp := testRequest(t, app, req)
 	checkStatusCode(t, http.StatusUnauthorized, resp.StatusCode)
 }
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+
+	var (
+		password    = "supersecurepassword"
+		store       = db.Store()
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		authHandler = NewAuthHandler(authService)
+		params      = types.AuthParams{
+			Email:    user.Email,
+			Password: password,
+		}
+	)
+	app.Post("/auth", authHandler.HandleAuthenticate)
+	app.Post("/auth/refresh", authHandler.HandleRefresh)
+	req := makeUnauthenticatedRequest(http.MethodPost, "/auth", bytes.NewReader(marshallParamsToJSON(t, params)))
+	resp := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, resp.StatusCode)
+	var first types.AuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&first); err != nil {
+		t.Fatal(err)
+	}
+
+	refresh := types.RefreshParams{RefreshToken: first.RefreshToken}
+	req = makeUnauthenticatedRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(marshallParamsToJSON(t, refresh)))
+	resp = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, resp.StatusCode)
+	var second types.AuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&second); err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Token) == 0 || second.RefreshToken == first.RefreshToken {
+		t.Fatal("expected a new token pair in the refresh response")
+	}
+
+	req = makeUnauthenticatedRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(marshallParamsToJSON(t, refresh)))
+	resp = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, resp.StatusCode)
+
+	refresh = types.RefreshParams{RefreshToken: second.RefreshToken}
+	req = makeUnauthenticatedRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(marshallParamsToJSON(t, refresh)))
+	resp = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestRotateSigningKey(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	defer service.UseKeySet(nil)
+
+	var (
+		store       = db.Store()
+		user        = fixtures.AddUser(store, "james", "foo", "supersecurepassword", false, true)
+		auth        = fixtures.AddAuth(store, user.ID)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		authHandler = NewAuthHandler(authService)
+	)
+	app.Get("/.well-known/jwks.json", authHandler.HandleGetJWKS)
+	app.Get("/me", JWTAuthentication(authService), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("supersecuresecret")
+	ks, err := service.NewKeySet(secret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.UseKeySet(ks)
+	legacyToken, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ks, err = service.NewKeySet(secret, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.UseKeySet(ks)
+	res := testRequest(t, app, makeRequest(http.MethodGet, "/me", legacyToken, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	oldToken, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ks, err = service.NewKeySet(nil, newKey, oldKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.UseKeySet(ks)
+	res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var jwks types.JWKS
+	if err := json.NewDecoder(res.Body).Decode(&jwks); err != nil {
+		t.Fatal(err)
+	}
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected 2 published keys, got %d", len(jwks.Keys))
+	}
+	newToken, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{oldToken, newToken} {
+		res = testRequest(t, app, makeRequest(http.MethodGet, "/me", token, nil))
+		checkStatusCode(t, http.StatusOK, res.StatusCode)
+	}
+
+	ks, err = service.NewKeySet(nil, newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.UseKeySet(ks)
+	for _, token := range []string{oldToken, legacyToken} {
+		res = testRequest(t, app, makeRequest(http.MethodGet, "/me", token, nil))
+		checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+	}
+}
+func TestEmptySecretRejectsPurposeTokens(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	t.Setenv(service.JWTSecretEnvName, "")
+
+	var (
+		store       = db.Store()
+		user        = fixtures.AddUser(store, "james", "foo", "supersecurepassword", false, true)
+		auth        = fixtures.AddAuth(store, user.ID)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		authHandler = NewAuthHandler(authService)
+	)
+	if _, err := service.NewKeySetFromEnv(); err == nil {
+		t.Fatal("expected the key set to require the JWT secret")
+	}
+	app.Post("/auth/refresh", authHandler.HandleRefresh)
+	mac := hmac.New(sha256.New, []byte(""))
+	mac.Write([]byte("refresh"))
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"auth_uuid": auth.AuthUUID,
+		"gen":       auth.RefreshGeneration,
+		"sub":       user.ID,
+		"aud":       "refresh",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}).SignedString(mac.Sum(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh := types.RefreshParams{RefreshToken: forged}
+	req := makeUnauthenticatedRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(marshallParamsToJSON(t, refresh)))
+	resp := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, resp.StatusCode)
+}
+func TestFailedLoginsLockAccountUntilAdminUnlocks(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	defer func(policy types.LockoutPolicy) { service.EmailLockoutPolicy = policy }(service.EmailLockoutPolicy)
+	service.EmailLockoutPolicy = types.LockoutPolicy{
+		FreeAttempts:     1,
+		LockoutThreshold: 3,
+		LockoutDuration:  time.Hour,
+	}
+
+	var (
+		password    = "supersecurepassword"
+		store       = db.Store()
+		mailbox     = &bytes.Buffer{}
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		adminUser   = fixtures.AddUser(store, "admin", "foo", password, true, true)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(mailbox))
+		authHandler = NewAuthHandler(authService)
+		userHandler = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+		admin       = app.Group("/admin", JWTAuthentication(authService), RequirePermission(service.DefaultPolicy, types.PermissionUserUnlock))
+	)
+	app.Post("/auth", authHandler.HandleAuthenticate)
+	admin.Delete("/user/:id/lockout", userHandler.HandleAdminUnlockUser)
+	authenticate := func(password string) *http.Response {
+		params := types.AuthParams{Email: user.Email, Password: password}
+		return testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/auth", bytes.NewReader(marshallParamsToJSON(t, params))))
+	}
+
+	for i := 0; i < service.EmailLockoutPolicy.LockoutThreshold; i++ {
+		checkStatusCode(t, http.StatusUnauthorized, authenticate("wrongpassword").StatusCode)
+	}
+	if !strings.Contains(mailbox.String(), "locked") {
+		t.Fatalf("expected the owner to be notified of the lockout, got %q", mailbox.String())
+	}
+	res := authenticate(password)
+	checkStatusCode(t, http.StatusTooManyRequests, res.StatusCode)
+	if len(res.Header.Get(fiber.HeaderRetryAfter)) == 0 {
+		t.Fatal("expected the Retry-After header to be set")
+	}
+
+	token, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, adminUser.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res = testRequest(t, app, makeRequest(http.MethodDelete, fmt.Sprintf("/admin/user/%s/lockout", user.ID), token, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	checkStatusCode(t, http.StatusOK, authenticate(password).StatusCode)
+}
+func TestAdminImpersonationIsFlaggedAndLimited(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		password    = "supersecurepassword"
+		store       = db.Store()
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		adminUser   = fixtures.AddUser(store, "admin", "foo", password, true, true)
+		otherAdmin  = fixtures.AddUser(store, "alice", "foo", password, true, true)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		authHandler = NewAuthHandler(authService)
+		userHandler = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+		apiv1       = app.Group("/api", JWTAuthentication(authService))
+	)
+	apiv1.Post("/admin/user/:id/impersonate", RequirePermission(service.DefaultPolicy, types.PermissionUserImpersonate), authHandler.HandleImpersonate)
+	apiv1.Get("/user", userHandler.HandleGetUser)
+	apiv1.Post("/user/tokens", DenyImpersonation, userHandler.HandlePostAccessToken)
+	apiv1.Delete("/user/tokens/:id", DenyImpersonation, userHandler.HandleDeleteAccessToken)
+	apiv1.Delete("/user/sessions/:uuid", DenyImpersonation, userHandler.HandleDeleteSession)
+	apiv1.Post("/auth/logout", DenyImpersonation, userHandler.HandleLogout)
+
+	adminToken, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, adminUser.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := testRequest(t, app, makeRequest(http.MethodPost, fmt.Sprintf("/api/admin/user/%s/impersonate", otherAdmin.ID), adminToken, nil))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodPost, fmt.Sprintf("/api/admin/user/%s/impersonate", user.ID), adminToken, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var impersonation types.ImpersonationResponse
+	if err := json.NewDecoder(res.Body).Decode(&impersonation); err != nil {
+		t.Fatal(err)
+	}
+	if impersonation.ExpirationTime > time.Now().Add(types.ImpersonationTTL).Unix() {
+		t.Fatalf("expected the impersonation to end within %s", types.ImpersonationTTL)
+	}
+
+	res = testRequest(t, app, makeRequest(http.MethodGet, "/api/user", impersonation.Token, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var current types.CurrentUser
+	if err := json.NewDecoder(res.Body).Decode(&current); err != nil {
+		t.Fatal(err)
+	}
+	if current.ID != user.ID || !current.Impersonated || current.ImpersonatedBy != adminUser.ID {
+		t.Fatalf("expected %s impersonated by %s, got %+v", user.ID, adminUser.ID, current)
+	}
+	params := types.CreateAccessTokenParams{Name: "ci", Scopes: []types.Scope{types.ScopeTasksRead}}
+	res = testRequest(t, app, makeRequest(http.MethodPost, "/api/user/tokens", impersonation.Token, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+
+	userAuth := fixtures.AddAuth(store, user.ID)
+	userToken, err := authService.CreateTokenFromAuth(userAuth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, route := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodDelete, "/api/user/tokens/000000000000000000000000"},
+		{http.MethodDelete, "/api/user/sessions/" + userAuth.AuthUUID},
+		{http.MethodPost, "/api/auth/logout"},
+	} {
+		res = testRequest(t, app, makeRequest(route.method, route.path, impersonation.Token, nil))
+		checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+	}
+	res = testRequest(t, app, makeRequest(http.MethodGet, "/api/user", userToken, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+}
@@ -49,6 +49,9 @@ func ErrInvalidCredentials() Error {
 func ErrForbidden() Error {
 	return NewError(http.StatusForbidden, "user account is not enabled. Please contact the administrator for assistance.")
 }
+func ErrEmailNotVerified() Error {
+	return NewError(http.StatusForbidden, "email address is not verified. Please follow the link sent to your email.")
+}
 func ErrConflict(msg string) Error {
 	return NewError(http.StatusConflict, msg)
 }
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// DenyImpersonation rejects impersonation sessions on the routes changing how the
+// user signs in, which only the user can do.
+func DenyImpersonation(c *fiber.Ctx) error {
+	if auth, ok := c.Context().UserValue("auth").(*types.Auth); ok && auth.IsImpersonation() {
+		return NewError(http.StatusForbidden, "impersonation sessions can't be used for this route")
+	}
+	return c.Next()
+}
+
+// auditImpersonation logs every request of an impersonation session, attributed to
+// both the admin and the impersonated user.
+func auditImpersonation(c *fiber.Ctx, auth *types.Auth) error {
+	err := c.Next()
+	entry := logrus.WithFields(logrus.Fields{
+		"userID":         auth.UserID,
+		"impersonatorID": auth.ImpersonatorID,
+		"method":         c.Method(),
+		"path":           c.Path(),
+		"status":         c.Response().StatusCode(),
+	})
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+	entry.Warn("Impersonated request")
+	return err
+}
@@ -1,7 +1,11 @@
 package api
 
 import (
+	"strings"
+
+	"github.com/ficontini/gotasks/db"
 	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
@@ -15,8 +19,11 @@ func JWTAuthentication(authService service.AuthServicer) fiber.Handler {
 			logrus.Error("token is not present in the header")
 			return ErrUnAuthorized()
 		}
-		tokenStr := token[0]
-		claims, err := authService.ValidateToken(tokenStr[len("Bearer "):])
+		tokenStr := token[0][len("Bearer "):]
+		if strings.HasPrefix(tokenStr, types.AccessTokenPrefix) {
+			return authenticateAccessToken(c, authService, tokenStr)
+		}
+		claims, err := authService.ValidateToken(tokenStr)
 		if err != nil {
 			return ErrUnAuthorized()
 		}
@@ -25,11 +32,32 @@ func JWTAuthentication(authService service.AuthServicer) fiber.Handler {
 			return ErrUnAuthorized()
 		}
 		c.Context().SetUserValue("user", user)
+		c.Context().SetUserValue(db.WorkspaceContextKey, user.WorkspaceID)
 		auth, err := authService.GetAuth(c.Context(), claims)
 		if err != nil {
 			return ErrUnAuthorized()
 		}
+		if impersonator, _ := claims["impersonator"].(string); impersonator != auth.ImpersonatorID {
+			return ErrUnAuthorized()
+		}
 		c.Context().SetUserValue("auth", auth)
+		if auth.IsImpersonation() {
+			return auditImpersonation(c, auth)
+		}
 		return c.Next()
 	}
 }
+
+// authenticateAccessToken signs the request in with a personal access token. Its
+// auth has no session, and only routes requiring one of its scopes accept it.
+func authenticateAccessToken(c *fiber.Ctx, authService service.AuthServicer, tokenStr string) error {
+	user, token, err := authService.AuthenticateAccessToken(c.Context(), tokenStr)
+	if err != nil {
+		return ErrUnAuthorized()
+	}
+	c.Context().SetUserValue("user", user)
+	c.Context().SetUserValue(db.WorkspaceContextKey, user.WorkspaceID)
+	c.Context().SetUserValue("auth", &types.Auth{UserID: user.ID, ExpirationTime: token.ExpirationTime})
+	c.Context().SetUserValue("accessToken", token)
+	return c.Next()
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MilestoneHandler struct {
+	milestoneService service.MilestoneServicer
+}
+
+func NewMilestoneHandler(milestoneService service.MilestoneServicer) *MilestoneHandler {
+	return &MilestoneHandler{
+		milestoneService: milestoneService,
+	}
+}
+
+func (h *MilestoneHandler) HandlePostMilestone(c *fiber.Ctx) error {
+	projectID := c.Params("id")
+	if len(projectID) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.NewMilestoneParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	milestone, err := h.milestoneService.CreateMilestone(c.Context(), projectID, auth.UserID, params)
+	if err != nil {
+		return milestoneError(err)
+	}
+	return c.JSON(milestone)
+}
+func (h *MilestoneHandler) HandleGetMilestones(c *fiber.Ctx) error {
+	projectID := c.Params("id")
+	if len(projectID) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var pagination db.Pagination
+	if err := c.QueryParser(&pagination); err != nil {
+		return ErrBadRequest()
+	}
+	milestones, err := h.milestoneService.GetMilestones(c.Context(), projectID, auth.UserID, pagination)
+	if err != nil {
+		return milestoneError(err)
+	}
+	resp := NewResourceResponse(milestones, len(milestones), pagination.Page)
+	return c.JSON(resp)
+}
+func (h *MilestoneHandler) HandleGetMilestone(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	milestone, err := h.milestoneService.GetMilestoneByID(c.Context(), id, auth.UserID)
+	if err != nil {
+		return milestoneError(err)
+	}
+	return c.JSON(milestone)
+}
+func (h *MilestoneHandler) HandleGetMilestoneProgress(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	progress, err := h.milestoneService.GetMilestoneProgress(c.Context(), id, auth.UserID)
+	if err != nil {
+		return milestoneError(err)
+	}
+	return c.JSON(progress)
+}
+func (h *MilestoneHandler) HandlePutMilestone(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.UpdateMilestoneParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.milestoneService.UpdateMilestone(c.Context(), id, auth.UserID, params); err != nil {
+		return milestoneError(err)
+	}
+	return c.JSON(fiber.Map{"updated": id})
+}
+func (h *MilestoneHandler) HandleDeleteMilestone(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.milestoneService.DeleteMilestone(c.Context(), id, auth.UserID); err != nil {
+		return milestoneError(err)
+	}
+	return c.JSON(fiber.Map{"deleted": id})
+}
+func (h *MilestoneHandler) HandlePostTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.AssignMilestoneParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if err := params.Validate(); err != nil {
+		return ErrBadRequestCustomMessage(err.Error())
+	}
+	if err := h.milestoneService.AssignTask(c.Context(), id, auth.UserID, params); err != nil {
+		return milestoneError(err)
+	}
+	return c.JSON(fiber.Map{"assigned": params.TaskID})
+}
+func (h *MilestoneHandler) HandleDeleteTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+	taskID := c.Params("taskID")
+	if len(id) == 0 || len(taskID) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.milestoneService.UnassignTask(c.Context(), id, auth.UserID, taskID); err != nil {
+		return milestoneError(err)
+	}
+	return c.JSON(fiber.Map{"unassigned": taskID})
+}
+
+func milestoneError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrMilestoneNotFound):
+		return ErrResourceNotFound(err.Error())
+	case errors.Is(err, service.ErrTaskAlreadyInMilestone),
+		errors.Is(err, service.ErrTaskNotInMilestone):
+		return ErrConflict(err.Error())
+	default:
+		return projectError(err)
+	}
+}
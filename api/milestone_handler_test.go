@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ficontini/gotasks/db/fixtures"
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMilestoneProgressProjectsSlip(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app              = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store            = db.Store()
+		authService      = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1            = app.Group("/", JWTAuthentication(authService))
+		milestoneHandler = NewMilestoneHandler(service.NewMilestoneService(store))
+		user             = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		task             = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 10), false)
+		project          = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{})
+		auth             = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectIDToTask(store, task, project.ID)
+	apiv1.Post("project/:id/milestone", milestoneHandler.HandlePostMilestone)
+	apiv1.Post("milestone/:id/task", milestoneHandler.HandlePostTask)
+	apiv1.Get("milestone/:id/progress", milestoneHandler.HandleGetMilestoneProgress)
+
+	params := types.NewMilestoneParams{
+		Name:       "release-1",
+		TargetDate: time.Now().AddDate(0, 0, 3),
+	}
+	req := makeRequest(http.MethodPost, fmt.Sprintf("/project/%s/milestone", project.ID), token, bytes.NewReader(marshallParamsToJSON(t, params)))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var milestone types.Milestone
+	if err := json.NewDecoder(res.Body).Decode(&milestone); err != nil {
+		t.Fatal(err)
+	}
+
+	assign := types.AssignMilestoneParams{TaskID: task.ID}
+	req = makeRequest(http.MethodPost, fmt.Sprintf("/milestone/%s/task", milestone.ID), token, bytes.NewReader(marshallParamsToJSON(t, assign)))
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+
+	req = makeRequest(http.MethodGet, fmt.Sprintf("/milestone/%s/progress", milestone.ID), token, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var progress types.MilestoneProgress
+	if err := json.NewDecoder(res.Body).Decode(&progress); err != nil {
+		t.Fatal(err)
+	}
+	if progress.Total != 1 || progress.Open != 1 {
+		t.Fatalf("expected 1 open task, got %+v", progress)
+	}
+	if progress.OnTrack || progress.SlipDays < 7 {
+		t.Fatalf("expected the milestone to slip at least 7 days, got %d", progress.SlipDays)
+	}
+}
+
+func TestAssignTaskToMilestoneOutsideProject(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app              = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store            = db.Store()
+		authService      = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1            = app.Group("/", JWTAuthentication(authService))
+		milestoneService = service.NewMilestoneService(store)
+		milestoneHandler = NewMilestoneHandler(milestoneService)
+		user             = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		task             = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		project          = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{})
+		auth             = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	milestone, err := milestoneService.CreateMilestone(context.Background(), project.ID, user.ID, types.NewMilestoneParams{
+		Name:       "release-1",
+		TargetDate: time.Now().AddDate(0, 0, 3),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Post("milestone/:id/task", milestoneHandler.HandlePostTask)
+	assign := types.AssignMilestoneParams{TaskID: task.ID}
+	req := makeRequest(http.MethodPost, fmt.Sprintf("/milestone/%s/task", milestone.ID), token, bytes.NewReader(marshallParamsToJSON(t, assign)))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusConflict, res.StatusCode)
+}
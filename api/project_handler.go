@@ -38,7 +38,7 @@ func (h *ProjectHandler) HandlePostProject(c *fiber.Ctx) error {
 	return c.JSON(insertedProject)
 
 }
-func (h *ProjectHandler) HandleGetProject(c *fiber.Ctx) error {
+func (h *ProjectHandler) HandleCloneProject(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if len(id) == 0 {
 		return ErrInvalidID()
@@ -47,41 +47,297 @@ func (h *ProjectHandler) HandleGetProject(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
-	project, err := h.projectService.GetProjectByID(c.Context(), id)
+	var params types.CloneProjectParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	project, err := h.projectService.CloneProject(c.Context(), id, auth.UserID, params)
 	if err != nil {
-		if errors.Is(err, service.ErrProjectNotFound) {
-			return ErrResourceNotFound(err.Error())
+		if errors.Is(err, service.ErrTooManyTasks) {
+			return ErrBadRequestCustomMessage(err.Error())
 		}
+		return projectError(err)
+	}
+	return c.JSON(project)
+}
+func (h *ProjectHandler) HandleGetProject(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
 		return err
 	}
-
-	if project.UserID != auth.UserID {
-		return ErrUnAuthorized()
+	project, err := h.projectService.GetProjectByID(c.Context(), id, auth.UserID)
+	if err != nil {
+		return projectError(err)
 	}
 	return c.JSON(project)
 }
+func (h *ProjectHandler) HandleGetProjectTasks(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params service.ProjectTaskQueryParams
+	if err := c.QueryParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	tasks, err := h.projectService.GetProjectTasks(c.Context(), id, auth.UserID, params)
+	if err != nil {
+		return projectError(err)
+	}
+	resp := NewResourceResponse(tasks, len(tasks), params.Page)
+	return c.JSON(resp)
+}
+func (h *ProjectHandler) HandleGetProjectStats(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	stats, err := h.projectService.GetProjectStats(c.Context(), id, auth.UserID)
+	if err != nil {
+		return projectError(err)
+	}
+	return c.JSON(stats)
+}
+func (h *ProjectHandler) HandleGetProjects(c *fiber.Ctx) error {
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params service.ProjectQueryParams
+	if err := c.QueryParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	projects, err := h.projectService.GetProjects(c.Context(), auth.UserID, params)
+	if err != nil {
+		return err
+	}
+	resp := NewResourceResponse(projects, len(projects), params.Page)
+	return c.JSON(resp)
+}
+func (h *ProjectHandler) HandlePutProject(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.UpdateProjectParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.projectService.UpdateProject(c.Context(), id, auth.UserID, params); err != nil {
+		return projectError(err)
+	}
+	return c.JSON(fiber.Map{"updated": id})
+}
+func (h *ProjectHandler) HandleArchiveProject(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.projectService.ArchiveProject(c.Context(), id, auth.UserID); err != nil {
+		return projectError(err)
+	}
+	return c.JSON(fiber.Map{"archived": id})
+}
+func (h *ProjectHandler) HandleUnarchiveProject(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.projectService.UnarchiveProject(c.Context(), id, auth.UserID); err != nil {
+		return projectError(err)
+	}
+	return c.JSON(fiber.Map{"unarchived": id})
+}
+func (h *ProjectHandler) HandleDeleteProject(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	params := types.DeleteProjectParams{Tasks: types.DetachProjectTasks}
+	if err := c.QueryParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if err := params.Validate(); err != nil {
+		return ErrBadRequestCustomMessage(err.Error())
+	}
+	if err := h.projectService.DeleteProject(c.Context(), id, auth.UserID, params); err != nil {
+		return projectError(err)
+	}
+	return c.JSON(fiber.Map{"deleted": id})
+}
 
-// TODO: check who can add tasks to a project
 func (h *ProjectHandler) HandlePostTask(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if len(id) == 0 {
 		return ErrInvalidID()
 	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
 	var params types.AddTaskParams
 	if err := c.BodyParser(&params); err != nil {
 		return ErrBadRequest()
 	}
-	if err := h.projectService.AddTask(c.Context(), id, params); err != nil {
-		switch {
-		case errors.Is(err, service.ErrTaskNotFound):
-			return ErrResourceNotFound(err.Error())
-		case errors.Is(err, service.ErrProjectNotFound):
-			return ErrResourceNotFound(err.Error())
-		case errors.Is(err, service.ErrTaskAlreadyAssociated):
-			return ErrConflict(err.Error())
-		default:
-			return err
-		}
+	if err := h.projectService.AddTask(c.Context(), id, auth.UserID, params); err != nil {
+		return projectError(err)
 	}
 	return c.JSON(fiber.Map{"added": params.TaskID})
 }
+
+func (h *ProjectHandler) HandleDeleteTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+	taskID := c.Params("taskID")
+	if len(id) == 0 || len(taskID) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.projectService.RemoveTask(c.Context(), id, auth.UserID, taskID); err != nil {
+		return projectError(err)
+	}
+	return c.JSON(fiber.Map{"removed": taskID})
+}
+
+func (h *ProjectHandler) HandleMoveTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+	taskID := c.Params("taskID")
+	if len(id) == 0 || len(taskID) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.MoveTaskParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if err := params.Validate(); err != nil {
+		return ErrBadRequestCustomMessage(err.Error())
+	}
+	if err := h.projectService.MoveTask(c.Context(), id, auth.UserID, taskID, params); err != nil {
+		return projectError(err)
+	}
+	return c.JSON(fiber.Map{"moved": taskID})
+}
+
+func (h *ProjectHandler) HandlePostMember(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.AddMemberParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.projectService.AddMember(c.Context(), id, auth.UserID, params); err != nil {
+		return projectError(err)
+	}
+	return c.JSON(fiber.Map{"added": params.Email})
+}
+
+func (h *ProjectHandler) HandlePutMemberRole(c *fiber.Ctx) error {
+	id := c.Params("id")
+	memberID := c.Params("userID")
+	if len(id) == 0 || len(memberID) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.UpdateMemberRoleParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if err := params.Validate(); err != nil {
+		return ErrBadRequestCustomMessage(err.Error())
+	}
+	if err := h.projectService.UpdateMemberRole(c.Context(), id, auth.UserID, memberID, params); err != nil {
+		return projectError(err)
+	}
+	return c.JSON(fiber.Map{"updated": memberID})
+}
+
+func (h *ProjectHandler) HandleDeleteMember(c *fiber.Ctx) error {
+	id := c.Params("id")
+	memberID := c.Params("userID")
+	if len(id) == 0 || len(memberID) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.projectService.RemoveMember(c.Context(), id, auth.UserID, memberID); err != nil {
+		return projectError(err)
+	}
+	return c.JSON(fiber.Map{"removed": memberID})
+}
+
+func projectError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound),
+		errors.Is(err, service.ErrTaskNotFound),
+		errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrMemberNotFound):
+		return ErrResourceNotFound(err.Error())
+	case errors.Is(err, service.ErrUnAuthorized):
+		return ErrUnAuthorized()
+	case errors.Is(err, service.ErrTaskAlreadyAssociated),
+		errors.Is(err, service.ErrTaskInAnotherProject),
+		errors.Is(err, service.ErrTaskNotInProject),
+		errors.Is(err, service.ErrMemberAlreadyExists),
+		errors.Is(err, service.ErrMemberRoleUnchanged),
+		errors.Is(err, service.ErrLastProjectOwner),
+		errors.Is(err, service.ErrProjectArchived),
+		errors.Is(err, service.ErrProjectStateUnchanged):
+		return ErrConflict(err.Error())
+	default:
+		return err
+	}
+}
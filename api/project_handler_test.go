@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/ficontini/gotasks/db"
 	"github.com/ficontini/gotasks/db/fixtures"
+	"github.com/ficontini/gotasks/mailer"
 	"github.com/ficontini/gotasks/service"
 	"github.com/ficontini/gotasks/types"
 	"github.com/gofiber/fiber/v2"
@@ -21,7 +25,7 @@ func TestPostProjectSuccess(t *testing.T) {
 	var (
 		store          = db.Store()
 		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService    = service.NewAuthService(store)
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1          = app.Group("/", JWTAuthentication(authService))
 		projectService = service.NewProjectService(store)
 		projectHandler = NewProjectHandler(projectService)
@@ -58,7 +62,7 @@ func TestPostProjectInvalidTitle(t *testing.T) {
 	var (
 		store          = db.Store()
 		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService    = service.NewAuthService(store)
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1          = app.Group("/", JWTAuthentication(authService))
 		projectService = service.NewProjectService(store)
 		projectHandler = NewProjectHandler(projectService)
@@ -86,7 +90,7 @@ func TestAddTaskToProjectSuccess(t *testing.T) {
 	var (
 		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 		store          = db.Store()
-		authService    = service.NewAuthService(store)
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1          = app.Group("/", JWTAuthentication(authService))
 		projectService = service.NewProjectService(store)
 		projectHandler = NewProjectHandler(projectService)
@@ -129,7 +133,7 @@ func TestAddTaskToProjectAlreadyAdded(t *testing.T) {
 	var (
 		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 		store          = db.Store()
-		authService    = service.NewAuthService(store)
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1          = app.Group("/", JWTAuthentication(authService))
 		projectService = service.NewProjectService(store)
 		projectHandler = NewProjectHandler(projectService)
@@ -153,6 +157,515 @@ func TestAddTaskToProjectAlreadyAdded(t *testing.T) {
 	checkStatusCode(t, http.StatusConflict, res.StatusCode)
 }
 
+func TestPutProjectUnchanged(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectHandler = NewProjectHandler(service.NewProjectService(store))
+		user           = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		project        = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{})
+		auth           = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Put("project/:id", projectHandler.HandlePutProject)
+	for _, tc := range []struct {
+		params types.UpdateProjectParams
+		status int
+	}{
+		{types.UpdateProjectParams{Name: project.Name, Description: project.Description}, http.StatusConflict},
+		{types.UpdateProjectParams{Name: project.Name, Description: "new description"}, http.StatusOK},
+		{types.UpdateProjectParams{Description: "new description"}, http.StatusConflict},
+	} {
+		jsonBytes := marshallParamsToJSON(t, tc.params)
+		req := makeRequest(http.MethodPut, fmt.Sprintf("/project/%s", project.ID), token, bytes.NewReader(jsonBytes))
+		res := testRequest(t, app, req)
+		checkStatusCode(t, tc.status, res.StatusCode)
+	}
+}
+
+func TestGetProjectNotMember(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		owner          = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		tom            = fixtures.AddUser(store, "tom", "foo", "supersecure", false, true)
+		project        = fixtures.AddProject(store, "test-project", "test-project-0001", owner.ID, []string{})
+		auth           = fixtures.AddAuth(store, tom.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Get("project/:id", projectHandler.HandleGetProject)
+	req := makeRequest(http.MethodGet, fmt.Sprintf("/project/%s", project.ID), token, nil)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+}
+func TestAddTaskToProjectAsViewer(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		owner          = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		tom            = fixtures.AddUser(store, "tom", "foo", "supersecure", false, true)
+		task           = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		project        = fixtures.AddProject(store, "test-project", "test-project-0001", owner.ID, []string{})
+		auth           = fixtures.AddAuth(store, tom.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectMember(store, project.ID, tom.ID, types.ProjectRoleViewer)
+	apiv1.Post("project/:id/task", projectHandler.HandlePostTask)
+	params := types.AddTaskParams{
+		TaskID: task.ID,
+	}
+	jsonBytes := marshallParamsToJSON(t, params)
+	req := makeRequest(http.MethodPost, fmt.Sprintf("/project/%s/task", project.ID), token, bytes.NewReader(jsonBytes))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+}
+func TestAddMemberSuccess(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		owner          = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		tom            = fixtures.AddUser(store, "tom", "foo", "supersecure", false, true)
+		project        = fixtures.AddProject(store, "test-project", "test-project-0001", owner.ID, []string{})
+		auth           = fixtures.AddAuth(store, owner.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Post("project/:id/member", projectHandler.HandlePostMember)
+	params := types.AddMemberParams{
+		Email: tom.Email,
+		Role:  types.ProjectRoleEditor,
+	}
+	jsonBytes := marshallParamsToJSON(t, params)
+	req := makeRequest(http.MethodPost, fmt.Sprintf("/project/%s/member", project.ID), token, bytes.NewReader(jsonBytes))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	updatedProject, err := store.Project.GetProjectByID(context.Background(), project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updatedProject.HasRole(tom.ID, types.ProjectRoleEditor) {
+		t.Fatalf("expected user %s to be an editor of project %s", tom.ID, project.ID)
+	}
+}
+
+func TestGetProjectsExcludesArchived(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		user           = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		project        = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{})
+		archived       = fixtures.AddProject(store, "archived-project", "test-project-0002", user.ID, []string{})
+		auth           = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Post("project/:id/archive", projectHandler.HandleArchiveProject)
+	apiv1.Get("project", projectHandler.HandleGetProjects)
+	req := makeRequest(http.MethodPost, fmt.Sprintf("/project/%s/archive", archived.ID), token, nil)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+
+	req = makeRequest(http.MethodGet, "/project", token, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var resp struct {
+		Data []*types.Project `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != project.ID {
+		t.Fatalf("expected only project %s to be listed, got %+v", project.ID, resp.Data)
+	}
+}
+func TestDeleteProjectDetachTasks(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		user           = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		task           = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		project        = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{task.ID})
+		auth           = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectIDToTask(store, task, project.ID)
+	apiv1.Delete("project/:id", projectHandler.HandleDeleteProject)
+	req := makeRequest(http.MethodDelete, fmt.Sprintf("/project/%s?tasks=detach", project.ID), token, nil)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	if _, err := store.Project.GetProjectByID(context.Background(), project.ID); err == nil {
+		t.Fatalf("expected project %s to be deleted", project.ID)
+	}
+	detachedTask, err := store.Task.GetTaskByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detachedTask.ProjectID) > 0 {
+		t.Fatalf("expected task %s to be detached from project", task.ID)
+	}
+}
+
+func TestDeleteProjectWithMoreTasksThanATransaction(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectHandler = NewProjectHandler(service.NewProjectService(store))
+		user           = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		auth           = fixtures.AddAuth(store, user.ID)
+		tasks          = []*types.Task{}
+		taskIDs        = []string{}
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 150; i++ {
+		task := fixtures.AddTask(store, fmt.Sprintf("task%d", i), fmt.Sprintf("description of task%d", i), time.Now().AddDate(0, 0, 2), false)
+		tasks = append(tasks, task)
+		taskIDs = append(taskIDs, task.ID)
+	}
+	project := fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, taskIDs)
+	for _, task := range tasks {
+		fixtures.AddProjectIDToTask(store, task, project.ID)
+	}
+	apiv1.Delete("project/:id", projectHandler.HandleDeleteProject)
+	req := makeRequest(http.MethodDelete, fmt.Sprintf("/project/%s?tasks=delete", project.ID), token, nil)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	if _, err := store.Project.GetProjectByID(context.Background(), project.ID); err == nil {
+		t.Fatalf("expected project %s to be deleted", project.ID)
+	}
+	for _, task := range tasks {
+		if _, err := store.Task.GetTaskByID(context.Background(), task.ID); err == nil {
+			t.Fatalf("expected task %s to be deleted", task.ID)
+		}
+	}
+}
+
+// unavailableTaskStore fails to read one of the tasks, as when the database is unavailable.
+type unavailableTaskStore struct {
+	db.TaskStore
+	taskID string
+}
+
+func (s unavailableTaskStore) GetTaskByID(ctx context.Context, id string) (*types.Task, error) {
+	if id == s.taskID {
+		return nil, errors.New("database unavailable")
+	}
+	return s.TaskStore.GetTaskByID(ctx, id)
+}
+
+func TestDeleteProjectKeepsProjectOnDBError(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store       = db.Store()
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/", JWTAuthentication(authService))
+		user        = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		task        = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		other       = fixtures.AddTask(store, "task02", "description of task02", time.Now().AddDate(0, 0, 2), false)
+		project     = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{task.ID, other.ID})
+		auth        = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectIDToTask(store, task, project.ID)
+	fixtures.AddProjectIDToTask(store, other, project.ID)
+	failing := *store
+	failing.Task = unavailableTaskStore{TaskStore: store.Task, taskID: other.ID}
+	projectHandler := NewProjectHandler(service.NewProjectService(&failing))
+	apiv1.Delete("project/:id", projectHandler.HandleDeleteProject)
+	req := makeRequest(http.MethodDelete, fmt.Sprintf("/project/%s?tasks=delete", project.ID), token, nil)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusInternalServerError, res.StatusCode)
+	if _, err := store.Project.GetProjectByID(context.Background(), project.ID); err != nil {
+		t.Fatalf("expected project %s to be kept, got %v", project.ID, err)
+	}
+	if _, err := store.Task.GetTaskByID(context.Background(), task.ID); err != nil {
+		t.Fatalf("expected task %s to be kept, got %v", task.ID, err)
+	}
+}
+
+func TestAddTaskToProjectInAnotherProject(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		user           = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		task           = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		source         = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{task.ID})
+		target         = fixtures.AddProject(store, "test-project", "test-project-0002", user.ID, []string{})
+		auth           = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectIDToTask(store, task, source.ID)
+	apiv1.Post("project/:id/task", projectHandler.HandlePostTask)
+	params := types.AddTaskParams{
+		TaskID: task.ID,
+	}
+	jsonBytes := marshallParamsToJSON(t, params)
+	req := makeRequest(http.MethodPost, fmt.Sprintf("/project/%s/task", target.ID), token, bytes.NewReader(jsonBytes))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusConflict, res.StatusCode)
+}
+func TestMoveTaskSuccess(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		user           = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		task           = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		source         = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{task.ID})
+		target         = fixtures.AddProject(store, "test-project", "test-project-0002", user.ID, []string{})
+		auth           = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectIDToTask(store, task, source.ID)
+	apiv1.Post("project/:id/task/:taskID/move", projectHandler.HandleMoveTask)
+	params := types.MoveTaskParams{
+		ProjectID: target.ID,
+	}
+	jsonBytes := marshallParamsToJSON(t, params)
+	req := makeRequest(http.MethodPost, fmt.Sprintf("/project/%s/task/%s/move", source.ID, task.ID), token, bytes.NewReader(jsonBytes))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	updatedSource, err := store.Project.GetProjectByID(context.Background(), source.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updatedSource.ContainsTask(task.ID) {
+		t.Fatalf("expected task %s to be removed from project %s", task.ID, source.ID)
+	}
+	updatedTarget, err := store.Project.GetProjectByID(context.Background(), target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updatedTarget.ContainsTask(task.ID) {
+		t.Fatalf("expected task %s to be added to project %s", task.ID, target.ID)
+	}
+	updatedTask, err := store.Task.GetTaskByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updatedTask.ProjectID != target.ID {
+		t.Fatalf("expected the task %s to be associated with project %s", task.ID, target.ID)
+	}
+}
+
+func TestGetProjectTasksFiltered(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		user           = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		open           = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		done           = fixtures.AddTask(store, "task02", "description of task02", time.Now().AddDate(0, 0, 2), true)
+		other          = fixtures.AddTask(store, "task03", "description of task03", time.Now().AddDate(0, 0, 2), false)
+		project        = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{})
+		auth           = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectIDToTask(store, open, project.ID)
+	fixtures.AddProjectIDToTask(store, done, project.ID)
+	apiv1.Get("project/:id/task", projectHandler.HandleGetProjectTasks)
+	req := makeRequest(http.MethodGet, fmt.Sprintf("/project/%s/task?completed=false", project.ID), token, nil)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var resp struct {
+		Data []*types.Task `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != open.ID {
+		t.Fatalf("expected only task %s to be listed, got %+v (other task %s)", open.ID, resp.Data, other.ID)
+	}
+}
+
+func TestGetProjectStats(t *testing.T) {
+	tdb := setup(t)
+	defer tdb.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = tdb.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		user           = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		overdue        = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, -2), false)
+		done           = fixtures.AddTask(store, "task02", "description of task02", time.Now().AddDate(0, 0, 2), true)
+		project        = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{})
+		auth           = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Post("project/:id/task", projectHandler.HandlePostTask)
+	apiv1.Get("project/:id/stats", projectHandler.HandleGetProjectStats)
+	for _, task := range []*types.Task{overdue, done} {
+		jsonBytes := marshallParamsToJSON(t, types.AddTaskParams{TaskID: task.ID})
+		req := makeRequest(http.MethodPost, fmt.Sprintf("/project/%s/task", project.ID), token, bytes.NewReader(jsonBytes))
+		res := testRequest(t, app, req)
+		checkStatusCode(t, http.StatusOK, res.StatusCode)
+	}
+	req := makeRequest(http.MethodGet, fmt.Sprintf("/project/%s/stats", project.ID), token, nil)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var stats types.ProjectStats
+	if err := json.NewDecoder(res.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 2 || stats.Completed != 1 || stats.Overdue != 1 {
+		t.Fatalf("expected 2 tasks, 1 completed and 1 overdue, got %+v", stats)
+	}
+	if stats.PercentComplete != 50 {
+		t.Fatalf("expected 50%% complete, got %v", stats.PercentComplete)
+	}
+	if len(stats.Trend) != types.ProjectStatsTrendDays {
+		t.Fatalf("expected a %d day trend, got %d", types.ProjectStatsTrendDays, len(stats.Trend))
+	}
+
+	apiv1.Delete("project/:id/task/:taskID", projectHandler.HandleDeleteTask)
+	req = makeRequest(http.MethodDelete, fmt.Sprintf("/project/%s/task/%s", project.ID, overdue.ID), token, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodGet, fmt.Sprintf("/project/%s/stats", project.ID), token, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	if err := json.NewDecoder(res.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 1 || stats.Completed != 1 || stats.Overdue != 0 {
+		t.Fatalf("expected the stats to follow the tasks, got %+v", stats)
+	}
+}
+
+func TestCloneProjectWithTasks(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app            = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store          = db.Store()
+		authService    = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1          = app.Group("/", JWTAuthentication(authService))
+		projectService = service.NewProjectService(store)
+		projectHandler = NewProjectHandler(projectService)
+		owner          = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		viewer         = fixtures.AddUser(store, "jane", "doe", "supersecure", false, true)
+		task           = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), true)
+		project        = fixtures.AddProject(store, "test-project", "test-project-0001", owner.ID, []string{})
+		auth           = fixtures.AddAuth(store, viewer.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectMember(store, project.ID, viewer.ID, types.ProjectRoleViewer)
+	fixtures.AddProjectIDToTask(store, task, project.ID)
+	apiv1.Post("project/:id/clone", projectHandler.HandleCloneProject)
+	params := types.CloneProjectParams{IncludeTasks: true}
+	req := makeRequest(http.MethodPost, fmt.Sprintf("/project/%s/clone", project.ID), token, bytes.NewReader(marshallParamsToJSON(t, params)))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	clone := decodeToProject(t, res)
+	if clone.ID == project.ID || clone.Name != project.Name || len(clone.Tasks) != 1 {
+		t.Fatalf("expected a copy of project %s with 1 task, got %+v", project.ID, clone)
+	}
+	if role, _ := clone.GetRole(viewer.ID); role != types.ProjectRoleOwner {
+		t.Fatalf("expected the user cloning the project to own the copy, got %s", role)
+	}
+	copied, err := store.Task.GetTaskByID(context.Background(), clone.Tasks[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied.ID == task.ID || copied.Completed || copied.ProjectID != clone.ID {
+		t.Fatalf("expected an open copy of task %s in project %s, got %+v", task.ID, clone.ID, copied)
+	}
+}
+
 func decodeToProject(t *testing.T, response *http.Response) *types.Project {
 	var project *types.Project
 	if err := json.NewDecoder(response.Body).Decode(&project); err != nil {
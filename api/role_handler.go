@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleHandler struct {
+	policy      *service.Policy
+	userService service.UserServicer
+}
+
+func NewRoleHandler(policy *service.Policy, userService service.UserServicer) *RoleHandler {
+	return &RoleHandler{
+		policy:      policy,
+		userService: userService,
+	}
+}
+
+func (h *RoleHandler) HandleGetRoles(c *fiber.Ctx) error {
+	return c.JSON(h.policy.Roles())
+}
+func (h *RoleHandler) HandlePutUserRoles(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	admin, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.AssignRolesParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.userService.AssignRoles(c.Context(), admin.ID, id, params); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			return ErrResourceNotFound(err.Error())
+		case errors.Is(err, service.ErrOwnRoles):
+			return NewError(http.StatusForbidden, err.Error())
+		default:
+			return err
+		}
+	}
+	return c.JSON(fiber.Map{"userID": id, "roles": params.Roles})
+}
+func (h *RoleHandler) HandleBulkPutUserRoles(c *fiber.Ctx) error {
+	admin, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.BulkRolesParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	ids, err := h.userService.AssignRolesToUsers(c.Context(), admin.ID, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOwnRoles):
+			return NewError(http.StatusForbidden, err.Error())
+		default:
+			return userError(err)
+		}
+	}
+	return c.JSON(fiber.Map{"userIDs": ids, "roles": params.Roles})
+}
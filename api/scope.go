@@ -0,0 +1,36 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope lets requests signed in with a personal access token through when
+// the token has the scope. Routes without it reject those requests, see getAuth.
+func RequireScope(scope types.Scope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := c.Context().UserValue("accessToken").(*types.AccessToken)
+		if !ok {
+			return c.Next()
+		}
+		if !token.HasScope(scope) {
+			return NewError(http.StatusForbidden, fmt.Sprintf("access token is missing the %s scope", scope))
+		}
+		c.Context().SetUserValue("scopeGranted", true)
+		return c.Next()
+	}
+}
+
+// checkScope fails for personal access tokens on routes that don't require a scope.
+func checkScope(c *fiber.Ctx) error {
+	if _, ok := c.Context().UserValue("accessToken").(*types.AccessToken); !ok {
+		return nil
+	}
+	if granted, _ := c.Context().UserValue("scopeGranted").(bool); !granted {
+		return NewError(http.StatusForbidden, "access tokens can't be used for this route")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+const ssoStateCookie = "sso_state"
+
+type SSOHandler struct {
+	ssoService  service.SSOServicer
+	authService service.AuthServicer
+}
+
+func NewSSOHandler(ssoService service.SSOServicer, authService service.AuthServicer) *SSOHandler {
+	return &SSOHandler{
+		ssoService:  ssoService,
+		authService: authService,
+	}
+}
+
+// HandleSSOLogin redirects to the identity provider. The state of the sign in is
+// kept in a cookie scoped to the sibling callback route.
+func (h *SSOHandler) HandleSSOLogin(c *fiber.Ctx) error {
+	login, err := h.ssoService.StartSSO()
+	if err != nil {
+		return ssoError(err)
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     ssoStateCookie,
+		Value:    login.StateToken,
+		Path:     path.Dir(c.Path()),
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+		Expires:  time.Now().Add(10 * time.Minute),
+	})
+	return c.Redirect(login.URL, http.StatusFound)
+}
+func (h *SSOHandler) HandleSSOCallback(c *fiber.Ctx) error {
+	var params types.SSOCallbackParams
+	if err := c.QueryParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	params.StateToken = c.Cookies(ssoStateCookie)
+	params.IP = c.IP()
+	params.UserAgent = c.Get(fiber.HeaderUserAgent)
+	c.Cookie(&fiber.Cookie{
+		Name:     ssoStateCookie,
+		Path:     path.Dir(c.Path()),
+		HTTPOnly: true,
+		Expires:  time.Unix(0, 0),
+	})
+	auth, err := h.ssoService.CompleteSSO(c.Context(), &params)
+	if err != nil {
+		return ssoError(err)
+	}
+	return sendTokens(c, h.authService, auth)
+}
+
+func ssoError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrSSONotConfigured):
+		return ErrResourceNotFound(err.Error())
+	case errors.Is(err, service.ErrInvalidSSOState),
+		errors.Is(err, service.ErrInvalidSSOIdentity):
+		return NewError(http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrSSOUserNotFound):
+		return NewError(http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		return ErrForbidden()
+	default:
+		return err
+	}
+}
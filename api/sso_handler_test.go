@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/oidc"
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "gotasks-test"
+
+// testProvider stands in for an OpenID Connect identity provider, signing in the
+// user of its claims on every authorization request.
+type testProvider struct {
+	*httptest.Server
+	t      *testing.T
+	key    *rsa.PrivateKey
+	jwk    types.JWK
+	claims jwt.MapClaims
+
+	mu    sync.Mutex
+	codes map[string]url.Values
+}
+
+func newTestProvider(t *testing.T, claims jwt.MapClaims) *testProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := types.NewJWK(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &testProvider{
+		t:      t,
+		key:    key,
+		jwk:    jwk,
+		claims: claims,
+		codes:  map[string]url.Values{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(types.JWKS{Keys: []types.JWK{p.jwk}})
+	})
+	mux.HandleFunc("/token", p.handleToken)
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+// authorize returns the code the provider would redirect back with.
+func (p *testProvider) authorize(location string) url.Values {
+	u, err := url.Parse(location)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	query := u.Query()
+	code := fmt.Sprintf("code-%d", time.Now().UnixNano())
+	p.mu.Lock()
+	p.codes[code] = query
+	p.mu.Unlock()
+	return url.Values{"code": {code}, "state": {query.Get("state")}}
+}
+func (p *testProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.mu.Lock()
+	auth, ok := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	p.mu.Unlock()
+	if !ok || auth.Get("code_challenge") != oidc.CodeChallenge(r.Form.Get("code_verifier")) {
+		http.Error(w, "invalid_grant", http.StatusBadRequest)
+		return
+	}
+	claims := jwt.MapClaims{
+		"iss":   p.URL,
+		"aud":   testClientID,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": auth.Get("nonce"),
+	}
+	for k, v := range p.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.jwk.Kid
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+func TestSSOLoginCreatesUser(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	idp := newTestProvider(t, jwt.MapClaims{
+		"sub":            "idp-user-1",
+		"email":          "james@foo.com",
+		"email_verified": true,
+		"given_name":     "james",
+		"family_name":    "foo",
+	})
+	defer idp.Close()
+	provider, err := oidc.NewProvider(context.Background(), oidc.Config{
+		Issuer:      idp.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost/auth/sso/callback",
+		AutoCreate:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store       = db.Store()
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		handler     = NewSSOHandler(service.NewSSOService(store, provider), authService)
+	)
+	app.Get("/auth/sso/login", handler.HandleSSOLogin)
+	app.Get("/auth/sso/callback", handler.HandleSSOCallback)
+
+	login := func() (url.Values, *http.Cookie) {
+		res := testRequest(t, app, makeUnauthenticatedRequest(http.MethodGet, "/auth/sso/login", nil))
+		checkStatusCode(t, http.StatusFound, res.StatusCode)
+		for _, cookie := range res.Cookies() {
+			if cookie.Name == ssoStateCookie {
+				return idp.authorize(res.Header.Get("Location")), cookie
+			}
+		}
+		t.Fatal("expected the sso state cookie to be set")
+		return nil, nil
+	}
+
+	callback, cookie := login()
+	callback.Set("state", "forged")
+	req := makeUnauthenticatedRequest(http.MethodGet, "/auth/sso/callback?"+callback.Encode(), nil)
+	req.AddCookie(cookie)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+
+	callback, cookie = login()
+	req = makeUnauthenticatedRequest(http.MethodGet, "/auth/sso/callback?"+callback.Encode(), nil)
+	req.AddCookie(cookie)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var authresp types.AuthResponse
+	if err := json.NewDecoder(res.Body).Decode(&authresp); err != nil {
+		t.Fatal(err)
+	}
+	if len(authresp.Token) == 0 {
+		t.Fatal("expected the JWT token to be present in the auth response")
+	}
+	user, err := store.User.GetUserByEmail(context.Background(), "james@foo.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.SSOSubject != "idp-user-1" || !user.Enabled || !user.Verified {
+		t.Fatalf("expected an enabled and verified user linked to the subject, got %+v", user)
+	}
+}
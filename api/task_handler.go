@@ -24,12 +24,20 @@ func (h *TaskHandler) HandleGetTask(c *fiber.Ctx) error {
 	if len(id) == 0 {
 		return ErrInvalidID()
 	}
-	task, err := h.taskService.GetTaskByID(c.Context(), id)
+	user, err := getUserAuth(c)
 	if err != nil {
-		if errors.Is(err, service.ErrTaskNotFound) {
+		return err
+	}
+	task, err := h.taskService.GetTaskByID(c.Context(), id, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTaskNotFound):
 			return ErrResourceNotFound(err.Error())
+		case errors.Is(err, service.ErrUnAuthorized):
+			return ErrUnAuthorized()
+		default:
+			return err
 		}
-		return err
 	}
 	return c.JSON(task)
 }
@@ -49,6 +57,22 @@ func (h *TaskHandler) HandleGetUserTasks(c *fiber.Ctx) error {
 	resp := NewResourceResponse(tasks, len(tasks), params.Page)
 	return c.JSON(resp)
 }
+func (h *TaskHandler) HandleGetTeamTasks(c *fiber.Ctx) error {
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params service.TeamTaskQueryParams
+	if err := c.QueryParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	tasks, err := h.taskService.GetTeamTasksByUserID(c.Context(), auth.UserID, params)
+	if err != nil {
+		return err
+	}
+	resp := NewResourceResponse(tasks, len(tasks), params.Page)
+	return c.JSON(resp)
+}
 func (h *TaskHandler) HandleGetTasks(c *fiber.Ctx) error {
 	var params service.TaskQueryParams
 	if err := c.QueryParser(&params); err != nil {
@@ -77,6 +101,31 @@ func (h *TaskHandler) HandlePostTask(c *fiber.Ctx) error {
 	return c.JSON(task)
 }
 
+func (h *TaskHandler) HandleDuplicateTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	task, err := h.taskService.DuplicateTask(c.Context(), id, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTaskNotFound), errors.Is(err, service.ErrProjectNotFound):
+			return ErrResourceNotFound(err.Error())
+		case errors.Is(err, service.ErrUnAuthorized):
+			return ErrUnAuthorized()
+		case errors.Is(err, service.ErrProjectArchived):
+			return ErrConflict(err.Error())
+		default:
+			return err
+		}
+	}
+	return c.JSON(task)
+}
+
 func (h *TaskHandler) HandleCompleteTask(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if len(id) == 0 {
@@ -94,10 +143,12 @@ func (h *TaskHandler) HandleCompleteTask(c *fiber.Ctx) error {
 		switch {
 		case errors.Is(err, service.ErrUnAuthorized):
 			return ErrUnAuthorized()
-		case errors.Is(err, service.ErrTaskNotFound):
+		case errors.Is(err, service.ErrTaskNotFound), errors.Is(err, service.ErrProjectNotFound):
 			return ErrResourceNotFound(err.Error())
 		case errors.Is(err, service.ErrTaskAlreadyCompleted):
 			return ErrBadRequestCustomMessage(err.Error())
+		case errors.Is(err, service.ErrProjectArchived):
+			return ErrConflict(err.Error())
 		default:
 			return err
 		}
@@ -133,13 +184,40 @@ func (h *TaskHandler) HandleAssignTaskToSelf(c *fiber.Ctx) error {
 		UserID: user.ID,
 	}
 	if err := h.taskService.AssignTaskToSelf(c.Context(), params); err != nil {
-		if errors.Is(err, service.ErrTaskNotFound) {
+		switch {
+		case errors.Is(err, service.ErrTaskNotFound), errors.Is(err, service.ErrTeamNotFound), errors.Is(err, service.ErrProjectNotFound):
 			return ErrResourceNotFound(err.Error())
+		case errors.Is(err, service.ErrUnAuthorized):
+			return ErrUnAuthorized()
+		case errors.Is(err, service.ErrProjectArchived):
+			return ErrConflict(err.Error())
+		default:
+			return err
 		}
-		return err
 	}
 	return c.JSON(fiber.Map{"assigned": "true"})
 }
+func (h *TaskHandler) HandleAssignTaskToTeam(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.AssignTeamParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if err := params.Validate(); err != nil {
+		return ErrBadRequestCustomMessage(err.Error())
+	}
+	if err := h.taskService.AssignTaskToTeam(c.Context(), id, auth.UserID, params); err != nil {
+		return teamError(err)
+	}
+	return c.JSON(fiber.Map{"assigned": params.TeamID})
+}
 func (h *TaskHandler) HandleAssignTaskToUser(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if len(id) == 0 {
@@ -154,8 +232,12 @@ func (h *TaskHandler) HandleAssignTaskToUser(c *fiber.Ctx) error {
 		switch {
 		case errors.Is(err, service.ErrUserNotFound):
 			return ErrResourceNotFound(err.Error())
-		case errors.Is(err, service.ErrTaskNotFound):
+		case errors.Is(err, service.ErrTaskNotFound), errors.Is(err, service.ErrProjectNotFound):
 			return ErrResourceNotFound(err.Error())
+		case errors.Is(err, service.ErrUnAuthorized):
+			return ErrUnAuthorized()
+		case errors.Is(err, service.ErrProjectArchived):
+			return ErrConflict(err.Error())
 		default:
 			return err
 		}
@@ -183,8 +265,10 @@ func (h *TaskHandler) HandlePutDueDateTask(c *fiber.Ctx) error {
 		switch {
 		case errors.Is(err, service.ErrUnAuthorized):
 			return ErrUnAuthorized()
-		case errors.Is(err, service.ErrTaskNotFound):
+		case errors.Is(err, service.ErrTaskNotFound), errors.Is(err, service.ErrProjectNotFound):
 			return ErrResourceNotFound(err.Error())
+		case errors.Is(err, service.ErrProjectArchived):
+			return ErrConflict(err.Error())
 		default:
 			return err
 		}
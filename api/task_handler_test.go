@@ -5,12 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/ficontini/gotasks/db"
 	"github.com/ficontini/gotasks/db/fixtures"
+	"github.com/ficontini/gotasks/mailer"
 	"github.com/ficontini/gotasks/service"
 	"github.com/ficontini/gotasks/types"
 	"github.com/gofiber/fiber/v2"
@@ -121,16 +124,24 @@ func TestDeleteTaskSuccess(t *testing.T) {
 		store        = db.Store()
 		insertedTask = fixtures.AddTask(store, "fake-task", "fake task description", time.Now().AddDate(0, 0, 2), false)
 		app          = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService  = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1        = app.Group("/api", JWTAuthentication(authService))
 		taskService  = service.NewTaskService(store)
 		taskHandler  = NewTaskHandler(taskService)
+		james        = fixtures.AddUser(store, "james", "foo", "supersecurepassword", false, true)
+		auth         = fixtures.AddAuth(store, james.ID)
 	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
 	app.Delete("/:id", taskHandler.HandleDeleteTask)
-	app.Get("/:id", taskHandler.HandleGetTask)
+	apiv1.Get("/:id", taskHandler.HandleGetTask)
 
 	req := makeUnauthenticatedRequest(http.MethodDelete, fmt.Sprintf("/%s", insertedTask.ID), nil)
 	res := testRequest(t, app, req)
 	checkStatusCode(t, http.StatusOK, res.StatusCode)
-	req = makeUnauthenticatedRequest(http.MethodGet, fmt.Sprintf("/%s", insertedTask.ID), nil)
+	req = makeRequest(http.MethodGet, fmt.Sprintf("/api/%s", insertedTask.ID), token, nil)
 	res = testRequest(t, app, req)
 	checkStatusCode(t, http.StatusNotFound, res.StatusCode)
 }
@@ -154,7 +165,7 @@ func TestCompleteTaskSuccess(t *testing.T) {
 	var (
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 		store       = db.Store()
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
 		taskService = service.NewTaskService(store)
 		taskHandler = NewTaskHandler(taskService)
@@ -191,7 +202,7 @@ func TestCompleteTaskWithCompletedStatus(t *testing.T) {
 	var (
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 		store       = db.Store()
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
 		taskService = service.NewTaskService(store)
 		taskHandler = NewTaskHandler(taskService)
@@ -216,7 +227,7 @@ func TestCompleteTaskWithAnotherAssignedUser(t *testing.T) {
 	var (
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 		store       = db.Store()
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
 		taskService = service.NewTaskService(store)
 		taskHandler = NewTaskHandler(taskService)
@@ -242,7 +253,7 @@ func TestUpdateDueDateTaskSuccess(t *testing.T) {
 	var (
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 		store       = db.Store()
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
 		taskService = service.NewTaskService(store)
 		taskHandler = NewTaskHandler(taskService)
@@ -285,7 +296,7 @@ func TestUpdateDueDateTaskWithWrongDate(t *testing.T) {
 	var (
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 		store       = db.Store()
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
 		taskService = service.NewTaskService(store)
 		taskHandler = NewTaskHandler(taskService)
@@ -314,7 +325,7 @@ func TestUpdateDueDateTaskWithAnotherAssignedUser(t *testing.T) {
 	var (
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 		store       = db.Store()
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
 		taskService = service.NewTaskService(store)
 		taskHandler = NewTaskHandler(taskService)
@@ -346,3 +357,53 @@ func decodeToTask(t *testing.T, response *http.Response) *types.Task {
 	}
 	return task
 }
+
+func TestArchivedProjectTasksAreReadOnly(t *testing.T) {
+	tdb := setup(t)
+	defer tdb.teardown(t)
+	var (
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store       = tdb.Store()
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/", JWTAuthentication(authService))
+		taskHandler = NewTaskHandler(service.NewTaskService(store))
+		james       = fixtures.AddUser(store, "james", "foo", "supersecurepassword", false, true)
+		admin       = fixtures.AddUser(store, "alice", "bar", "supersecurepassword", true, true)
+		task        = fixtures.AddTask(store, "fake task", "fake task description", time.Now().AddDate(0, 0, 5), false)
+		project     = fixtures.AddProject(store, "test-project", "test-project-0001", james.ID, []string{task.ID})
+	)
+	token, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, james.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminToken, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, admin.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectIDToTask(store, task, project.ID)
+	fixtures.AssignTaskToUser(store, task.ID, james.ID)
+	if err := store.Project.Update(context.Background(), project.ID, db.ProjectArchiveUpdater{Archived: true}); err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Post("/:id/complete", taskHandler.HandleCompleteTask)
+	apiv1.Put("/:id/due-date", taskHandler.HandlePutDueDateTask)
+	apiv1.Post("/admin/:id/assign", RequirePermission(service.DefaultPolicy, types.PermissionTaskAssign), taskHandler.HandleAssignTaskToUser)
+
+	dueDate := marshallParamsToJSON(t, types.UpdateDueDateTaskRequest{DueDate: time.Now().AddDate(0, 0, 10)})
+	assignee := marshallParamsToJSON(t, types.UpdateTaskRequest{UserID: james.ID})
+	for _, req := range []*http.Request{
+		makeRequest(http.MethodPost, fmt.Sprintf("/%s/complete", task.ID), token, nil),
+		makeRequest(http.MethodPut, fmt.Sprintf("/%s/due-date", task.ID), token, bytes.NewReader(dueDate)),
+		makeRequest(http.MethodPost, fmt.Sprintf("/admin/%s/assign", task.ID), adminToken, bytes.NewReader(assignee)),
+	} {
+		res := testRequest(t, app, req)
+		checkStatusCode(t, http.StatusConflict, res.StatusCode)
+	}
+	updated, err := store.Task.GetTaskByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Completed || !updated.DueDate.Equal(task.DueDate) {
+		t.Fatalf("expected the task of the archived project to be unchanged, got %+v", updated)
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TeamHandler struct {
+	teamService service.TeamServicer
+}
+
+func NewTeamHandler(teamService service.TeamServicer) *TeamHandler {
+	return &TeamHandler{
+		teamService: teamService,
+	}
+}
+
+func (h *TeamHandler) HandlePostTeam(c *fiber.Ctx) error {
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.NewTeamParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	team, err := h.teamService.CreateTeam(c.Context(), auth.UserID, params)
+	if err != nil {
+		return err
+	}
+	return c.JSON(team)
+}
+func (h *TeamHandler) HandleGetTeams(c *fiber.Ctx) error {
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var pagination db.Pagination
+	if err := c.QueryParser(&pagination); err != nil {
+		return ErrBadRequest()
+	}
+	teams, err := h.teamService.GetTeams(c.Context(), auth.UserID, pagination)
+	if err != nil {
+		return err
+	}
+	resp := NewResourceResponse(teams, len(teams), pagination.Page)
+	return c.JSON(resp)
+}
+func (h *TeamHandler) HandleGetTeam(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	team, err := h.teamService.GetTeamByID(c.Context(), id, auth.UserID)
+	if err != nil {
+		return teamError(err)
+	}
+	return c.JSON(team)
+}
+func (h *TeamHandler) HandleGetTeamTasks(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params service.TeamTaskQueryParams
+	if err := c.QueryParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	tasks, err := h.teamService.GetTeamTasks(c.Context(), id, auth.UserID, params)
+	if err != nil {
+		return teamError(err)
+	}
+	resp := NewResourceResponse(tasks, len(tasks), params.Page)
+	return c.JSON(resp)
+}
+func (h *TeamHandler) HandlePutTeam(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.UpdateTeamParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.teamService.UpdateTeam(c.Context(), id, auth.UserID, params); err != nil {
+		return teamError(err)
+	}
+	return c.JSON(fiber.Map{"updated": id})
+}
+func (h *TeamHandler) HandleDeleteTeam(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.teamService.DeleteTeam(c.Context(), id, auth.UserID); err != nil {
+		return teamError(err)
+	}
+	return c.JSON(fiber.Map{"deleted": id})
+}
+func (h *TeamHandler) HandlePostMember(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.AddTeamMemberParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.teamService.AddMember(c.Context(), id, auth.UserID, params); err != nil {
+		return teamError(err)
+	}
+	return c.JSON(fiber.Map{"added": params.Email})
+}
+func (h *TeamHandler) HandleDeleteMember(c *fiber.Ctx) error {
+	id := c.Params("id")
+	memberID := c.Params("userID")
+	if len(id) == 0 || len(memberID) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.teamService.RemoveMember(c.Context(), id, auth.UserID, memberID); err != nil {
+		return teamError(err)
+	}
+	return c.JSON(fiber.Map{"removed": memberID})
+}
+
+func teamError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrTeamNotFound),
+		errors.Is(err, service.ErrTeamMemberNotFound),
+		errors.Is(err, service.ErrTaskNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		return ErrResourceNotFound(err.Error())
+	case errors.Is(err, service.ErrUnAuthorized):
+		return ErrUnAuthorized()
+	case errors.Is(err, service.ErrTeamMemberAlreadyExists),
+		errors.Is(err, service.ErrTeamLeadCannotLeave):
+		return ErrConflict(err.Error())
+	default:
+		return projectError(err)
+	}
+}
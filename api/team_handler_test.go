@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ficontini/gotasks/db/fixtures"
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestTeamMemberPicksUpTeamTask(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store       = db.Store()
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/", JWTAuthentication(authService))
+		teamHandler = NewTeamHandler(service.NewTeamService(store))
+		taskHandler = NewTaskHandler(service.NewTaskService(store))
+		lead        = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		member      = fixtures.AddUser(store, "peter", "foo", "supersecure", false, true)
+		outsider    = fixtures.AddUser(store, "alice", "foo", "supersecure", false, true)
+		task        = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 10), false)
+	)
+	tokens := map[string]string{}
+	for _, user := range []*types.User{lead, member, outsider} {
+		token, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, user.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[user.ID] = token
+	}
+	apiv1.Post("team", teamHandler.HandlePostTeam)
+	apiv1.Post("team/:id/member", teamHandler.HandlePostMember)
+	apiv1.Get("task/team", taskHandler.HandleGetTeamTasks)
+	apiv1.Post("task/:id/team", taskHandler.HandleAssignTaskToTeam)
+	apiv1.Post("task/:id/assign", taskHandler.HandleAssignTaskToSelf)
+
+	params := types.NewTeamParams{Name: "support"}
+	res := testRequest(t, app, makeRequest(http.MethodPost, "/team", tokens[lead.ID], bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var team types.Team
+	if err := json.NewDecoder(res.Body).Decode(&team); err != nil {
+		t.Fatal(err)
+	}
+	add := types.AddTeamMemberParams{Email: member.Email}
+	res = testRequest(t, app, makeRequest(http.MethodPost, fmt.Sprintf("/team/%s/member", team.ID), tokens[lead.ID], bytes.NewReader(marshallParamsToJSON(t, add))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	assign := types.AssignTeamParams{TeamID: team.ID}
+	res = testRequest(t, app, makeRequest(http.MethodPost, fmt.Sprintf("/task/%s/team", task.ID), tokens[outsider.ID], bytes.NewReader(marshallParamsToJSON(t, assign))))
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodPost, fmt.Sprintf("/task/%s/team", task.ID), tokens[lead.ID], bytes.NewReader(marshallParamsToJSON(t, assign))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+
+	res = testRequest(t, app, makeRequest(http.MethodGet, "/task/team?unassigned=true", tokens[member.ID], nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var resp struct {
+		Data []types.Task `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != task.ID {
+		t.Fatalf("expected the team task, got %+v", resp.Data)
+	}
+
+	res = testRequest(t, app, makeRequest(http.MethodPost, fmt.Sprintf("/task/%s/assign", task.ID), tokens[outsider.ID], nil))
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodPost, fmt.Sprintf("/task/%s/assign", task.ID), tokens[member.ID], nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	updated, err := store.Task.GetTaskByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.AssignedTo != member.ID || updated.TeamID != team.ID {
+		t.Fatalf("expected the task to be assigned to the member and kept in the team, got %+v", updated)
+	}
+}
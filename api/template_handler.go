@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TemplateHandler struct {
+	templateService service.TemplateServicer
+}
+
+func NewTemplateHandler(templateService service.TemplateServicer) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+	}
+}
+
+func (h *TemplateHandler) HandlePostProjectTemplate(c *fiber.Ctx) error {
+	return h.handlePostTemplate(c, h.templateService.CreateProjectTemplate)
+}
+func (h *TemplateHandler) HandlePostTaskTemplate(c *fiber.Ctx) error {
+	return h.handlePostTemplate(c, h.templateService.CreateTaskTemplate)
+}
+
+type createTemplateFunc func(context.Context, string, string, types.NewTemplateParams) (*types.Template, error)
+
+func (h *TemplateHandler) handlePostTemplate(c *fiber.Ctx, create createTemplateFunc) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.NewTemplateParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	template, err := create(c.Context(), id, auth.UserID, params)
+	if err != nil {
+		return templateError(err)
+	}
+	return c.JSON(template)
+}
+func (h *TemplateHandler) HandleGetTemplates(c *fiber.Ctx) error {
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params service.TemplateQueryParams
+	if err := c.QueryParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if len(params.Kind) > 0 && !params.Kind.IsValid() {
+		return ErrBadRequestCustomMessage(fmt.Sprintf("kind %s is invalid", params.Kind))
+	}
+	templates, err := h.templateService.GetTemplates(c.Context(), auth.UserID, params)
+	if err != nil {
+		return err
+	}
+	resp := NewResourceResponse(templates, len(templates), params.Page)
+	return c.JSON(resp)
+}
+func (h *TemplateHandler) HandleGetTemplate(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	template, err := h.templateService.GetTemplateByID(c.Context(), id, auth.UserID)
+	if err != nil {
+		return templateError(err)
+	}
+	return c.JSON(template)
+}
+func (h *TemplateHandler) HandleDeleteTemplate(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.templateService.DeleteTemplate(c.Context(), id, auth.UserID); err != nil {
+		return templateError(err)
+	}
+	return c.JSON(fiber.Map{"deleted": id})
+}
+func (h *TemplateHandler) HandleInstantiateProject(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.InstantiateTemplateParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	project, err := h.templateService.InstantiateProject(c.Context(), id, auth.UserID, params)
+	if err != nil {
+		return templateError(err)
+	}
+	return c.JSON(project)
+}
+func (h *TemplateHandler) HandleInstantiateTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.InstantiateTemplateParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	task, err := h.templateService.InstantiateTask(c.Context(), id, auth.UserID, params)
+	if err != nil {
+		return templateError(err)
+	}
+	return c.JSON(task)
+}
+
+func templateError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrTemplateNotFound):
+		return ErrResourceNotFound(err.Error())
+	case errors.Is(err, service.ErrTemplateKindMismatch),
+		errors.Is(err, service.ErrTooManyTasks):
+		return ErrBadRequestCustomMessage(err.Error())
+	default:
+		return projectError(err)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ficontini/gotasks/db/fixtures"
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestInstantiateProjectTemplate(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app             = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store           = db.Store()
+		authService     = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1           = app.Group("/", JWTAuthentication(authService))
+		templateHandler = NewTemplateHandler(service.NewTemplateService(store))
+		user            = fixtures.AddUser(store, "james", "foo", "supersecure", false, true)
+		first           = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		second          = fixtures.AddTask(store, "task02", "description of task02", time.Now().AddDate(0, 0, 5), true)
+		project         = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{})
+		auth            = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectIDToTask(store, first, project.ID)
+	fixtures.AddProjectIDToTask(store, second, project.ID)
+	apiv1.Post("project/:id/template", templateHandler.HandlePostProjectTemplate)
+	apiv1.Post("template/:id/project", templateHandler.HandleInstantiateProject)
+
+	req := makeRequest(http.MethodPost, fmt.Sprintf("/project/%s/template", project.ID), token, bytes.NewReader(marshallParamsToJSON(t, types.NewTemplateParams{})))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var template types.Template
+	if err := json.NewDecoder(res.Body).Decode(&template); err != nil {
+		t.Fatal(err)
+	}
+	if len(template.Tasks) != 2 {
+		t.Fatalf("expected 2 template tasks, got %d", len(template.Tasks))
+	}
+
+	start := time.Now().AddDate(0, 0, 10)
+	params := types.InstantiateTemplateParams{Name: "new-project", StartDate: start}
+	req = makeRequest(http.MethodPost, fmt.Sprintf("/template/%s/project", template.ID), token, bytes.NewReader(marshallParamsToJSON(t, params)))
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	created := decodeToProject(t, res)
+	if created.Name != params.Name || len(created.Tasks) != 2 {
+		t.Fatalf("expected project %s with 2 tasks, got %+v", params.Name, created)
+	}
+	for _, id := range created.Tasks {
+		task, err := store.Task.GetTaskByID(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.Completed || task.ProjectID != created.ID {
+			t.Fatalf("expected an open task of project %s, got %+v", created.ID, task)
+		}
+		offset := task.DueDate.Sub(start).Round(24 * time.Hour)
+		if offset != 0 && offset != 3*24*time.Hour {
+			t.Fatalf("expected the task to be due 0 or 3 days after the start date, got %v", offset)
+		}
+	}
+}
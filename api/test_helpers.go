@@ -50,10 +50,19 @@ func setupTestMongoDB(t *testing.T) *TestMongoDB {
 	return &TestMongoDB{
 		client: client,
 		store: &db.Store{
-			Task:    taskStore,
-			User:    db.NewMongoUserStore(client),
-			Project: db.NewMongoProjectStore(client, taskStore),
-			Auth:    db.NewMongoAuthStore(client),
+			Task:         taskStore,
+			User:         db.NewMongoUserStore(client),
+			Project:      db.NewMongoProjectStore(client, taskStore),
+			ProjectStats: db.NewMongoProjectStatsStore(client),
+			Milestone:    db.NewMongoMilestoneStore(client),
+			Template:     db.NewMongoTemplateStore(client),
+			Token:        db.NewMongoTokenStore(client),
+			AccessToken:  db.NewMongoAccessTokenStore(client),
+			Team:         db.NewMongoTeamStore(client),
+			Workspace:    db.NewMongoWorkspaceStore(client),
+			Invitation:   db.NewMongoInvitationStore(client),
+			LoginAttempt: db.NewMemoryLoginAttemptStore(db.LoginAttemptTTL),
+			Auth:         db.NewMongoAuthStore(client),
 		},
 	}
 }
@@ -100,10 +109,19 @@ func setupTestDynamoDB(t *testing.T) *TestDynamoDB {
 	return &TestDynamoDB{
 		client: client,
 		store: &db.Store{
-			Auth:    db.NewDynamoDBAuthStore(client),
-			User:    db.NewDynamoDBUserStore(client),
-			Task:    db.NewDynamoDBTaskStore(client),
-			Project: db.NewDynamoDBProjectStore(client),
+			Auth:         db.NewDynamoDBAuthStore(client),
+			User:         db.NewDynamoDBUserStore(client),
+			Task:         db.NewDynamoDBTaskStore(client),
+			Project:      db.NewDynamoDBProjectStore(client),
+			ProjectStats: db.NewDynamoDBProjectStatsStore(client),
+			Milestone:    db.NewDynamoDBMilestoneStore(client),
+			Template:     db.NewDynamoDBTemplateStore(client),
+			Token:        db.NewDynamoDBTokenStore(client),
+			AccessToken:  db.NewDynamoDBAccessTokenStore(client),
+			Team:         db.NewDynamoDBTeamStore(client),
+			Workspace:    db.NewDynamoDBWorkspaceStore(client),
+			Invitation:   db.NewDynamoDBInvitationStore(client),
+			LoginAttempt: db.NewMemoryLoginAttemptStore(db.LoginAttemptTTL),
 		},
 	}
 }
@@ -1,7 +1,12 @@
 package api
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/ficontini/gotasks/service"
@@ -36,6 +41,32 @@ func (h *UserHandler) HandlePostUser(c *fiber.Ctx) error {
 	}
 	return c.JSON(insertedUser)
 }
+func (h *UserHandler) HandleVerifyEmail(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if len(token) == 0 {
+		return ErrBadRequestCustomMessage("token is required")
+	}
+	if err := h.userService.VerifyEmail(c.Context(), token); err != nil {
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			return ErrBadRequestCustomMessage(err.Error())
+		}
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"email": "verified"})
+}
+func (h *UserHandler) HandleResendVerification(c *fiber.Ctx) error {
+	var params types.ResendVerificationParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.userService.ResendVerification(c.Context(), params.Email); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"verification": "sent"})
+}
 func (h *UserHandler) HandleEnableUser(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if len(id) == 0 {
@@ -91,6 +122,10 @@ func (h *UserHandler) HandleResetPassword(c *fiber.Ctx) error {
 		if errors.Is(err, service.ErrCurrentPassword) {
 			return ErrUnAuthorized()
 		}
+		var weak types.WeakPasswordError
+		if errors.As(err, &weak) {
+			return c.Status(http.StatusBadRequest).JSON(map[string]string{"newPassword": weak.Reason})
+		}
 		return err
 	}
 	if err := h.userService.InvalidateJWT(c.Context(), auth); err != nil {
@@ -98,13 +133,303 @@ func (h *UserHandler) HandleResetPassword(c *fiber.Ctx) error {
 	}
 	return c.JSON(fiber.Map{"password": "updated"})
 }
+func (h *UserHandler) HandlePutUser(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.UpdateUserParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	updatedUser, err := h.userService.UpdateUser(c.Context(), user, params)
+	if err != nil {
+		return userError(err)
+	}
+	return c.JSON(updatedUser)
+}
+func (h *UserHandler) HandleDeleteUser(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var account types.DeleteAccountParams
+	if err := c.BodyParser(&account); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := account.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	var params types.DeleteUserParams
+	if err := c.QueryParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if err := h.userService.DeleteAccount(c.Context(), user, account, params); err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"deleted": user.ID})
+}
+func (h *UserHandler) HandleAdminDeleteUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	var params types.DeleteUserParams
+	if err := c.QueryParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if err := h.userService.DeleteUser(c.Context(), id, params); err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"deleted": id})
+}
+func (h *UserHandler) HandleForgotPassword(c *fiber.Ctx) error {
+	var params types.ForgotPasswordParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.userService.RequestPasswordReset(c.Context(), params.Email); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"reset": "requested"})
+}
+func (h *UserHandler) HandleResetForgottenPassword(c *fiber.Ctx) error {
+	var params types.ForgottenPasswordResetParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.userService.ResetForgottenPassword(c.Context(), params); err != nil {
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			return ErrBadRequestCustomMessage(err.Error())
+		}
+		var weak types.WeakPasswordError
+		if errors.As(err, &weak) {
+			return c.Status(http.StatusBadRequest).JSON(map[string]string{"newPassword": weak.Reason})
+		}
+		return err
+	}
+	return c.JSON(fiber.Map{"password": "updated"})
+}
+func (h *UserHandler) HandleLogout(c *fiber.Ctx) error {
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.userService.InvalidateJWT(c.Context(), auth); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"logout": "success"})
+}
+func (h *UserHandler) HandleGetSessions(c *fiber.Ctx) error {
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	sessions, err := h.userService.GetSessions(c.Context(), auth)
+	if err != nil {
+		return err
+	}
+	return c.JSON(sessions)
+}
+func (h *UserHandler) HandleDeleteSession(c *fiber.Ctx) error {
+	authUUID := c.Params("uuid")
+	if len(authUUID) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.userService.RevokeSession(c.Context(), auth.UserID, authUUID); err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"revoked": authUUID})
+}
+func (h *UserHandler) HandleAdminDeleteSessions(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	if err := h.userService.RevokeSessions(c.Context(), id); err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"revoked": id})
+}
+func (h *UserHandler) HandlePostTOTP(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	enrollment, err := h.userService.EnrollTOTP(c.Context(), user)
+	if err != nil {
+		return userError(err)
+	}
+	return c.JSON(enrollment)
+}
+func (h *UserHandler) HandleConfirmTOTP(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.TOTPParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	codes, err := h.userService.ConfirmTOTP(c.Context(), user, params)
+	if err != nil {
+		return userError(err)
+	}
+	return c.JSON(types.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+func (h *UserHandler) HandleDeleteTwoFactor(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.DisableTwoFactorParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.userService.DisableTwoFactor(c.Context(), user, params); err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"twoFactor": "disabled"})
+}
+func (h *UserHandler) HandleAdminUnlockUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	if err := h.userService.UnlockUser(c.Context(), id); err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"unlocked": id})
+}
+func (h *UserHandler) HandleAdminDeleteTwoFactor(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	if err := h.userService.ResetTwoFactor(c.Context(), id); err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"twoFactor": "reset"})
+}
+func (h *UserHandler) HandlePostAccessToken(c *fiber.Ctx) error {
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.CreateAccessTokenParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	token, err := h.userService.CreateAccessToken(c.Context(), auth.UserID, params)
+	if err != nil {
+		return err
+	}
+	return c.JSON(token)
+}
+func (h *UserHandler) HandleGetAccessTokens(c *fiber.Ctx) error {
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	tokens, err := h.userService.GetAccessTokens(c.Context(), auth.UserID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(tokens)
+}
+func (h *UserHandler) HandleDeleteAccessToken(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	if err := h.userService.RevokeAccessToken(c.Context(), auth.UserID, id); err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"revoked": id})
+}
 func (h *UserHandler) HandleGetUser(c *fiber.Ctx) error {
 	user, err := getUserAuth(c)
 	if err != nil {
 		return err
 	}
+	auth, err := getAuth(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(types.NewCurrentUser(user, auth))
+}
+func (h *UserHandler) HandleExportUser(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	export, err := h.userService.ExportUser(c.Context(), user)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeExportArchive(&buf, export); err != nil {
+		return err
+	}
+	c.Attachment(exportArchiveName)
+	return c.Send(buf.Bytes())
+}
 
-	return c.JSON(user)
+const exportArchiveName = "gotasks-export.zip"
+
+// writeExportArchive writes each part of the export as a JSON file of a zip archive.
+func writeExportArchive(w io.Writer, export *types.UserExport) error {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"tasks.json", export.Tasks},
+		{"projects.json", export.Projects},
+	}
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		f, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
 }
 func (h *UserHandler) HandleAdminGetUser(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -127,8 +452,86 @@ func (h *UserHandler) HandleGetUsers(c *fiber.Ctx) error {
 	}
 	users, err := h.userService.GetUsers(c.Context(), params)
 	if err != nil {
-		return err
+		return userError(err)
 	}
 	resp := NewResourceResponse(users, len(users), params.Page)
 	return c.JSON(resp)
 }
+
+func (h *UserHandler) HandleBulkEnableUsers(c *fiber.Ctx) error {
+	return handleBulkUsers(c, "enabled", h.userService.EnableUsers)
+}
+func (h *UserHandler) HandleBulkDisableUsers(c *fiber.Ctx) error {
+	admin, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	return handleBulkUsers(c, "disabled", func(ctx context.Context, params types.BulkUserParams) ([]string, error) {
+		return h.userService.DisableUsers(ctx, admin.ID, params)
+	})
+}
+func (h *UserHandler) HandleBulkDeleteUsers(c *fiber.Ctx) error {
+	admin, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var deleteParams types.DeleteUserParams
+	if err := c.QueryParser(&deleteParams); err != nil {
+		return ErrBadRequest()
+	}
+	var params types.BulkUserParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	results, err := h.userService.DeleteUsers(c.Context(), admin.ID, params, deleteParams)
+	if err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{"results": results})
+}
+
+// handleBulkUsers runs the action on the users of the body, responding with the
+// IDs of the users it changed under key.
+func handleBulkUsers(c *fiber.Ctx, key string, action func(context.Context, types.BulkUserParams) ([]string, error)) error {
+	var params types.BulkUserParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	ids, err := action(c.Context(), params)
+	if err != nil {
+		return userError(err)
+	}
+	return c.JSON(fiber.Map{key: ids})
+}
+
+func userError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrSessionNotFound),
+		errors.Is(err, service.ErrAccessTokenNotFound):
+		return ErrResourceNotFound(err.Error())
+	case errors.Is(err, service.ErrCurrentPassword):
+		return ErrUnAuthorized()
+	case errors.Is(err, service.ErrOwnAccount):
+		return NewError(http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		return NewError(http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrEmailAlreadyInUse),
+		errors.Is(err, service.ErrInvalidReassignee),
+		errors.Is(err, service.ErrInvalidUserQuery):
+		return ErrBadRequestCustomMessage(err.Error())
+	case errors.Is(err, service.ErrUserStateUnchanged),
+		errors.Is(err, service.ErrLastAdmin),
+		errors.Is(err, service.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnabled):
+		return ErrConflict(err.Error())
+	default:
+		return err
+	}
+}
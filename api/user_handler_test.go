@@ -1,14 +1,23 @@
 package api
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ficontini/gotasks/db"
 	"github.com/ficontini/gotasks/db/fixtures"
+	"github.com/ficontini/gotasks/mailer"
 	"github.com/ficontini/gotasks/service"
 	"github.com/ficontini/gotasks/types"
 	"github.com/gofiber/fiber/v2"
@@ -24,10 +33,10 @@ func TestEnableUserSuccess(t *testing.T) {
 		user        = fixtures.AddUser(store, "james", "foo", password, false, false)
 		adminUser   = fixtures.AddUser(store, "admin", "foo", password, true, true)
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
-		admin       = apiv1.Group("/admin", AdminAuth)
-		handler     = NewUserHandler(service.NewUserService(store))
+		admin       = apiv1.Group("/admin", RequirePermission(service.DefaultPolicy, types.PermissionUserEnable))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
 		auth        = fixtures.AddAuth(store, adminUser.ID)
 	)
 	token, err := authService.CreateTokenFromAuth(auth)
@@ -56,10 +65,10 @@ func TestEnableUserAlreadyEnabled(t *testing.T) {
 		adminUser   = fixtures.AddUser(store, "admin", "foo", password, true, true)
 		auth        = fixtures.AddAuth(store, adminUser.ID)
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
-		admin       = apiv1.Group("/admin", AdminAuth)
-		handler     = NewUserHandler(service.NewUserService(store))
+		admin       = apiv1.Group("/admin", RequirePermission(service.DefaultPolicy, types.PermissionUserEnable))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
 	)
 	token, err := authService.CreateTokenFromAuth(auth)
 	if err != nil {
@@ -81,10 +90,10 @@ func TestDisableUserSuccess(t *testing.T) {
 		adminUser   = fixtures.AddUser(store, "admin", "foo", password, true, true)
 		auth        = fixtures.AddAuth(store, adminUser.ID)
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
-		admin       = apiv1.Group("/admin", AdminAuth)
-		handler     = NewUserHandler(service.NewUserService(store))
+		admin       = apiv1.Group("/admin", RequirePermission(service.DefaultPolicy, types.PermissionUserDisable))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
 	)
 	token, err := authService.CreateTokenFromAuth(auth)
 	if err != nil {
@@ -110,10 +119,10 @@ func TestDisableUserWithWrongID(t *testing.T) {
 		adminUser   = fixtures.AddUser(store, "admin", "foo", "supersecurepassword", true, true)
 		auth        = fixtures.AddAuth(store, adminUser.ID)
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
-		admin       = apiv1.Group("/admin", AdminAuth)
-		handler     = NewUserHandler(service.NewUserService(store))
+		admin       = apiv1.Group("/admin", RequirePermission(service.DefaultPolicy, types.PermissionUserDisable))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
 		wrongID     = "609c4b22a2c2d9c3f83a01f6"
 	)
 	token, err := authService.CreateTokenFromAuth(auth)
@@ -135,9 +144,9 @@ func TestResetPasswordSuccess(t *testing.T) {
 		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
 		auth        = fixtures.AddAuth(store, user.ID)
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
-		handler     = NewUserHandler(service.NewUserService(store))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
 	)
 	token, err := authService.CreateTokenFromAuth(auth)
 	if err != nil {
@@ -168,9 +177,9 @@ func TestResetPasswordWithWrongCurrentPassword(t *testing.T) {
 		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
 		auth        = fixtures.AddAuth(store, user.ID)
 		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
-		authService = service.NewAuthService(store)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
 		apiv1       = app.Group("/", JWTAuthentication(authService))
-		handler     = NewUserHandler(service.NewUserService(store))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
 	)
 	token, err := authService.CreateTokenFromAuth(auth)
 	if err != nil {
@@ -190,3 +199,739 @@ func TestResetPasswordWithWrongCurrentPassword(t *testing.T) {
 	res = testRequest(t, app, req)
 	checkStatusCode(t, http.StatusOK, res.StatusCode)
 }
+func TestUpdateUserEmailWithWrongCurrentPassword(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		password    = "supersecurepwd"
+		store       = db.Store()
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		auth        = fixtures.AddAuth(store, user.ID)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/", JWTAuthentication(authService))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Put("/user", handler.HandlePutUser)
+	params := types.UpdateUserParams{
+		FirstName:       "jimmy",
+		Email:           "jimmy@foo.com",
+		CurrentPassword: "wrongpassword",
+	}
+	req := makeRequest(http.MethodPut, "/user", token, bytes.NewReader(marshallParamsToJSON(t, params)))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+
+	params.CurrentPassword = password
+	req = makeRequest(http.MethodPut, "/user", token, bytes.NewReader(marshallParamsToJSON(t, params)))
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var updatedUser types.User
+	if err := json.NewDecoder(res.Body).Decode(&updatedUser); err != nil {
+		t.Fatal(err)
+	}
+	if updatedUser.Email != params.Email || updatedUser.FirstName != params.FirstName {
+		t.Fatalf("expected user %s %s, got %+v", params.FirstName, params.Email, updatedUser)
+	}
+}
+func TestDeleteAccountHandsProjectsOver(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		password    = "supersecurepwd"
+		store       = db.Store()
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		member      = fixtures.AddUser(store, "alice", "foo", password, false, true)
+		task        = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		project     = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{})
+		auth        = fixtures.AddAuth(store, user.ID)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/", JWTAuthentication(authService))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AddProjectMember(store, project.ID, member.ID, types.ProjectRoleEditor)
+	fixtures.AssignTaskToUser(store, task.ID, user.ID)
+	apiv1.Delete("/user", handler.HandleDeleteUser)
+	params := types.DeleteAccountParams{CurrentPassword: password}
+	req := makeRequest(http.MethodDelete, "/user", token, bytes.NewReader(marshallParamsToJSON(t, params)))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+
+	if _, err := store.User.GetUserByID(context.Background(), user.ID); err == nil {
+		t.Fatal("expected the user to be deleted")
+	}
+	updatedProject, err := store.Project.GetProjectByID(context.Background(), project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updatedProject.UserID != member.ID || !updatedProject.HasRole(member.ID, types.ProjectRoleOwner) {
+		t.Fatalf("expected the project to be handed over to %s, got %+v", member.ID, updatedProject)
+	}
+	if _, ok := updatedProject.GetRole(user.ID); ok {
+		t.Fatal("expected the deleted user not to be a project member")
+	}
+	updatedTask, err := store.Task.GetTaskByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updatedTask.AssignedTo) > 0 {
+		t.Fatalf("expected the task to be unassigned, got %s", updatedTask.AssignedTo)
+	}
+	apiv1.Get("/user", handler.HandleGetUser)
+	req = makeRequest(http.MethodGet, "/user", token, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+}
+func TestExportUser(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		password    = "supersecurepwd"
+		store       = db.Store()
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		task        = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 2), false)
+		_           = fixtures.AddProject(store, "test-project", "test-project-0001", user.ID, []string{})
+		auth        = fixtures.AddAuth(store, user.ID)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/", JWTAuthentication(authService))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures.AssignTaskToUser(store, task.ID, user.ID)
+	apiv1.Get("/user/export", handler.HandleExportUser)
+	req := makeRequest(http.MethodGet, "/user/export", token, nil)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+	for _, name := range []string{"profile.json", "tasks.json", "projects.json"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("expected %s in the export", name)
+		}
+	}
+	f, err := files["tasks.json"].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var tasks []*types.Task
+	if err := json.NewDecoder(f).Decode(&tasks); err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("expected the export to contain task %s, got %+v", task.ID, tasks)
+	}
+}
+func TestPostUserRequiresEmailVerification(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		password    = "supersecurepwd"
+		store       = db.Store()
+		mailbox     = &bytes.Buffer{}
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authHandler = NewAuthHandler(service.NewAuthService(store, mailer.NewLogMailer(io.Discard)))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(mailbox)))
+		params      = types.CreateUserParams{
+			FirstName: "james",
+			LastName:  "foooo",
+			Email:     "james@foo.com",
+			Password:  password,
+		}
+	)
+	app.Post("/user", handler.HandlePostUser)
+	app.Get("/user/verify", handler.HandleVerifyEmail)
+	app.Post("/auth", authHandler.HandleAuthenticate)
+	req := makeUnauthenticatedRequest(http.MethodPost, "/user", bytes.NewReader(marshallParamsToJSON(t, params)))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var user types.User
+	if err := json.NewDecoder(res.Body).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Verified {
+		t.Fatal("expected a new user to be unverified")
+	}
+
+	auth := types.AuthParams{Email: params.Email, Password: password}
+	req = makeUnauthenticatedRequest(http.MethodPost, "/auth", bytes.NewReader(marshallParamsToJSON(t, auth)))
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+	var apiErr Error
+	if err := json.NewDecoder(res.Body).Decode(&apiErr); err != nil {
+		t.Fatal(err)
+	}
+	if apiErr.Err != ErrEmailNotVerified().Err {
+		t.Fatalf("expected the email not verified error, got %s", apiErr.Err)
+	}
+
+	match := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(mailbox.String())
+	if len(match) != 2 {
+		t.Fatalf("expected a verification link to be sent, got %q", mailbox.String())
+	}
+	req = makeUnauthenticatedRequest(http.MethodGet, fmt.Sprintf("/user/verify?token=%s", match[1]), nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	verifiedUser, err := store.User.GetUserByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verifiedUser.Verified {
+		t.Fatal("expected the user to be verified")
+	}
+}
+func TestResetForgottenPassword(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		password    = "supersecurepwd"
+		store       = db.Store()
+		mailbox     = &bytes.Buffer{}
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		auth        = fixtures.AddAuth(store, user.ID)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/api", JWTAuthentication(authService))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(mailbox)))
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Post("/user/forgot-password", handler.HandleForgotPassword)
+	app.Post("/user/forgot-password/reset", handler.HandleResetForgottenPassword)
+	apiv1.Get("/user", handler.HandleGetUser)
+
+	forgot := types.ForgotPasswordParams{Email: user.Email}
+	req := makeUnauthenticatedRequest(http.MethodPost, "/user/forgot-password", bytes.NewReader(marshallParamsToJSON(t, forgot)))
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	match := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(mailbox.String())
+	if len(match) != 2 {
+		t.Fatalf("expected a reset link to be sent, got %q", mailbox.String())
+	}
+
+	weak := types.ForgottenPasswordResetParams{Token: match[1], NewPassword: "james-password"}
+	req = makeUnauthenticatedRequest(http.MethodPost, "/user/forgot-password/reset", bytes.NewReader(marshallParamsToJSON(t, weak)))
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusBadRequest, res.StatusCode)
+
+	params := types.ForgottenPasswordResetParams{Token: match[1], NewPassword: "newsupersecurepwd"}
+	req = makeUnauthenticatedRequest(http.MethodPost, "/user/forgot-password/reset", bytes.NewReader(marshallParamsToJSON(t, params)))
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	updatedUser, err := store.User.GetUserByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updatedUser.IsPasswordValid(params.NewPassword) {
+		t.Fatal("expected the password to be updated")
+	}
+
+	req = makeRequest(http.MethodGet, "/api/user", token, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+
+	req = makeUnauthenticatedRequest(http.MethodPost, "/user/forgot-password/reset", bytes.NewReader(marshallParamsToJSON(t, params)))
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusBadRequest, res.StatusCode)
+}
+func TestRevokeSessionAndLogout(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		store       = db.Store()
+		user        = fixtures.AddUser(store, "james", "foo", "supersecurepwd", false, true)
+		current     = fixtures.AddAuth(store, user.ID)
+		other       = fixtures.AddAuth(store, user.ID)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/", JWTAuthentication(authService))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+	)
+	token, err := authService.CreateTokenFromAuth(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherToken, err := authService.CreateTokenFromAuth(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Get("/user", handler.HandleGetUser)
+	apiv1.Get("/user/sessions", handler.HandleGetSessions)
+	apiv1.Delete("/user/sessions/:uuid", handler.HandleDeleteSession)
+	apiv1.Post("/auth/logout", handler.HandleLogout)
+
+	req := makeRequest(http.MethodGet, "/user/sessions", token, nil)
+	res := testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var sessions []types.Session
+	if err := json.NewDecoder(res.Body).Decode(&sessions); err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current != (session.AuthUUID == current.AuthUUID) {
+			t.Fatalf("expected only session %s to be current, got %+v", current.AuthUUID, session)
+		}
+	}
+
+	req = makeRequest(http.MethodDelete, fmt.Sprintf("/user/sessions/%s", other.AuthUUID), token, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	req = makeRequest(http.MethodGet, "/user", otherToken, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+	req = makeRequest(http.MethodDelete, fmt.Sprintf("/user/sessions/%s", other.AuthUUID), token, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusNotFound, res.StatusCode)
+
+	req = makeRequest(http.MethodPost, "/auth/logout", token, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	req = makeRequest(http.MethodGet, "/user", token, nil)
+	res = testRequest(t, app, req)
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestTwoFactorSignInWithRecoveryCode(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store       = db.Store()
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		authHandler = NewAuthHandler(authService)
+		apiv1       = app.Group("/v1", JWTAuthentication(authService))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+		password    = "supersecurepassword"
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		auth        = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Post("/auth", authHandler.HandleAuthenticate)
+	app.Post("/auth/2fa", authHandler.HandleAuthenticateTwoFactor)
+	apiv1.Post("/user/2fa/totp", handler.HandlePostTOTP)
+	apiv1.Post("/user/2fa/totp/confirm", handler.HandleConfirmTOTP)
+
+	res := testRequest(t, app, makeRequest(http.MethodPost, "/v1/user/2fa/totp", token, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var enrollment types.TOTPEnrollment
+	if err := json.NewDecoder(res.Body).Decode(&enrollment); err != nil {
+		t.Fatal(err)
+	}
+	code, err := types.TOTPCode(enrollment.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirm := types.TOTPParams{Code: code}
+	res = testRequest(t, app, makeRequest(http.MethodPost, "/v1/user/2fa/totp/confirm", token, bytes.NewReader(marshallParamsToJSON(t, confirm))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var recovery types.RecoveryCodesResponse
+	if err := json.NewDecoder(res.Body).Decode(&recovery); err != nil {
+		t.Fatal(err)
+	}
+	if len(recovery.RecoveryCodes) == 0 {
+		t.Fatal("expected recovery codes")
+	}
+
+	params := types.AuthParams{Email: user.Email, Password: password}
+	res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/auth", bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var challenge types.TwoFactorChallenge
+	if err := json.NewDecoder(res.Body).Decode(&challenge); err != nil {
+		t.Fatal(err)
+	}
+	if !challenge.TwoFactorRequired || len(challenge.ChallengeToken) == 0 {
+		t.Fatalf("expected a two-factor challenge, got %+v", challenge)
+	}
+
+	for _, tc := range []struct {
+		code   string
+		status int
+	}{
+		{code, http.StatusUnauthorized},
+		{recovery.RecoveryCodes[0], http.StatusOK},
+		{recovery.RecoveryCodes[0], http.StatusUnauthorized},
+	} {
+		params := types.TwoFactorAuthParams{ChallengeToken: challenge.ChallengeToken, Code: tc.code}
+		res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/auth/2fa", bytes.NewReader(marshallParamsToJSON(t, params))))
+		checkStatusCode(t, tc.status, res.StatusCode)
+	}
+}
+func TestWrongTwoFactorCodesLockAccount(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	defer func(policy types.LockoutPolicy) { service.EmailLockoutPolicy = policy }(service.EmailLockoutPolicy)
+	service.EmailLockoutPolicy = types.LockoutPolicy{
+		FreeAttempts:     1,
+		LockoutThreshold: 3,
+		LockoutDuration:  time.Hour,
+	}
+	var (
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store       = db.Store()
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		authHandler = NewAuthHandler(authService)
+		apiv1       = app.Group("/v1", JWTAuthentication(authService))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+		password    = "supersecurepassword"
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		auth        = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Post("/auth", authHandler.HandleAuthenticate)
+	app.Post("/auth/2fa", authHandler.HandleAuthenticateTwoFactor)
+	apiv1.Post("/user/2fa/totp", handler.HandlePostTOTP)
+	apiv1.Post("/user/2fa/totp/confirm", handler.HandleConfirmTOTP)
+
+	res := testRequest(t, app, makeRequest(http.MethodPost, "/v1/user/2fa/totp", token, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var enrollment types.TOTPEnrollment
+	if err := json.NewDecoder(res.Body).Decode(&enrollment); err != nil {
+		t.Fatal(err)
+	}
+	code, err := types.TOTPCode(enrollment.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirm := types.TOTPParams{Code: code}
+	res = testRequest(t, app, makeRequest(http.MethodPost, "/v1/user/2fa/totp/confirm", token, bytes.NewReader(marshallParamsToJSON(t, confirm))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+
+	authParams := types.AuthParams{Email: user.Email, Password: password}
+	res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/auth", bytes.NewReader(marshallParamsToJSON(t, authParams))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var challenge types.TwoFactorChallenge
+	if err := json.NewDecoder(res.Body).Decode(&challenge); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < service.EmailLockoutPolicy.LockoutThreshold; i++ {
+		params := types.TwoFactorAuthParams{ChallengeToken: challenge.ChallengeToken, Code: "wrongcode"}
+		res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/auth/2fa", bytes.NewReader(marshallParamsToJSON(t, params))))
+		checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+	}
+	code, err = types.TOTPCode(enrollment.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := types.TwoFactorAuthParams{ChallengeToken: challenge.ChallengeToken, Code: code}
+	res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/auth/2fa", bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusTooManyRequests, res.StatusCode)
+	res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/auth", bytes.NewReader(marshallParamsToJSON(t, authParams))))
+	checkStatusCode(t, http.StatusTooManyRequests, res.StatusCode)
+}
+
+func TestAccessTokenScopes(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store       = db.Store()
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/v1", JWTAuthentication(authService))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+		taskHandler = NewTaskHandler(service.NewTaskService(store))
+		user        = fixtures.AddUser(store, "james", "foo", "supersecurepassword", false, true)
+		auth        = fixtures.AddAuth(store, user.ID)
+	)
+	token, err := authService.CreateTokenFromAuth(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Post("/user/tokens", handler.HandlePostAccessToken)
+	apiv1.Delete("/user/tokens/:id", handler.HandleDeleteAccessToken)
+	apiv1.Get("/task", RequireScope(types.ScopeTasksRead), taskHandler.HandleGetUserTasks)
+	apiv1.Post("/task", RequireScope(types.ScopeTasksWrite), taskHandler.HandlePostTask)
+
+	params := types.CreateAccessTokenParams{Name: "ci script", Scopes: []types.Scope{types.ScopeTasksRead}}
+	res := testRequest(t, app, makeRequest(http.MethodPost, "/v1/user/tokens", token, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var created types.CreatedAccessToken
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.Token, types.AccessTokenPrefix) {
+		t.Fatalf("expected the token to start with %s, got %s", types.AccessTokenPrefix, created.Token)
+	}
+
+	res = testRequest(t, app, makeRequest(http.MethodGet, "/v1/task", created.Token, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	task := types.NewTaskParams{Name: "new task", Description: "task created with a token"}
+	res = testRequest(t, app, makeRequest(http.MethodPost, "/v1/task", created.Token, bytes.NewReader(marshallParamsToJSON(t, task))))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodPost, "/v1/user/tokens", created.Token, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+
+	res = testRequest(t, app, makeRequest(http.MethodDelete, "/v1/user/tokens/"+created.ID, token, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodGet, "/v1/task", created.Token, nil))
+	checkStatusCode(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestSupportRoleCanDisableUsersButNotDeleteTasks(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store       = db.Store()
+		password    = "supersecurepassword"
+		user        = fixtures.AddUser(store, "james", "foo", password, false, true)
+		supportUser = fixtures.AddUser(store, "support", "foo", password, false, true)
+		adminUser   = fixtures.AddUser(store, "admin", "foo", password, true, true)
+		task        = fixtures.AddTask(store, "task", "description of the task", time.Now().AddDate(0, 0, 1), false)
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		userService = service.NewUserService(store, mailer.NewLogMailer(io.Discard))
+		policy      = service.DefaultPolicy
+		apiv1       = app.Group("/v1", JWTAuthentication(authService))
+		handler     = NewUserHandler(userService)
+		roleHandler = NewRoleHandler(policy, userService)
+		taskHandler = NewTaskHandler(service.NewTaskService(store))
+	)
+	adminToken, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, adminUser.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	supportToken, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, supportUser.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Put("/admin/user/:id/roles", RequirePermission(policy, types.PermissionRoleAssign), roleHandler.HandlePutUserRoles)
+	apiv1.Put("/admin/user/:id/disable", RequirePermission(policy, types.PermissionUserDisable), handler.HandleDisableUser)
+	apiv1.Delete("/admin/task/:id", RequirePermission(policy, types.PermissionTaskDelete), taskHandler.HandleDeleteTask)
+
+	params := types.AssignRolesParams{Roles: []types.Role{types.RoleSupport}}
+	res := testRequest(t, app, makeRequest(http.MethodPut, fmt.Sprintf("/v1/admin/user/%s/roles", supportUser.ID), supportToken, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodPut, fmt.Sprintf("/v1/admin/user/%s/roles", supportUser.ID), adminToken, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodPut, fmt.Sprintf("/v1/admin/user/%s/roles", adminUser.ID), adminToken, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+
+	res = testRequest(t, app, makeRequest(http.MethodPut, fmt.Sprintf("/v1/admin/user/%s/disable", user.ID), supportToken, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodDelete, fmt.Sprintf("/v1/admin/task/%s", task.ID), supportToken, nil))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+	if _, err := store.Task.GetTaskByID(context.Background(), task.ID); err != nil {
+		t.Fatalf("expected the task to still exist, got %v", err)
+	}
+}
+func TestPostUserRejectsWeakPasswords(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		store   = db.Store()
+		app     = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		handler = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+	)
+	app.Post("/user", handler.HandlePostUser)
+	for _, tc := range []struct {
+		password string
+		field    string
+	}{
+		{"short", "password"},
+		{"password123", "password"},
+		{"foooo-james-2024", "password"},
+		{strings.Repeat("a", types.MaxPasswordBytes+1), "password"},
+		{"supersecurepwd", ""},
+	} {
+		params := types.CreateUserParams{FirstName: "james", LastName: "foooo", Email: "james@foo.com", Password: tc.password}
+		res := testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/user", bytes.NewReader(marshallParamsToJSON(t, params))))
+		if len(tc.field) == 0 {
+			checkStatusCode(t, http.StatusOK, res.StatusCode)
+			continue
+		}
+		checkStatusCode(t, http.StatusBadRequest, res.StatusCode)
+		var errors map[string]string
+		if err := json.NewDecoder(res.Body).Decode(&errors); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := errors[tc.field]; !ok || len(errors) != 1 {
+			t.Fatalf("expected only a %s error for %q, got %v", tc.field, tc.password, errors)
+		}
+	}
+}
+func TestAdminFiltersAndBulkUpdatesUsers(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		password    = "supersecurepassword"
+		store       = db.Store()
+		adminUser   = fixtures.AddUser(store, "admin", "foo", password, true, true)
+		james       = fixtures.AddUser(store, "james", "foo", password, false, true)
+		tom         = fixtures.AddUser(store, "tom", "foo", password, false, false)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		userService = service.NewUserService(store, mailer.NewLogMailer(io.Discard))
+		handler     = NewUserHandler(userService)
+		roleHandler = NewRoleHandler(service.DefaultPolicy, userService)
+		admin       = app.Group("/admin", JWTAuthentication(authService), RequirePermission(service.DefaultPolicy, types.PermissionRoleAssign))
+	)
+	token, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, adminUser.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin.Get("/user", handler.HandleGetUsers)
+	admin.Put("/user/bulk/enable", handler.HandleBulkEnableUsers)
+	admin.Put("/user/bulk/disable", handler.HandleBulkDisableUsers)
+	admin.Put("/user/bulk/roles", roleHandler.HandleBulkPutUserRoles)
+
+	for _, tc := range []struct {
+		query string
+		users []string
+	}{
+		{"search=TOM", []string{tom.ID}},
+		{"enabled=false", []string{tom.ID}},
+		{"role=admin", []string{adminUser.ID}},
+		{"enabled=true&search=foo.com", []string{adminUser.ID, james.ID}},
+		{"createdFrom=" + time.Now().AddDate(0, 0, 1).Format(time.DateOnly), []string{}},
+	} {
+		res := testRequest(t, app, makeRequest(http.MethodGet, "/admin/user?"+tc.query, token, nil))
+		checkStatusCode(t, http.StatusOK, res.StatusCode)
+		var resp struct {
+			Data []*types.User `json:"data"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		ids := []string{}
+		for _, user := range resp.Data {
+			ids = append(ids, user.ID)
+		}
+		sort.Strings(ids)
+		sort.Strings(tc.users)
+		if !reflect.DeepEqual(ids, tc.users) {
+			t.Fatalf("expected users %v for %q, got %v", tc.users, tc.query, ids)
+		}
+	}
+	res := testRequest(t, app, makeRequest(http.MethodGet, "/admin/user?createdFrom=yesterday", token, nil))
+	checkStatusCode(t, http.StatusBadRequest, res.StatusCode)
+
+	params := types.BulkUserParams{IDs: []string{james.ID, "000000000000000000000000"}}
+	res = testRequest(t, app, makeRequest(http.MethodPut, "/admin/user/bulk/disable", token, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusNotFound, res.StatusCode)
+	params = types.BulkUserParams{IDs: []string{james.ID, tom.ID}}
+	res = testRequest(t, app, makeRequest(http.MethodPut, "/admin/user/bulk/disable", token, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	updated, err := store.User.GetUserByID(context.Background(), james.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Enabled {
+		t.Fatal("expected the user to be disabled")
+	}
+	res = testRequest(t, app, makeRequest(http.MethodPut, "/admin/user/bulk/disable", token, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusConflict, res.StatusCode)
+
+	roles := types.BulkRolesParams{BulkUserParams: types.BulkUserParams{IDs: []string{adminUser.ID, james.ID}}, Roles: []types.Role{types.RoleSupport}}
+	res = testRequest(t, app, makeRequest(http.MethodPut, "/admin/user/bulk/roles", token, bytes.NewReader(marshallParamsToJSON(t, roles))))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+	roles.IDs = []string{james.ID, tom.ID}
+	res = testRequest(t, app, makeRequest(http.MethodPut, "/admin/user/bulk/roles", token, bytes.NewReader(marshallParamsToJSON(t, roles))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	updated, err = store.User.GetUserByID(context.Background(), tom.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.HasRole(types.RoleSupport) {
+		t.Fatalf("expected the user to have the support role, got %v", updated.Roles)
+	}
+}
+func TestBulkUserRemovalProtectsAdmins(t *testing.T) {
+	tdb := setup(t)
+	defer tdb.teardown(t)
+	var (
+		password    = "supersecurepassword"
+		store       = tdb.Store()
+		adminUser   = fixtures.AddUser(store, "admin", "foo", password, true, true)
+		supportUser = fixtures.AddUser(store, "support", "foo", password, false, true)
+		james       = fixtures.AddUser(store, "james", "foo", password, false, true)
+		tom         = fixtures.AddUser(store, "tom", "foo", password, false, true)
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		handler     = NewUserHandler(service.NewUserService(store, mailer.NewLogMailer(io.Discard)))
+		admin       = app.Group("/admin", JWTAuthentication(authService))
+	)
+	if err := store.User.Update(context.Background(), supportUser.ID, db.RolesUpdater{Roles: []types.Role{types.RoleSupport}}); err != nil {
+		t.Fatal(err)
+	}
+	adminToken, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, adminUser.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	supportToken, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, supportUser.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin.Put("/user/bulk/disable", handler.HandleBulkDisableUsers)
+	admin.Post("/user/bulk/delete", handler.HandleBulkDeleteUsers)
+
+	params := types.BulkUserParams{IDs: []string{adminUser.ID, james.ID}}
+	res := testRequest(t, app, makeRequest(http.MethodPut, "/admin/user/bulk/disable", adminToken, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodPost, "/admin/user/bulk/delete", adminToken, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusForbidden, res.StatusCode)
+	params = types.BulkUserParams{IDs: []string{adminUser.ID}}
+	res = testRequest(t, app, makeRequest(http.MethodPut, "/admin/user/bulk/disable", supportToken, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusConflict, res.StatusCode)
+	updated, err := store.User.GetUserByID(context.Background(), adminUser.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Enabled {
+		t.Fatal("expected the last admin to stay enabled")
+	}
+
+	missingID := "000000000000000000000000"
+	params = types.BulkUserParams{IDs: []string{james.ID, missingID, tom.ID}}
+	res = testRequest(t, app, makeRequest(http.MethodPost, "/admin/user/bulk/delete", adminToken, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var resp struct {
+		Results []types.BulkUserResult `json:"results"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("expected a result for every user, got %v", resp.Results)
+	}
+	for _, result := range resp.Results {
+		if failed := len(result.Error) > 0; failed != (result.ID == missingID) {
+			t.Fatalf("unexpected result %+v", result)
+		}
+	}
+	if _, err := store.User.GetUserByID(context.Background(), tom.ID); err == nil {
+		t.Fatal("expected the users after the failure to be deleted")
+	}
+}
@@ -6,6 +6,9 @@ import (
 )
 
 func getAuth(c *fiber.Ctx) (*types.Auth, error) {
+	if err := checkScope(c); err != nil {
+		return nil, err
+	}
 	auth, ok := c.Context().Value("auth").(*types.Auth)
 	if !ok {
 		return nil, ErrUnAuthorized()
@@ -14,6 +17,9 @@ func getAuth(c *fiber.Ctx) (*types.Auth, error) {
 }
 
 func getUserAuth(c *fiber.Ctx) (*types.User, error) {
+	if err := checkScope(c); err != nil {
+		return nil, err
+	}
 	user, ok := c.Context().Value("user").(*types.User)
 	if !ok {
 		return nil, ErrUnAuthorized()
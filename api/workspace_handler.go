@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WorkspaceHandler struct {
+	workspaceService service.WorkspaceServicer
+}
+
+func NewWorkspaceHandler(workspaceService service.WorkspaceServicer) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+	}
+}
+
+func (h *WorkspaceHandler) HandleGetWorkspace(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	workspace, err := h.workspaceService.GetWorkspace(c.Context(), user)
+	if err != nil {
+		return workspaceError(err)
+	}
+	return c.JSON(workspace)
+}
+func (h *WorkspaceHandler) HandlePostWorkspace(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.NewWorkspaceParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	workspace, err := h.workspaceService.CreateWorkspace(c.Context(), user, params)
+	if err != nil {
+		return workspaceError(err)
+	}
+	return c.JSON(workspace)
+}
+func (h *WorkspaceHandler) HandlePutWorkspace(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.UpdateWorkspaceParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.workspaceService.UpdateWorkspace(c.Context(), user, params); err != nil {
+		return workspaceError(err)
+	}
+	return c.JSON(fiber.Map{"updated": user.WorkspaceID})
+}
+func (h *WorkspaceHandler) HandlePostInvitation(c *fiber.Ctx) error {
+	user, err := getUserAuth(c)
+	if err != nil {
+		return err
+	}
+	var params types.InviteUserParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	if err := h.workspaceService.InviteUser(c.Context(), user, params); err != nil {
+		return workspaceError(err)
+	}
+	return c.JSON(fiber.Map{"invited": params.Email})
+}
+func (h *WorkspaceHandler) HandleGetInvitations(c *fiber.Ctx) error {
+	var pagination db.Pagination
+	if err := c.QueryParser(&pagination); err != nil {
+		return ErrBadRequest()
+	}
+	invitations, err := h.workspaceService.GetInvitations(c.Context(), pagination)
+	if err != nil {
+		return workspaceError(err)
+	}
+	resp := NewResourceResponse(invitations, len(invitations), pagination.Page)
+	return c.JSON(resp)
+}
+func (h *WorkspaceHandler) HandleDeleteInvitation(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if len(id) == 0 {
+		return ErrInvalidID()
+	}
+	if err := h.workspaceService.RevokeInvitation(c.Context(), id); err != nil {
+		return workspaceError(err)
+	}
+	return c.JSON(fiber.Map{"revoked": id})
+}
+func (h *WorkspaceHandler) HandleAcceptInvitation(c *fiber.Ctx) error {
+	var params types.AcceptInvitationParams
+	if err := c.BodyParser(&params); err != nil {
+		return ErrBadRequest()
+	}
+	if errors := params.Validate(); len(errors) > 0 {
+		return c.Status(http.StatusBadRequest).JSON(errors)
+	}
+	user, err := h.workspaceService.AcceptInvitation(c.Context(), params)
+	if err != nil {
+		var weak types.WeakPasswordError
+		if errors.As(err, &weak) {
+			return c.Status(http.StatusBadRequest).JSON(map[string]string{"password": weak.Reason})
+		}
+		return workspaceError(err)
+	}
+	return c.JSON(user)
+}
+
+func workspaceError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrWorkspaceNotFound),
+		errors.Is(err, service.ErrInvitationNotFound):
+		return ErrResourceNotFound(err.Error())
+	case errors.Is(err, service.ErrWorkspaceCreation):
+		return NewError(http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		return NewError(http.StatusForbidden, fmt.Sprintf("user is missing the %s permission", types.PermissionRoleAssign))
+	case errors.Is(err, service.ErrEmailAlreadyInUse):
+		return ErrConflict(err.Error())
+	case errors.Is(err, service.ErrDefaultWorkspace),
+		errors.Is(err, service.ErrInvalidInvitationToken):
+		return ErrBadRequestCustomMessage(err.Error())
+	default:
+		return err
+	}
+}
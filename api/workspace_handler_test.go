@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/db/fixtures"
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestInvitedWorkspaceAdminOnlySeesWorkspaceTasks(t *testing.T) {
+	db := setup(t)
+	defer db.teardown(t)
+	var (
+		app              = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store            = db.Store()
+		mailbox          = &bytes.Buffer{}
+		authService      = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1            = app.Group("/api", JWTAuthentication(authService))
+		workspaceHandler = NewWorkspaceHandler(service.NewWorkspaceService(store, mailer.NewLogMailer(mailbox), service.DefaultPolicy))
+		taskHandler      = NewTaskHandler(service.NewTaskService(store))
+		admin            = fixtures.AddUser(store, "james", "foo", "supersecure", true, true)
+		task             = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 10), false)
+	)
+	adminToken, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, admin.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Post("/user/invitation", workspaceHandler.HandleAcceptInvitation)
+	apiv1.Post("/workspace", RequirePermission(service.DefaultPolicy, types.PermissionWorkspaceCreate), workspaceHandler.HandlePostWorkspace)
+	apiv1.Post("/task", taskHandler.HandlePostTask)
+	apiv1.Get("/task/all", RequirePermission(service.DefaultPolicy, types.PermissionTaskReadAny), taskHandler.HandleGetTasks)
+	apiv1.Get("/task/:id", taskHandler.HandleGetTask)
+
+	params := types.NewWorkspaceParams{Name: "acme", AdminEmail: "alice@acme.com"}
+	res := testRequest(t, app, makeRequest(http.MethodPost, "/api/workspace", adminToken, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var workspace types.Workspace
+	if err := json.NewDecoder(res.Body).Decode(&workspace); err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(mailbox.String())
+	if len(match) != 2 {
+		t.Fatalf("expected an invitation link to be sent, got %q", mailbox.String())
+	}
+	accept := types.AcceptInvitationParams{Token: match[1], FirstName: "alice", LastName: "smith", Password: "supersecure"}
+	res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/user/invitation", bytes.NewReader(marshallParamsToJSON(t, accept))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var invited types.User
+	if err := json.NewDecoder(res.Body).Decode(&invited); err != nil {
+		t.Fatal(err)
+	}
+	if invited.WorkspaceID != workspace.ID || !invited.HasRole(types.RoleAdmin) {
+		t.Fatalf("expected an admin of workspace %s, got %+v", workspace.ID, invited)
+	}
+	res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/user/invitation", bytes.NewReader(marshallParamsToJSON(t, accept))))
+	checkStatusCode(t, http.StatusBadRequest, res.StatusCode)
+
+	token, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, invited.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res = testRequest(t, app, makeRequest(http.MethodGet, fmt.Sprintf("/api/task/%s", task.ID), token, nil))
+	checkStatusCode(t, http.StatusNotFound, res.StatusCode)
+	newTask := types.NewTaskParams{Name: "task02", Description: "description of task02", DueDate: time.Now().AddDate(0, 0, 10)}
+	res = testRequest(t, app, makeRequest(http.MethodPost, "/api/task", token, bytes.NewReader(marshallParamsToJSON(t, newTask))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+
+	for _, tc := range []struct {
+		token  string
+		taskID string
+	}{
+		{adminToken, task.ID},
+		{token, ""},
+	} {
+		res = testRequest(t, app, makeRequest(http.MethodGet, "/api/task/all", tc.token, nil))
+		checkStatusCode(t, http.StatusOK, res.StatusCode)
+		var resp struct {
+			Data []*types.Task `json:"data"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Data) != 1 {
+			t.Fatalf("expected a single task in the workspace, got %d", len(resp.Data))
+		}
+		if len(tc.taskID) > 0 && resp.Data[0].ID != tc.taskID {
+			t.Fatalf("expected task %s, got %s", tc.taskID, resp.Data[0].ID)
+		}
+	}
+}
+func TestRevokedInvitationCantBeAccepted(t *testing.T) {
+	tdb := setup(t)
+	defer tdb.teardown(t)
+	var (
+		app              = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store            = tdb.Store()
+		mailbox          = &bytes.Buffer{}
+		authService      = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1            = app.Group("/api", JWTAuthentication(authService))
+		workspaceHandler = NewWorkspaceHandler(service.NewWorkspaceService(store, mailer.NewLogMailer(mailbox), service.DefaultPolicy))
+		admin            = fixtures.AddUser(store, "james", "foo", "supersecure", true, true)
+		invite           = RequirePermission(service.DefaultPolicy, types.PermissionUserInvite)
+	)
+	adminToken, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, admin.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Post("/user/invitation", workspaceHandler.HandleAcceptInvitation)
+	apiv1.Post("/workspace/invitation", invite, workspaceHandler.HandlePostInvitation)
+	apiv1.Get("/workspace/invitation", invite, workspaceHandler.HandleGetInvitations)
+	apiv1.Delete("/workspace/invitation/:id", invite, workspaceHandler.HandleDeleteInvitation)
+
+	params := types.InviteUserParams{Email: "bob@bar.com"}
+	res := testRequest(t, app, makeRequest(http.MethodPost, "/api/workspace/invitation", adminToken, bytes.NewReader(marshallParamsToJSON(t, params))))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	match := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(mailbox.String())
+	if len(match) != 2 {
+		t.Fatalf("expected an invitation link to be sent, got %q", mailbox.String())
+	}
+	res = testRequest(t, app, makeRequest(http.MethodGet, "/api/workspace/invitation", adminToken, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	var resp struct {
+		Data []*types.Invitation `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].Email != params.Email {
+		t.Fatalf("expected the invitation of %s to be pending, got %+v", params.Email, resp.Data)
+	}
+	res = testRequest(t, app, makeRequest(http.MethodDelete, fmt.Sprintf("/api/workspace/invitation/%s", resp.Data[0].ID), adminToken, nil))
+	checkStatusCode(t, http.StatusOK, res.StatusCode)
+	res = testRequest(t, app, makeRequest(http.MethodDelete, fmt.Sprintf("/api/workspace/invitation/%s", resp.Data[0].ID), adminToken, nil))
+	checkStatusCode(t, http.StatusNotFound, res.StatusCode)
+
+	accept := types.AcceptInvitationParams{Token: match[1], FirstName: "bob", LastName: "bar", Password: "supersecure"}
+	res = testRequest(t, app, makeUnauthenticatedRequest(http.MethodPost, "/user/invitation", bytes.NewReader(marshallParamsToJSON(t, accept))))
+	checkStatusCode(t, http.StatusBadRequest, res.StatusCode)
+}
+func TestAdminCantAssignTasksAcrossWorkspaces(t *testing.T) {
+	tdb := setup(t)
+	defer tdb.teardown(t)
+	var (
+		app         = fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		store       = tdb.Store()
+		authService = service.NewAuthService(store, mailer.NewLogMailer(io.Discard))
+		apiv1       = app.Group("/api", JWTAuthentication(authService))
+		taskHandler = NewTaskHandler(service.NewTaskService(store))
+		admin       = fixtures.AddUser(store, "james", "foo", "supersecure", true, true)
+		task        = fixtures.AddTask(store, "task01", "description of task01", time.Now().AddDate(0, 0, 10), false)
+		acme        = db.WithWorkspace(context.Background(), "acme")
+	)
+	adminToken, err := authService.CreateTokenFromAuth(fixtures.AddAuth(store, admin.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	acmeTask, err := store.Task.InsertTask(acme, types.NewTaskFromParams(types.NewTaskParams{Name: "task02", Description: "description of task02", DueDate: time.Now().AddDate(0, 0, 10)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	acmeUser, err := types.NewUserFromParams(types.CreateUserParams{FirstName: "alice", LastName: "smith", Email: "alice@acme.com", Password: "supersecure"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	acmeUser, err = store.User.InsertUser(acme, acmeUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1.Post("/admin/task/:id/assign", RequirePermission(service.DefaultPolicy, types.PermissionTaskAssign), taskHandler.HandleAssignTaskToUser)
+
+	for _, tc := range []struct {
+		taskID string
+		userID string
+		status int
+	}{
+		{acmeTask.ID, admin.ID, http.StatusNotFound},
+		{task.ID, acmeUser.ID, http.StatusNotFound},
+		{task.ID, admin.ID, http.StatusOK},
+	} {
+		req := types.UpdateTaskRequest{UserID: tc.userID}
+		res := testRequest(t, app, makeRequest(http.MethodPost, fmt.Sprintf("/api/admin/task/%s/assign", tc.taskID), adminToken, bytes.NewReader(marshallParamsToJSON(t, req))))
+		checkStatusCode(t, tc.status, res.StatusCode)
+	}
+	if err := store.Task.Update(db.WithWorkspace(context.Background(), ""), acmeTask.ID, db.TaskAssignationUpdater{AssignedTo: admin.ID}); err == nil {
+		t.Fatal("expected the update of a task of another workspace to fail")
+	}
+}
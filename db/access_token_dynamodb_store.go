@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ficontini/gotasks/types"
+)
+
+type DynamoDBAccessTokenStore struct {
+	client *dynamodb.Client
+	table  *string
+}
+
+func NewDynamoDBAccessTokenStore(client *dynamodb.Client) *DynamoDBAccessTokenStore {
+	return &DynamoDBAccessTokenStore{
+		client: client,
+		table:  aws.String(accessTokenColl),
+	}
+}
+
+func (s *DynamoDBAccessTokenStore) InsertAccessToken(ctx context.Context, token *types.AccessToken) error {
+	item, err := attributevalue.MarshalMap(token)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: s.table, Item: item,
+	})
+	return err
+}
+func (s *DynamoDBAccessTokenStore) GetAccessToken(ctx context.Context, id string) (*types.AccessToken, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: s.table,
+		Key:       key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Item == nil {
+		return nil, ErrorNotFound
+	}
+	var token *types.AccessToken
+	if err := attributevalue.UnmarshalMap(res.Item, &token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+func (s *DynamoDBAccessTokenStore) GetAccessTokensByUserID(ctx context.Context, userID string) ([]*types.AccessToken, error) {
+	keyEx := expression.Key(userIDField).Equal(expression.Value(userID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+	if err != nil {
+		return nil, err
+	}
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 s.table,
+		IndexName:                 aws.String(userIDGSI),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+	tokens := []*types.AccessToken{}
+	for paginator.HasMorePages() {
+		res, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var page []*types.AccessToken
+		if err := attributevalue.UnmarshalListOfMaps(res.Items, &page); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, page...)
+	}
+	return tokens, nil
+}
+func (s *DynamoDBAccessTokenStore) DeleteAccessToken(ctx context.Context, id, userID string) error {
+	key, err := GetKey(id)
+	if err != nil {
+		return err
+	}
+	condition := expression.Equal(expression.Name(userIDField), expression.Value(userID))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+	_, err = s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 s.table,
+		Key:                       key,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	var condErr *dynamodbtypes.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrorNotFound
+	}
+	return err
+}
+func (s *DynamoDBAccessTokenStore) DeleteByUserID(ctx context.Context, userID string) error {
+	tokens, err := s.GetAccessTokensByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if err := s.DeleteAccessToken(ctx, token.ID, userID); err != nil && !errors.Is(err, ErrorNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const accessTokenColl = "accessTokens"
+
+type AccessTokenStore interface {
+	InsertAccessToken(context.Context, *types.AccessToken) error
+	GetAccessToken(context.Context, string) (*types.AccessToken, error)
+	GetAccessTokensByUserID(context.Context, string) ([]*types.AccessToken, error)
+	// DeleteAccessToken deletes the token only if it belongs to the user.
+	DeleteAccessToken(context.Context, string, string) error
+	DeleteByUserID(context.Context, string) error
+}
+
+type MongoAccessTokenStore struct {
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+func NewMongoAccessTokenStore(client *mongo.Client) *MongoAccessTokenStore {
+	return &MongoAccessTokenStore{
+		client: client,
+		coll:   client.Database(DBNAME).Collection(accessTokenColl),
+	}
+}
+
+func (s *MongoAccessTokenStore) InsertAccessToken(ctx context.Context, token *types.AccessToken) error {
+	_, err := s.coll.InsertOne(ctx, token)
+	return err
+}
+func (s *MongoAccessTokenStore) GetAccessToken(ctx context.Context, id string) (*types.AccessToken, error) {
+	var token *types.AccessToken
+	if err := s.coll.FindOne(ctx, bson.M{mongoIDField: id}).Decode(&token); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
+		return nil, err
+	}
+	return token, nil
+}
+func (s *MongoAccessTokenStore) GetAccessTokensByUserID(ctx context.Context, userID string) ([]*types.AccessToken, error) {
+	cur, err := s.coll.Find(ctx, bson.M{userIDField: userID})
+	if err != nil {
+		return nil, err
+	}
+	tokens := []*types.AccessToken{}
+	if err := cur.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+func (s *MongoAccessTokenStore) DeleteAccessToken(ctx context.Context, id, userID string) error {
+	res, err := s.coll.DeleteOne(ctx, bson.M{mongoIDField: id, userIDField: userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrorNotFound
+	}
+	return nil
+}
+func (s *MongoAccessTokenStore) DeleteByUserID(ctx context.Context, userID string) error {
+	_, err := s.coll.DeleteMany(ctx, bson.M{userIDField: userID})
+	return err
+}
@@ -1,10 +1,15 @@
 package db
 
 import (
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/ficontini/gotasks/types"
 )
 
+// MaxTransactItems is the DynamoDB limit of actions in a single transaction.
+const MaxTransactItems = 100
+
 type DBAction interface {
 	get() (interface{}, error)
 }
@@ -12,6 +17,8 @@ type UpdateAction struct {
 	ID        string
 	Params    Update
 	TableName string
+	// scope restricts the write to the workspace of the transaction.
+	scope *expression.ConditionBuilder
 }
 
 func NewTaskUpdateAction(id string, params Update) (*UpdateAction, error) {
@@ -28,13 +35,29 @@ func NewProjectUpdateAction(id string, params Update) (*UpdateAction, error) {
 		TableName: projectColl,
 	}, nil
 }
+func NewUserUpdateAction(id string, params Update) (*UpdateAction, error) {
+	return &UpdateAction{
+		ID:        id,
+		Params:    params,
+		TableName: userColl,
+	}, nil
+}
 func (a *UpdateAction) get() (interface{}, error) {
 	key, err := GetKey(a.ID)
 	if err != nil {
 		return nil, err
 	}
-	update := a.Params.ToExpression()
-	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	builder := expression.NewBuilder().WithUpdate(a.Params.ToExpression())
+	conditional, ok := a.Params.(ConditionalUpdate)
+	switch {
+	case ok && a.scope != nil:
+		builder = builder.WithCondition(conditional.Condition().And(*a.scope))
+	case ok:
+		builder = builder.WithCondition(conditional.Condition())
+	case a.scope != nil:
+		builder = builder.WithCondition(*a.scope)
+	}
+	expr, err := builder.Build()
 	if err != nil {
 		return nil, err
 	}
@@ -44,5 +67,177 @@ func (a *UpdateAction) get() (interface{}, error) {
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+	}, nil
+}
+
+type DeleteAction struct {
+	ID        string
+	TableName string
+	scope     *expression.ConditionBuilder
+}
+
+func NewTaskDeleteAction(id string) *DeleteAction {
+	return &DeleteAction{
+		ID:        id,
+		TableName: taskColl,
+	}
+}
+func NewProjectDeleteAction(id string) *DeleteAction {
+	return &DeleteAction{
+		ID:        id,
+		TableName: projectColl,
+	}
+}
+func NewMilestoneDeleteAction(id string) *DeleteAction {
+	return &DeleteAction{
+		ID:        id,
+		TableName: milestoneColl,
+	}
+}
+func NewTeamDeleteAction(id string) *DeleteAction {
+	return &DeleteAction{
+		ID:        id,
+		TableName: teamColl,
+	}
+}
+func (a *DeleteAction) get() (interface{}, error) {
+	key, err := GetKey(a.ID)
+	if err != nil {
+		return nil, err
+	}
+	del := &dynamodbtypes.Delete{
+		TableName: &a.TableName,
+		Key:       key,
+	}
+	if a.scope != nil {
+		expr, err := expression.NewBuilder().WithCondition(*a.scope).Build()
+		if err != nil {
+			return nil, err
+		}
+		del.ConditionExpression = expr.Condition()
+		del.ExpressionAttributeNames = expr.Names()
+		del.ExpressionAttributeValues = expr.Values()
+	}
+	return del, nil
+}
+
+// InsertAction puts a new item whose ID was generated beforehand with the store's NewID,
+// so related items in the same transaction can reference it.
+type InsertAction struct {
+	ID        string
+	Item      interface{}
+	TableName string
+}
+
+func NewProjectInsertAction(project *types.Project) *InsertAction {
+	return &InsertAction{
+		ID:        project.ID,
+		Item:      project,
+		TableName: projectColl,
+	}
+}
+func NewTaskInsertAction(task *types.Task) *InsertAction {
+	return &InsertAction{
+		ID:        task.ID,
+		Item:      task,
+		TableName: taskColl,
+	}
+}
+func (a *InsertAction) get() (interface{}, error) {
+	item, err := attributevalue.MarshalMap(a.Item)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name(dynamoIDField))).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodbtypes.Put{
+		TableName:                &a.TableName,
+		Item:                     item,
+		ExpressionAttributeNames: expr.Names(),
+		ConditionExpression:      expr.Condition(),
+	}, nil
+}
+
+// ProjectStatsAction keeps the DynamoDB summary item the stats of a project are
+// computed from in step with its tasks, in the transaction that changes them. Mongo
+// aggregates the tasks on read, so it skips the action.
+type ProjectStatsAction struct {
+	ProjectID string
+	Tasks     []*types.Task
+	Removed   []string
+	Delete    bool
+}
+
+// NewTaskSummaryAction summarizes the tasks, as they are after the transaction, in
+// the stats of the project.
+func NewTaskSummaryAction(projectID string, tasks ...*types.Task) *ProjectStatsAction {
+	return &ProjectStatsAction{
+		ProjectID: projectID,
+		Tasks:     tasks,
+	}
+}
+func NewTaskSummaryRemoveAction(projectID string, taskIDs ...string) *ProjectStatsAction {
+	return &ProjectStatsAction{
+		ProjectID: projectID,
+		Removed:   taskIDs,
+	}
+}
+func NewProjectStatsDeleteAction(projectID string) *ProjectStatsAction {
+	return &ProjectStatsAction{
+		ProjectID: projectID,
+		Delete:    true,
+	}
+}
+func (a *ProjectStatsAction) get() (interface{}, error) {
+	key, err := GetKey(a.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	table := projectStatsColl
+	if a.Delete {
+		return &dynamodbtypes.Delete{
+			TableName: &table,
+			Key:       key,
+		}, nil
+	}
+	var update expression.UpdateBuilder
+	for _, task := range a.Tasks {
+		update = update.Set(expression.Name(taskSummaryName(task.ID)), expression.Value(types.NewTaskSummary(task)))
+	}
+	for _, taskID := range a.Removed {
+		update = update.Remove(expression.Name(taskSummaryName(taskID)))
+	}
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodbtypes.Update{
+		TableName:                 &table,
+		Key:                       key,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
 	}, nil
 }
+
+func newTransactWriteItem(action DBAction) (dynamodbtypes.TransactWriteItem, error) {
+	operation, err := action.get()
+	if err != nil {
+		return dynamodbtypes.TransactWriteItem{}, err
+	}
+	switch op := operation.(type) {
+	case *dynamodbtypes.Update:
+		return dynamodbtypes.TransactWriteItem{Update: op}, nil
+	case *dynamodbtypes.Delete:
+		return dynamodbtypes.TransactWriteItem{Delete: op}, nil
+	case *dynamodbtypes.Put:
+		return dynamodbtypes.TransactWriteItem{Put: op}, nil
+	default:
+		return dynamodbtypes.TransactWriteItem{}, ErrInvalidOperationType
+	}
+}
@@ -2,9 +2,12 @@ package db
 
 import (
 	"context"
+	"errors"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/ficontini/gotasks/types"
 )
@@ -73,3 +76,71 @@ func (s *DynamoDBAuthStore) Delete(ctx context.Context, params *types.AuthFilter
 	}
 	return nil
 }
+
+func (s *DynamoDBAuthStore) GetByUserID(ctx context.Context, userID string) ([]*types.Auth, error) {
+	keyEx := expression.Key(userIDField).Equal(expression.Value(userID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+	if err != nil {
+		return nil, err
+	}
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 s.table,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+	auths := []*types.Auth{}
+	for paginator.HasMorePages() {
+		res, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var page []*types.Auth
+		if err := attributevalue.UnmarshalListOfMaps(res.Items, &page); err != nil {
+			return nil, err
+		}
+		auths = append(auths, page...)
+	}
+	return auths, nil
+}
+func (s *DynamoDBAuthStore) DeleteByUserID(ctx context.Context, userID string) error {
+	auths, err := s.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, auth := range auths {
+		filter := &types.AuthFilter{UserID: auth.UserID, AuthUUID: auth.AuthUUID}
+		if err := s.Delete(ctx, filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DynamoDBAuthStore) RotateRefreshToken(ctx context.Context, params *types.AuthFilter, generation int) error {
+	key, err := attributevalue.MarshalMap(params)
+	if err != nil {
+		return err
+	}
+	var (
+		update    = expression.Set(expression.Name(refreshGenerationField), expression.Name(refreshGenerationField).Plus(expression.Value(1)))
+		condition = expression.Equal(expression.Name(refreshGenerationField), expression.Value(generation))
+	)
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 s.table,
+		Key:                       key,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+	})
+	var condErr *dynamodbtypes.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrorNotFound
+	}
+	return err
+}
@@ -2,8 +2,11 @@ package db
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -13,7 +16,12 @@ const authColl = "auths"
 type AuthStore interface {
 	Insert(context.Context, *types.Auth) (*types.Auth, error)
 	Get(context.Context, *types.AuthFilter) (*types.Auth, error)
+	GetByUserID(context.Context, string) ([]*types.Auth, error)
 	Delete(context.Context, *types.AuthFilter) error
+	DeleteByUserID(context.Context, string) error
+	// RotateRefreshToken moves the auth to the next refresh generation, only if it is
+	// still at the given one.
+	RotateRefreshToken(context.Context, *types.AuthFilter, int) error
 }
 
 type MongoAuthStore struct {
@@ -47,10 +55,28 @@ func (s *MongoAuthStore) Get(ctx context.Context, params *types.AuthFilter) (*ty
 	}
 	var auth *types.Auth
 	if err := s.coll.FindOne(ctx, filter).Decode(&auth); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
 		return nil, err
 	}
 	return auth, err
 }
+func (s *MongoAuthStore) GetByUserID(ctx context.Context, userID string) ([]*types.Auth, error) {
+	oid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := s.coll.Find(ctx, bson.M{userIDField: oid})
+	if err != nil {
+		return nil, err
+	}
+	auths := []*types.Auth{}
+	if err := cur.All(ctx, &auths); err != nil {
+		return nil, err
+	}
+	return auths, nil
+}
 func (s *MongoAuthStore) Delete(ctx context.Context, params *types.AuthFilter) error {
 	filter, err := NewMongoAuthFilter(params)
 	if err != nil {
@@ -66,10 +92,42 @@ func (s *MongoAuthStore) Delete(ctx context.Context, params *types.AuthFilter) e
 	return nil
 }
 
+func (s *MongoAuthStore) DeleteByUserID(ctx context.Context, userID string) error {
+	oid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.coll.DeleteMany(ctx, bson.M{userIDField: oid})
+	return err
+}
+
+func (s *MongoAuthStore) RotateRefreshToken(ctx context.Context, params *types.AuthFilter, generation int) error {
+	filter, err := NewMongoAuthFilter(params)
+	if err != nil {
+		return err
+	}
+	res, err := s.coll.UpdateOne(ctx,
+		bson.M{userIDField: filter.UserID, authUUIDField: filter.AuthUUID, refreshGenerationField: generation},
+		bson.M{"$inc": bson.M{refreshGenerationField: 1}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrorNotFound
+	}
+	return nil
+}
+
 type MongoAuth struct {
-	UserID         primitive.ObjectID `bson:"userID"`
-	AuthUUID       string             `bson:"authUUID"`
-	ExpirationTime int64              `bson:"expirationTime"`
+	UserID            primitive.ObjectID `bson:"userID"`
+	AuthUUID          string             `bson:"authUUID"`
+	ExpirationTime    int64              `bson:"expirationTime"`
+	IP                string             `bson:"ip"`
+	UserAgent         string             `bson:"userAgent"`
+	CreatedAt         time.Time          `bson:"createdAt"`
+	RefreshGeneration int                `bson:"refreshGeneration"`
+	ImpersonatorID    string             `bson:"impersonatorID,omitempty"`
 }
 
 func newMongoAuth(auth *types.Auth) (*MongoAuth, error) {
@@ -78,8 +136,14 @@ func newMongoAuth(auth *types.Auth) (*MongoAuth, error) {
 		return nil, err
 	}
 	return &MongoAuth{
-		UserID:   oid,
-		AuthUUID: auth.AuthUUID,
+		UserID:            oid,
+		AuthUUID:          auth.AuthUUID,
+		ExpirationTime:    auth.ExpirationTime,
+		IP:                auth.IP,
+		UserAgent:         auth.UserAgent,
+		CreatedAt:         auth.CreatedAt,
+		RefreshGeneration: auth.RefreshGeneration,
+		ImpersonatorID:    auth.ImpersonatorID,
 	}, nil
 }
 
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Backfill fills in a field added after items were stored, so the items stored by
+// older versions keep behaving as they did. It only updates the items missing the
+// field, so running it again is harmless. Databases that need nothing have no function.
+type Backfill struct {
+	Name     string
+	Mongo    func(context.Context, *mongo.Database) (int64, error)
+	DynamoDB func(context.Context, *dynamodb.Client) (int64, error)
+}
+
+// Backfills lists the backfills to run after upgrading, in order.
+var Backfills = []Backfill{
+	{
+		// Users signed up before email verification existed count as verified.
+		Name: "verified users",
+		Mongo: func(ctx context.Context, database *mongo.Database) (int64, error) {
+			return setMissingMongoField(ctx, database.Collection(userColl), verifiedField, true)
+		},
+		DynamoDB: func(ctx context.Context, client *dynamodb.Client) (int64, error) {
+			return setMissingDynamoDBField(ctx, client, userColl, verifiedField, true)
+		},
+	},
+	{
+		// DynamoDB only finds the projects stored before they had a data type by scanning.
+		Name: "project data types",
+		DynamoDB: func(ctx context.Context, client *dynamodb.Client) (int64, error) {
+			return setMissingDynamoDBField(ctx, client, projectColl, dataTypeField, types.ProjectDataType)
+		},
+	},
+	{
+		// MongoDB IDs hold the time they were created at. DynamoDB IDs don't, so the users
+		// stored there before the creation date only match listings without a range.
+		Name: "user creation dates",
+		Mongo: func(ctx context.Context, database *mongo.Database) (int64, error) {
+			createdAt := bson.M{"$toDate": "$" + mongoIDField}
+			res, err := database.Collection(userColl).UpdateMany(ctx,
+				bson.M{createdAtField: bson.M{"$exists": false}},
+				mongo.Pipeline{{{Key: "$set", Value: bson.M{createdAtField: createdAt}}}},
+			)
+			if err != nil {
+				return 0, err
+			}
+			return res.ModifiedCount, nil
+		},
+	},
+	{
+		// Roles replaced the isAdmin flag, admins get the admin role in its place.
+		Name: "admin roles",
+		Mongo: func(ctx context.Context, database *mongo.Database) (int64, error) {
+			res, err := database.Collection(userColl).UpdateMany(ctx,
+				bson.M{isAdminField: true, rolesField: bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{rolesField: []types.Role{types.RoleAdmin}}, "$unset": bson.M{isAdminField: ""}},
+			)
+			if err != nil {
+				return 0, err
+			}
+			return res.ModifiedCount, nil
+		},
+		DynamoDB: func(ctx context.Context, client *dynamodb.Client) (int64, error) {
+			var (
+				admin = expression.And(
+					expression.Name(isAdminField).Equal(expression.Value(true)),
+					expression.AttributeNotExists(expression.Name(rolesField)),
+				)
+				update = expression.Set(expression.Name(rolesField), expression.Value([]types.Role{types.RoleAdmin})).
+					Remove(expression.Name(isAdminField))
+			)
+			return updateDynamoDBItems(ctx, client, userColl, admin, update)
+		},
+	},
+	{
+		// DynamoDB computes the stats of a project from a summary of its tasks, rebuilt
+		// from the tasks for the projects created before it or whose summary drifted.
+		Name:     "project stats summaries",
+		DynamoDB: rebuildProjectStatsSummaries,
+	},
+}
+
+func setMissingMongoField(ctx context.Context, coll *mongo.Collection, field string, value interface{}) (int64, error) {
+	res, err := coll.UpdateMany(ctx, bson.M{field: bson.M{"$exists": false}}, bson.M{"$set": bson.M{field: value}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+func setMissingDynamoDBField(ctx context.Context, client *dynamodb.Client, table, field string, value interface{}) (int64, error) {
+	missing := expression.AttributeNotExists(expression.Name(field))
+	return updateDynamoDBItems(ctx, client, table, missing, expression.Set(expression.Name(field), expression.Value(value)))
+}
+
+func rebuildProjectStatsSummaries(ctx context.Context, client *dynamodb.Client) (int64, error) {
+	inProject := expression.Name(projectIDField).NotEqual(expression.Value(""))
+	expr, err := expression.NewBuilder().WithFilter(inProject).Build()
+	if err != nil {
+		return 0, err
+	}
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName:                 aws.String(taskColl),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	projects := map[string][]*types.Task{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		var tasks []*types.Task
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &tasks); err != nil {
+			return 0, err
+		}
+		for _, task := range tasks {
+			projects[task.ProjectID] = append(projects[task.ProjectID], task)
+		}
+	}
+	var updated int64
+	for projectID, tasks := range projects {
+		item, err := newProjectStatsItem(projectID, tasks)
+		if err != nil {
+			return updated, err
+		}
+		if _, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(projectStatsColl),
+			Item:      item,
+		}); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// updateDynamoDBItems scans the table for the items matching the condition and updates
+// them, skipping the ones that stop matching it since the scan.
+func updateDynamoDBItems(ctx context.Context, client *dynamodb.Client, table string, condition expression.ConditionBuilder, update expression.UpdateBuilder) (int64, error) {
+	expr, err := expression.NewBuilder().WithFilter(condition).Build()
+	if err != nil {
+		return 0, err
+	}
+	updateExpr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return 0, err
+	}
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName:                 aws.String(table),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	var updated int64
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return updated, err
+		}
+		for _, item := range page.Items {
+			var id string
+			if err := attributevalue.Unmarshal(item[dynamoIDField], &id); err != nil {
+				return updated, err
+			}
+			key, err := GetKey(id)
+			if err != nil {
+				return updated, err
+			}
+			_, err = client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName:                 aws.String(table),
+				Key:                       key,
+				ExpressionAttributeNames:  updateExpr.Names(),
+				ExpressionAttributeValues: updateExpr.Values(),
+				UpdateExpression:          updateExpr.Update(),
+				ConditionExpression:       updateExpr.Condition(),
+			})
+			var condErr *dynamodbtypes.ConditionalCheckFailedException
+			if errors.As(err, &condErr) {
+				continue
+			}
+			if err != nil {
+				return updated, err
+			}
+			updated++
+		}
+	}
+	return updated, nil
+}
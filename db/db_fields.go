@@ -2,13 +2,38 @@ package db
 
 const (
 	enabledField           = "enabled"
+	verifiedField          = "verified"
+	totpSecretField        = "totpSecret"
+	totpEnabledField       = "totpEnabled"
+	totpLastStepField      = "totpLastStep"
+	recoveryCodesField     = "recoveryCodes"
+	ssoSubjectField        = "ssoSubject"
+	rolesField             = "roles"
+	isAdminField           = "isAdmin"
 	completedField         = "completed"
+	completedAtField       = "completedAt"
+	createdAtField         = "createdAt"
 	assignedToField        = "assignedTo"
 	encryptedPasswordField = "encryptedPassword"
 	dueDateField           = "dueDate"
 	tasksField             = "tasks"
 	projectIDField         = "projectID"
+	milestoneIDField       = "milestoneID"
+	teamIDField            = "teamID"
+	leadIDField            = "leadID"
+	workspaceIDField       = "workspaceID"
+	targetDateField        = "targetDate"
+	membersField           = "members"
+	archivedField          = "archived"
+	nameField              = "name"
+	descriptionField       = "description"
 	emailField             = "email"
+	firstNameField         = "firstName"
+	lastNameField          = "lastName"
+	userIDField            = "userID"
+	authUUIDField          = "authUUID"
+	refreshGenerationField = "refreshGeneration"
+	kindField              = "kind"
 	mongoIDField           = "_id"
 	dynamoIDField          = "ID"
 	dataTypeField          = "dataType"
@@ -2,16 +2,20 @@ package db
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 const (
-	dataTypeGSI = "DataTypeGSI"
+	dataTypeGSI  = "DataTypeGSI"
+	projectIDGSI = "ProjectIDGSI"
+	userIDGSI    = "UserIDGSI"
 )
 
 func NewDynamoDBStore() (*Store, error) {
@@ -20,10 +24,19 @@ func NewDynamoDBStore() (*Store, error) {
 		return nil, err
 	}
 	return &Store{
-		Auth:    NewDynamoDBAuthStore(client),
-		User:    NewDynamoDBUserStore(client),
-		Task:    NewDynamoDBTaskStore(client),
-		Project: NewDynamoDBProjectStore(client),
+		Auth:         NewDynamoDBAuthStore(client),
+		User:         NewDynamoDBUserStore(client),
+		Task:         NewDynamoDBTaskStore(client),
+		Project:      NewDynamoDBProjectStore(client),
+		ProjectStats: NewDynamoDBProjectStatsStore(client),
+		Milestone:    NewDynamoDBMilestoneStore(client),
+		Template:     NewDynamoDBTemplateStore(client),
+		Token:        NewDynamoDBTokenStore(client),
+		AccessToken:  NewDynamoDBAccessTokenStore(client),
+		Team:         NewDynamoDBTeamStore(client),
+		Workspace:    NewDynamoDBWorkspaceStore(client),
+		Invitation:   NewDynamoDBInvitationStore(client),
+		LoginAttempt: NewMemoryLoginAttemptStore(LoginAttemptTTL),
 	}, nil
 }
 func NewDynamoDBClient() (*dynamodb.Client, error) {
@@ -44,6 +57,63 @@ func GetKey(idStr string) (map[string]dynamodbtypes.AttributeValue, error) {
 	}
 	return map[string]dynamodbtypes.AttributeValue{dynamoIDField: id}, nil
 }
+
+// newUpdateItemInput updates the item by ID, restricted to the workspace of the context.
+func newUpdateItemInput(ctx context.Context, table *string, id string, params Update) (*dynamodb.UpdateItemInput, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	builder := expression.NewBuilder().WithUpdate(params.ToExpression())
+	if condition := scopeCondition(ctx); condition != nil {
+		builder = builder.WithCondition(*condition)
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.UpdateItemInput{
+		TableName:                 table,
+		Key:                       key,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+	}, nil
+}
+
+// newDeleteItemInput deletes the item by ID, restricted to the workspace of the context.
+func newDeleteItemInput(ctx context.Context, table *string, id string) (*dynamodb.DeleteItemInput, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.DeleteItemInput{
+		TableName:    table,
+		Key:          key,
+		ReturnValues: ReturnAllOld,
+	}
+	if condition := scopeCondition(ctx); condition != nil {
+		expr, err := expression.NewBuilder().WithCondition(*condition).Build()
+		if err != nil {
+			return nil, err
+		}
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+	return input, nil
+}
+
+// notFoundIfConditionFailed reports the items that failed the workspace condition as
+// not found.
+func notFoundIfConditionFailed(err error) error {
+	var condErr *dynamodbtypes.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrorNotFound
+	}
+	return err
+}
 func Min(a, b int) int {
 	if a < b {
 		return a
@@ -63,6 +133,9 @@ func NewDynamoDBQueryOptions(queryInput *dynamodb.QueryInput, pagination *Pagina
 	}
 }
 
+// PaginatedDynamoDBQuery queries until it has the items up to the requested page. The
+// limit of a query applies before its filter, so a page can come back short while
+// more items match after it.
 func PaginatedDynamoDBQuery(ctx context.Context, client *dynamodb.Client, opts *DynamoDBQueryOptions) ([]map[string]dynamodbtypes.AttributeValue, error) {
 	var (
 		collectiveResult []map[string]dynamodbtypes.AttributeValue
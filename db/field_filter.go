@@ -1,18 +1,23 @@
 package db
 
 import (
-	"log"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/ficontini/gotasks/types"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type DataTyper interface {
 	GetKeyCondition() expression.KeyConditionBuilder
+	GetIndexName() string
 }
 type DataType struct {
-	DataType string
+	DataType    string
+	WorkspaceID string
 }
 
 func NewDataType(dataType string) DataTyper {
@@ -21,7 +26,10 @@ func NewDataType(dataType string) DataTyper {
 	}
 }
 func (d *DataType) GetKeyCondition() expression.KeyConditionBuilder {
-	return expression.Key(dataTypeField).Equal(expression.Value(d.DataType))
+	return expression.Key(dataTypeField).Equal(expression.Value(types.WorkspaceDataType(d.WorkspaceID, d.DataType)))
+}
+func (d *DataType) GetIndexName() string {
+	return dataTypeGSI
 }
 
 type FieldFilterer interface {
@@ -54,13 +62,338 @@ func NewAssigneFieldFilterer(assignedTo string) FieldFilterer {
 	}
 }
 func (c *AssigneFieldFilterer) GetBSONFilter() bson.M {
-	//TODO: Review how to handle this error
-	oid, err := primitive.ObjectIDFromHex(c.AssignedTo)
-	if err != nil {
-		log.Fatal(err)
-	}
-	return bson.M{assignedToField: oid}
+	return bson.M{assignedToField: bson.M{"$in": idValues(c.AssignedTo)}}
 }
 func (c *AssigneFieldFilterer) GetFilter() expression.ConditionBuilder {
 	return expression.Equal(expression.Name(assignedToField), expression.Value(c.AssignedTo))
 }
+
+// MemberFieldFilterer filters the projects the user is a member of. Projects stored
+// before memberships existed have no members, and only their owner is one.
+type MemberFieldFilterer struct {
+	UserID string
+}
+
+func NewMemberFieldFilterer(userID string) FieldFilterer {
+	return &MemberFieldFilterer{
+		UserID: userID,
+	}
+}
+func (c *MemberFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{memberField(c.UserID): bson.M{"$exists": true}},
+		bson.M{membersField: nil, userIDField: c.UserID},
+	}}
+}
+func (c *MemberFieldFilterer) GetFilter() expression.ConditionBuilder {
+	owner := expression.AttributeNotExists(expression.Name(membersField)).
+		And(expression.Equal(expression.Name(userIDField), expression.Value(c.UserID)))
+	return expression.AttributeExists(expression.Name(memberField(c.UserID))).Or(owner)
+}
+
+// ArchivedFieldFilterer counts the projects stored before archiving existed as not
+// archived.
+type ArchivedFieldFilterer struct {
+	Archived bool
+}
+
+func NewArchivedFieldFilterer(archived bool) FieldFilterer {
+	return &ArchivedFieldFilterer{
+		Archived: archived,
+	}
+}
+func (c *ArchivedFieldFilterer) GetBSONFilter() bson.M {
+	if !c.Archived {
+		return bson.M{archivedField: bson.M{"$ne": true}}
+	}
+	return bson.M{archivedField: true}
+}
+func (c *ArchivedFieldFilterer) GetFilter() expression.ConditionBuilder {
+	archived := expression.Equal(expression.Name(archivedField), expression.Value(c.Archived))
+	if !c.Archived {
+		return archived.Or(expression.AttributeNotExists(expression.Name(archivedField)))
+	}
+	return archived
+}
+
+type MilestoneFieldFilterer struct {
+	MilestoneID string
+}
+
+func NewMilestoneFieldFilterer(milestoneID string) FieldFilterer {
+	return &MilestoneFieldFilterer{
+		MilestoneID: milestoneID,
+	}
+}
+func (c *MilestoneFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{milestoneIDField: c.MilestoneID}
+}
+func (c *MilestoneFieldFilterer) GetFilter() expression.ConditionBuilder {
+	return expression.Equal(expression.Name(milestoneIDField), expression.Value(c.MilestoneID))
+}
+
+type UserFieldFilterer struct {
+	UserID string
+}
+
+func NewUserFieldFilterer(userID string) FieldFilterer {
+	return &UserFieldFilterer{
+		UserID: userID,
+	}
+}
+func (c *UserFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{userIDField: c.UserID}
+}
+func (c *UserFieldFilterer) GetFilter() expression.ConditionBuilder {
+	return expression.Equal(expression.Name(userIDField), expression.Value(c.UserID))
+}
+
+type KindFieldFilterer struct {
+	Kind string
+}
+
+func NewKindFieldFilterer(kind string) FieldFilterer {
+	return &KindFieldFilterer{
+		Kind: kind,
+	}
+}
+func (c *KindFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{kindField: c.Kind}
+}
+func (c *KindFieldFilterer) GetFilter() expression.ConditionBuilder {
+	return expression.Equal(expression.Name(kindField), expression.Value(c.Kind))
+}
+
+// ProjectFieldFilterer filters tasks by project. It is also a DataTyper, so
+// DynamoDB queries it through the projectID GSI instead of the dataType one.
+type ProjectFieldFilterer struct {
+	ProjectID string
+}
+
+func NewProjectFieldFilterer(projectID string) *ProjectFieldFilterer {
+	return &ProjectFieldFilterer{
+		ProjectID: projectID,
+	}
+}
+func (c *ProjectFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{projectIDField: bson.M{"$in": idValues(c.ProjectID)}}
+}
+func (c *ProjectFieldFilterer) GetFilter() expression.ConditionBuilder {
+	return expression.Equal(expression.Name(projectIDField), expression.Value(c.ProjectID))
+}
+func (c *ProjectFieldFilterer) GetKeyCondition() expression.KeyConditionBuilder {
+	return expression.Key(projectIDField).Equal(expression.Value(c.ProjectID))
+}
+func (c *ProjectFieldFilterer) GetIndexName() string {
+	return projectIDGSI
+}
+
+// TeamFieldFilterer filters tasks owned by any of the teams.
+type TeamFieldFilterer struct {
+	TeamIDs []string
+}
+
+func NewTeamFieldFilterer(teamIDs ...string) FieldFilterer {
+	return &TeamFieldFilterer{
+		TeamIDs: teamIDs,
+	}
+}
+func (c *TeamFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{teamIDField: bson.M{"$in": c.TeamIDs}}
+}
+func (c *TeamFieldFilterer) GetFilter() expression.ConditionBuilder {
+	values := make([]expression.OperandBuilder, 0, len(c.TeamIDs))
+	for _, id := range c.TeamIDs {
+		values = append(values, expression.Value(id))
+	}
+	return expression.Name(teamIDField).In(values[0], values[1:]...)
+}
+
+type UnassignedFieldFilterer struct{}
+
+func NewUnassignedFieldFilterer() FieldFilterer {
+	return &UnassignedFieldFilterer{}
+}
+func (c *UnassignedFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{assignedToField: bson.M{"$in": bson.A{"", nil}}}
+}
+func (c *UnassignedFieldFilterer) GetFilter() expression.ConditionBuilder {
+	return expression.Or(
+		expression.Equal(expression.Name(assignedToField), expression.Value("")),
+		expression.AttributeNotExists(expression.Name(assignedToField)),
+	)
+}
+
+type TeamMemberFieldFilterer struct {
+	UserID string
+}
+
+func NewTeamMemberFieldFilterer(userID string) FieldFilterer {
+	return &TeamMemberFieldFilterer{
+		UserID: userID,
+	}
+}
+func (c *TeamMemberFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{membersField: c.UserID}
+}
+func (c *TeamMemberFieldFilterer) GetFilter() expression.ConditionBuilder {
+	return expression.Contains(expression.Name(membersField), c.UserID)
+}
+
+type EnabledFieldFilterer struct {
+	Enabled bool
+}
+
+func NewEnabledFieldFilterer(enabled bool) FieldFilterer {
+	return &EnabledFieldFilterer{
+		Enabled: enabled,
+	}
+}
+func (c *EnabledFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{enabledField: c.Enabled}
+}
+func (c *EnabledFieldFilterer) GetFilter() expression.ConditionBuilder {
+	return expression.Equal(expression.Name(enabledField), expression.Value(c.Enabled))
+}
+
+type RoleFieldFilterer struct {
+	Role types.Role
+}
+
+func NewRoleFieldFilterer(role types.Role) FieldFilterer {
+	return &RoleFieldFilterer{
+		Role: role,
+	}
+}
+func (c *RoleFieldFilterer) GetBSONFilter() bson.M {
+	return bson.M{rolesField: c.Role}
+}
+func (c *RoleFieldFilterer) GetFilter() expression.ConditionBuilder {
+	return expression.Contains(expression.Name(rolesField), string(c.Role))
+}
+
+// UserSearchFieldFilterer matches users whose email or name contains the search.
+// Emails are lowercase, but DynamoDB matches names case sensitively.
+type UserSearchFieldFilterer struct {
+	Search string
+}
+
+func NewUserSearchFieldFilterer(search string) FieldFilterer {
+	return &UserSearchFieldFilterer{
+		Search: search,
+	}
+}
+func (c *UserSearchFieldFilterer) GetBSONFilter() bson.M {
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(c.Search), Options: "i"}
+	return bson.M{"$or": []bson.M{
+		{emailField: pattern},
+		{firstNameField: pattern},
+		{lastNameField: pattern},
+	}}
+}
+func (c *UserSearchFieldFilterer) GetFilter() expression.ConditionBuilder {
+	return expression.Or(
+		expression.Contains(expression.Name(emailField), strings.ToLower(c.Search)),
+		expression.Contains(expression.Name(firstNameField), c.Search),
+		expression.Contains(expression.Name(lastNameField), c.Search),
+	)
+}
+
+// CreatedAtFieldFilterer matches items created from From, inclusive, to To, exclusive.
+// A zero time leaves that end of the range open, but not both.
+type CreatedAtFieldFilterer struct {
+	From time.Time
+	To   time.Time
+}
+
+func NewCreatedAtFieldFilterer(from, to time.Time) FieldFilterer {
+	return &CreatedAtFieldFilterer{
+		From: from,
+		To:   to,
+	}
+}
+func (c *CreatedAtFieldFilterer) GetBSONFilter() bson.M {
+	createdAt := bson.M{}
+	if !c.From.IsZero() {
+		createdAt["$gte"] = c.From
+	}
+	if !c.To.IsZero() {
+		createdAt["$lt"] = c.To
+	}
+	return bson.M{createdAtField: createdAt}
+}
+func (c *CreatedAtFieldFilterer) GetFilter() expression.ConditionBuilder {
+	name := expression.Name(createdAtField)
+	switch {
+	case c.To.IsZero():
+		return expression.GreaterThanEqual(name, expression.Value(c.From))
+	case c.From.IsZero():
+		return expression.LessThan(name, expression.Value(c.To))
+	default:
+		return expression.And(
+			expression.GreaterThanEqual(name, expression.Value(c.From)),
+			expression.LessThan(name, expression.Value(c.To)),
+		)
+	}
+}
+
+// AndFieldFilterer matches the documents matching all of its fields.
+type AndFieldFilterer struct {
+	Fields []FieldFilterer
+}
+
+func NewAndFieldFilterer(fields ...FieldFilterer) FieldFilterer {
+	if len(fields) == 1 {
+		return fields[0]
+	}
+	return &AndFieldFilterer{
+		Fields: fields,
+	}
+}
+func (c *AndFieldFilterer) GetBSONFilter() bson.M {
+	filters := make([]bson.M, 0, len(c.Fields))
+	for _, field := range c.Fields {
+		filters = append(filters, field.GetBSONFilter())
+	}
+	return bson.M{"$and": filters}
+}
+func (c *AndFieldFilterer) GetFilter() expression.ConditionBuilder {
+	others := make([]expression.ConditionBuilder, 0, len(c.Fields)-2)
+	for _, field := range c.Fields[2:] {
+		others = append(others, field.GetFilter())
+	}
+	return expression.And(c.Fields[0].GetFilter(), c.Fields[1].GetFilter(), others...)
+}
+
+// WorkspaceFieldFilterer filters items of a workspace. Items of the default workspace
+// were stored before workspaces existed, so they have no workspaceID.
+type WorkspaceFieldFilterer struct {
+	WorkspaceID string
+}
+
+func NewWorkspaceFieldFilterer(workspaceID string) FieldFilterer {
+	return &WorkspaceFieldFilterer{
+		WorkspaceID: workspaceID,
+	}
+}
+func (c *WorkspaceFieldFilterer) GetBSONFilter() bson.M {
+	if len(c.WorkspaceID) == 0 {
+		return bson.M{workspaceIDField: nil}
+	}
+	return bson.M{workspaceIDField: c.WorkspaceID}
+}
+func (c *WorkspaceFieldFilterer) GetFilter() expression.ConditionBuilder {
+	if len(c.WorkspaceID) == 0 {
+		return expression.AttributeNotExists(expression.Name(workspaceIDField))
+	}
+	return expression.Equal(expression.Name(workspaceIDField), expression.Value(c.WorkspaceID))
+}
+
+// idValues matches references stored either as ObjectIDs or as plain strings.
+func idValues(id string) bson.A {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return bson.A{id}
+	}
+	return bson.A{oid, id}
+}
@@ -8,6 +8,9 @@ import (
 type Filter interface {
 	ToBSON() bson.M
 	ToExpression() (expression.Expression, error)
+	GetIndexName() string
+	// InWorkspace returns the filter restricted to the items of the workspace.
+	InWorkspace(string) Filter
 }
 type EmptyFilter struct {
 	DataType DataTyper
@@ -25,6 +28,12 @@ func (f EmptyFilter) ToExpression() (expression.Expression, error) {
 	KeyCond := f.DataType.GetKeyCondition()
 	return expression.NewBuilder().WithKeyCondition(KeyCond).Build()
 }
+func (f EmptyFilter) GetIndexName() string {
+	return f.DataType.GetIndexName()
+}
+func (f EmptyFilter) InWorkspace(workspaceID string) Filter {
+	return newWorkspaceFilter(&EmptyFilter{DataType: scopeDataType(f.DataType, workspaceID)}, workspaceID)
+}
 
 type SimpleFilter struct {
 	DataType DataTyper
@@ -44,6 +53,12 @@ func (f SimpleFilter) ToBSON() bson.M {
 func (f SimpleFilter) ToExpression() (expression.Expression, error) {
 	return buildExpression(f.DataType, f.Field.GetFilter())
 }
+func (f SimpleFilter) GetIndexName() string {
+	return f.DataType.GetIndexName()
+}
+func (f SimpleFilter) InWorkspace(workspaceID string) Filter {
+	return newWorkspaceFilter(&SimpleFilter{DataType: scopeDataType(f.DataType, workspaceID), Field: f.Field}, workspaceID)
+}
 
 type CompositeFilter struct {
 	DataType DataTyper
@@ -70,6 +85,105 @@ func (f CompositeFilter) ToExpression() (expression.Expression, error) {
 	return buildExpression(f.DataType, filter)
 
 }
+func (f CompositeFilter) GetIndexName() string {
+	return f.DataType.GetIndexName()
+}
+func (f CompositeFilter) InWorkspace(workspaceID string) Filter {
+	return newWorkspaceFilter(&CompositeFilter{DataType: scopeDataType(f.DataType, workspaceID), Field1: f.Field1, Field2: f.Field2}, workspaceID)
+}
+
+type KeyFilterer interface {
+	DataTyper
+	FieldFilterer
+}
+
+// IndexedFilter queries by a key that is also stored in the document, so the
+// key itself is the only condition on Mongo and the key condition on DynamoDB.
+type IndexedFilter struct {
+	Key    KeyFilterer
+	Fields []FieldFilterer
+}
+
+func NewIndexedFilter(key KeyFilterer, fields ...FieldFilterer) Filter {
+	return &IndexedFilter{
+		Key:    key,
+		Fields: fields,
+	}
+}
+func (f IndexedFilter) ToBSON() bson.M {
+	filters := []bson.M{f.Key.GetBSONFilter()}
+	for _, field := range f.Fields {
+		filters = append(filters, field.GetBSONFilter())
+	}
+	return bson.M{"$and": filters}
+}
+func (f IndexedFilter) ToExpression() (expression.Expression, error) {
+	builder := expression.NewBuilder().WithKeyCondition(f.Key.GetKeyCondition())
+	switch len(f.Fields) {
+	case 0:
+	case 1:
+		builder = builder.WithFilter(f.Fields[0].GetFilter())
+	default:
+		others := make([]expression.ConditionBuilder, 0, len(f.Fields)-2)
+		for _, field := range f.Fields[2:] {
+			others = append(others, field.GetFilter())
+		}
+		builder = builder.WithFilter(expression.And(f.Fields[0].GetFilter(), f.Fields[1].GetFilter(), others...))
+	}
+	return builder.Build()
+}
+func (f IndexedFilter) GetIndexName() string {
+	return f.Key.GetIndexName()
+}
+
+// InWorkspace adds the workspace as a field, since the key isn't partitioned by
+// workspace.
+func (f IndexedFilter) InWorkspace(workspaceID string) Filter {
+	fields := make([]FieldFilterer, 0, len(f.Fields)+1)
+	for _, field := range f.Fields {
+		if _, ok := field.(*WorkspaceFieldFilterer); !ok {
+			fields = append(fields, field)
+		}
+	}
+	return &IndexedFilter{
+		Key:    f.Key,
+		Fields: append(fields, NewWorkspaceFieldFilterer(workspaceID)),
+	}
+}
+
+// workspaceFilter restricts a filter on a data type to a workspace. On DynamoDB the
+// data type of the filter is already partitioned by workspace, while Mongo needs the
+// workspace as a condition.
+type workspaceFilter struct {
+	Filter
+	workspace FieldFilterer
+}
+
+func newWorkspaceFilter(filter Filter, workspaceID string) Filter {
+	return &workspaceFilter{
+		Filter:    filter,
+		workspace: NewWorkspaceFieldFilterer(workspaceID),
+	}
+}
+func (f workspaceFilter) ToBSON() bson.M {
+	return bson.M{"$and": []bson.M{
+		f.Filter.ToBSON(),
+		f.workspace.GetBSONFilter(),
+	}}
+}
+func (f workspaceFilter) InWorkspace(workspaceID string) Filter {
+	return f.Filter.InWorkspace(workspaceID)
+}
+
+func scopeDataType(dataType DataTyper, workspaceID string) DataTyper {
+	if d, ok := dataType.(*DataType); ok {
+		return &DataType{
+			DataType:    d.DataType,
+			WorkspaceID: workspaceID,
+		}
+	}
+	return dataType
+}
 
 func buildExpression(dataType DataTyper, filter expression.ConditionBuilder) (expression.Expression, error) {
 	keyCond := dataType.GetKeyCondition()
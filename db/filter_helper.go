@@ -18,3 +18,67 @@ func NewUserTasksFilter(completed *bool, id string) Filter {
 	}
 	return NewSimpleFilter(dataType, fieldFiltered1)
 }
+
+func NewUserProjectsFilter(archived bool, userID string) Filter {
+	dataType := NewDataType(types.ProjectDataType)
+	return NewCompositeFilter(dataType, NewMemberFieldFilterer(userID), NewArchivedFieldFilterer(archived))
+}
+
+func NewMemberProjectsFilter(userID string) Filter {
+	return NewSimpleFilter(NewDataType(types.ProjectDataType), NewMemberFieldFilterer(userID))
+}
+
+// NewTeamTasksFilter returns the tasks of any of the teams, which must not be empty.
+func NewTeamTasksFilter(teamIDs []string, completed *bool, unassigned bool) Filter {
+	fields := []FieldFilterer{NewTeamFieldFilterer(teamIDs...)}
+	if completed != nil {
+		fields = append(fields, NewCompletedFieldFilterer(*completed))
+	}
+	if unassigned {
+		fields = append(fields, NewUnassignedFieldFilterer())
+	}
+	return NewSimpleFilter(NewDataType(types.TaskDataType), NewAndFieldFilterer(fields...))
+}
+
+func NewInvitationsFilter() Filter {
+	return NewEmptyFilter(NewDataType(types.InvitationDataType))
+}
+func NewUserTeamsFilter(userID string) Filter {
+	return NewSimpleFilter(NewDataType(types.TeamDataType), NewTeamMemberFieldFilterer(userID))
+}
+
+func NewProjectTasksFilter(projectID string, completed *bool, assignedTo string) Filter {
+	fields := []FieldFilterer{}
+	if completed != nil {
+		fields = append(fields, NewCompletedFieldFilterer(*completed))
+	}
+	if len(assignedTo) > 0 {
+		fields = append(fields, NewAssigneFieldFilterer(assignedTo))
+	}
+	return NewIndexedFilter(NewProjectFieldFilterer(projectID), fields...)
+}
+
+func NewProjectMilestonesFilter(projectID string) Filter {
+	return NewIndexedFilter(NewProjectFieldFilterer(projectID))
+}
+
+func NewMilestoneTasksFilter(projectID, milestoneID string) Filter {
+	return NewIndexedFilter(NewProjectFieldFilterer(projectID), NewMilestoneFieldFilterer(milestoneID))
+}
+
+func NewUserTemplatesFilter(userID string, kind types.TemplateKind) Filter {
+	dataType := NewDataType(types.TemplateDataType)
+	if len(kind) > 0 {
+		return NewCompositeFilter(dataType, NewUserFieldFilterer(userID), NewKindFieldFilterer(string(kind)))
+	}
+	return NewSimpleFilter(dataType, NewUserFieldFilterer(userID))
+}
+
+// NewUsersFilter returns the users matching all of the fields, or every user without any.
+func NewUsersFilter(fields ...FieldFilterer) Filter {
+	dataType := NewDataType(types.UserDataType)
+	if len(fields) == 0 {
+		return NewEmptyFilter(dataType)
+	}
+	return NewSimpleFilter(dataType, NewAndFieldFilterer(fields...))
+}
@@ -16,6 +16,8 @@ func AddProject(store *db.Store, name, description string, userID string, tasks
 		Description: description,
 		UserID:      userID,
 		Tasks:       tasks,
+		Members:     map[string]types.ProjectRole{userID: types.ProjectRoleOwner},
+		DataType:    types.ProjectDataType,
 	}
 	insertedProject, err := store.Project.InsertProject(context.Background(), project)
 	if err != nil {
@@ -23,6 +25,11 @@ func AddProject(store *db.Store, name, description string, userID string, tasks
 	}
 	return insertedProject
 }
+func AddProjectMember(store *db.Store, projectID, userID string, role types.ProjectRole) {
+	if err := store.Project.Update(context.Background(), projectID, db.ProjectMemberUpdater{UserID: userID, Role: role}); err != nil {
+		log.Fatal(err)
+	}
+}
 func AddTask(store *db.Store, name, description string, dueTo time.Time, completed bool) *types.Task {
 	task := types.NewTaskFromParams(types.NewTaskParams{
 		Name:        name,
@@ -48,8 +55,11 @@ func AddUser(store *db.Store, fn, ln, pwd string, isAdmin, enabled bool) *types.
 		Email:     fmt.Sprintf("%s@%s.com", fn, ln),
 		Password:  pwd,
 	})
-	user.IsAdmin = isAdmin
+	if isAdmin {
+		user.Roles = []types.Role{types.RoleAdmin}
+	}
 	user.Enabled = enabled
+	user.Verified = true
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ficontini/gotasks/types"
+)
+
+type DynamoDBInvitationStore struct {
+	client *dynamodb.Client
+	table  *string
+}
+
+func NewDynamoDBInvitationStore(client *dynamodb.Client) *DynamoDBInvitationStore {
+	return &DynamoDBInvitationStore{
+		client: client,
+		table:  aws.String(invitationColl),
+	}
+}
+
+func (s *DynamoDBInvitationStore) InsertInvitation(ctx context.Context, invitation *types.Invitation) error {
+	scopeItem(ctx, invitation)
+	item, err := attributevalue.MarshalMap(invitation)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: s.table, Item: item,
+	})
+	return err
+}
+func (s *DynamoDBInvitationStore) GetInvitationByID(ctx context.Context, id string) (*types.Invitation, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: s.table,
+		Key:       key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Item == nil {
+		return nil, ErrorNotFound
+	}
+	var invitation *types.Invitation
+	if err := attributevalue.UnmarshalMap(res.Item, &invitation); err != nil {
+		return nil, err
+	}
+	if !inWorkspace(ctx, invitation.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
+	return invitation, nil
+}
+func (s *DynamoDBInvitationStore) GetInvitations(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Invitation, error) {
+	expr, err := scopeFilter(ctx, filter).ToExpression()
+	if err != nil {
+		return nil, err
+	}
+	pagination.generatePaginationForDynamoDB()
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 s.table,
+		IndexName:                 aws.String(filter.GetIndexName()),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		Limit:                     aws.Int32(int32(pagination.Limit)),
+	}
+	opts := NewDynamoDBQueryOptions(queryInput, pagination)
+	collectiveResult, err := PaginatedDynamoDBQuery(ctx, s.client, opts)
+	if err != nil {
+		return nil, err
+	}
+	start := pagination.Offset
+	var invitations []*types.Invitation
+	if start > len(collectiveResult) {
+		return invitations, nil
+	}
+	endIdx := Min(start+int(pagination.Limit), len(collectiveResult))
+	if err := attributevalue.UnmarshalListOfMaps(collectiveResult[start:endIdx], &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+func (s *DynamoDBInvitationStore) ConsumeInvitation(ctx context.Context, id string) (*types.Invitation, error) {
+	input, err := newDeleteItemInput(ctx, s.table, id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.DeleteItem(ctx, input)
+	if err != nil {
+		return nil, notFoundIfConditionFailed(err)
+	}
+	if len(res.Attributes) == 0 {
+		return nil, ErrorNotFound
+	}
+	var invitation *types.Invitation
+	if err := attributevalue.UnmarshalMap(res.Attributes, &invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+func (s *DynamoDBInvitationStore) Delete(ctx context.Context, id string) error {
+	_, err := s.ConsumeInvitation(ctx, id)
+	return err
+}
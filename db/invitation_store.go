@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const invitationColl = "invitations"
+
+type InvitationStore interface {
+	InsertInvitation(context.Context, *types.Invitation) error
+	GetInvitationByID(context.Context, string) (*types.Invitation, error)
+	GetInvitations(context.Context, Filter, *Pagination) ([]*types.Invitation, error)
+	// ConsumeInvitation deletes the invitation and returns it, so an invitation can only be accepted once.
+	ConsumeInvitation(context.Context, string) (*types.Invitation, error)
+	Delete(context.Context, string) error
+}
+
+type MongoInvitationStore struct {
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+func NewMongoInvitationStore(client *mongo.Client) *MongoInvitationStore {
+	return &MongoInvitationStore{
+		client: client,
+		coll:   client.Database(DBNAME).Collection(invitationColl),
+	}
+}
+
+func (s *MongoInvitationStore) InsertInvitation(ctx context.Context, invitation *types.Invitation) error {
+	scopeItem(ctx, invitation)
+	_, err := s.coll.InsertOne(ctx, invitation)
+	return err
+}
+func (s *MongoInvitationStore) GetInvitationByID(ctx context.Context, id string) (*types.Invitation, error) {
+	var invitation *types.Invitation
+	if err := s.coll.FindOne(ctx, bson.M{mongoIDField: id}).Decode(&invitation); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
+		return nil, err
+	}
+	if !inWorkspace(ctx, invitation.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
+	return invitation, nil
+}
+func (s *MongoInvitationStore) GetInvitations(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Invitation, error) {
+	opts := pagination.getOptions()
+	cur, err := s.coll.Find(ctx, scopeFilter(ctx, filter).ToBSON(), opts)
+	if err != nil {
+		return nil, err
+	}
+	var invitations []*types.Invitation
+	if err := cur.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+func (s *MongoInvitationStore) ConsumeInvitation(ctx context.Context, id string) (*types.Invitation, error) {
+	var invitation *types.Invitation
+	if err := s.coll.FindOneAndDelete(ctx, scopeIDFilter(ctx, id)).Decode(&invitation); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+func (s *MongoInvitationStore) Delete(ctx context.Context, id string) error {
+	res, err := s.coll.DeleteOne(ctx, scopeIDFilter(ctx, id))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrorNotFound
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ficontini/gotasks/types"
+)
+
+// LoginAttemptTTL is how long failed sign-ins are remembered after the last one.
+const LoginAttemptTTL = 24 * time.Hour
+
+// LoginAttemptStore counts failed sign-ins. The counters are short-lived, so they can
+// live in memory or in a cache shared by every instance of the API.
+type LoginAttemptStore interface {
+	GetLoginAttempts(context.Context, string) (*types.LoginAttempts, error)
+	AddFailedLogin(context.Context, string, time.Time) (*types.LoginAttempts, error)
+	ResetLoginAttempts(context.Context, string) error
+}
+
+// MemoryLoginAttemptStore keeps the counters of a single instance in memory.
+type MemoryLoginAttemptStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	attempts  map[string]types.LoginAttempts
+	lastPrune time.Time
+}
+
+func NewMemoryLoginAttemptStore(ttl time.Duration) *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{
+		ttl:      ttl,
+		attempts: map[string]types.LoginAttempts{},
+	}
+}
+
+func (s *MemoryLoginAttemptStore) GetLoginAttempts(ctx context.Context, key string) (*types.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempts := s.get(key, time.Now())
+	return &attempts, nil
+}
+func (s *MemoryLoginAttemptStore) AddFailedLogin(ctx context.Context, key string, now time.Time) (*types.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	attempts := s.get(key, now)
+	attempts.Failures++
+	attempts.LastFailure = now
+	s.attempts[key] = attempts
+	return &attempts, nil
+}
+func (s *MemoryLoginAttemptStore) ResetLoginAttempts(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+func (s *MemoryLoginAttemptStore) get(key string, now time.Time) types.LoginAttempts {
+	attempts, ok := s.attempts[key]
+	if !ok || now.Sub(attempts.LastFailure) > s.ttl {
+		return types.LoginAttempts{Key: key}
+	}
+	return attempts
+}
+
+// prune drops the expired counters at most once per TTL, so keys that are never
+// seen again, like the IPs of an attack, don't pile up.
+func (s *MemoryLoginAttemptStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < s.ttl {
+		return
+	}
+	for key, attempts := range s.attempts {
+		if now.Sub(attempts.LastFailure) > s.ttl {
+			delete(s.attempts, key)
+		}
+	}
+	s.lastPrune = now
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ficontini/gotasks/types"
+	"github.com/google/uuid"
+)
+
+type DynamoDBMilestoneStore struct {
+	client *dynamodb.Client
+	table  *string
+}
+
+func NewDynamoDBMilestoneStore(client *dynamodb.Client) *DynamoDBMilestoneStore {
+	return &DynamoDBMilestoneStore{
+		client: client,
+		table:  aws.String(milestoneColl),
+	}
+}
+func (s *DynamoDBMilestoneStore) InsertMilestone(ctx context.Context, milestone *types.Milestone) (*types.Milestone, error) {
+	scopeItem(ctx, milestone)
+	milestone.ID = uuid.New().String()
+	item, err := attributevalue.MarshalMap(milestone)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: s.table, Item: item,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return milestone, nil
+}
+func (s *DynamoDBMilestoneStore) GetMilestoneByID(ctx context.Context, id string) (*types.Milestone, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: s.table,
+		Key:       key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Item == nil {
+		return nil, ErrorNotFound
+	}
+	var milestone *types.Milestone
+	if err := attributevalue.UnmarshalMap(res.Item, &milestone); err != nil {
+		return nil, err
+	}
+	if !inWorkspace(ctx, milestone.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
+	return milestone, nil
+}
+func (s *DynamoDBMilestoneStore) GetMilestones(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Milestone, error) {
+	expr, err := scopeFilter(ctx, filter).ToExpression()
+	if err != nil {
+		return nil, err
+	}
+	pagination.generatePaginationForDynamoDB()
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 s.table,
+		IndexName:                 aws.String(filter.GetIndexName()),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		Limit:                     aws.Int32(int32(pagination.Limit)),
+	}
+	opts := NewDynamoDBQueryOptions(queryInput, pagination)
+	collectiveResult, err := PaginatedDynamoDBQuery(ctx, s.client, opts)
+	if err != nil {
+		return nil, err
+	}
+	start := pagination.Offset
+	var milestones []*types.Milestone
+	if start > len(collectiveResult) {
+		return milestones, nil
+	}
+	endIdx := Min(start+int(pagination.Limit), len(collectiveResult))
+	if err := attributevalue.UnmarshalListOfMaps(collectiveResult[start:endIdx], &milestones); err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+func (s *DynamoDBMilestoneStore) Update(ctx context.Context, id string, params Update) error {
+	input, err := newUpdateItemInput(ctx, s.table, id, params)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.UpdateItem(ctx, input)
+	return notFoundIfConditionFailed(err)
+}
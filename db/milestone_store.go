@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const milestoneColl = "milestones"
+
+type MilestoneStore interface {
+	InsertMilestone(context.Context, *types.Milestone) (*types.Milestone, error)
+	GetMilestoneByID(context.Context, string) (*types.Milestone, error)
+	GetMilestones(context.Context, Filter, *Pagination) ([]*types.Milestone, error)
+	Update(context.Context, string, Update) error
+}
+
+type MongoMilestoneStore struct {
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+func NewMongoMilestoneStore(client *mongo.Client) *MongoMilestoneStore {
+	return &MongoMilestoneStore{
+		client: client,
+		coll:   client.Database(DBNAME).Collection(milestoneColl),
+	}
+}
+func (s *MongoMilestoneStore) InsertMilestone(ctx context.Context, milestone *types.Milestone) (*types.Milestone, error) {
+	scopeItem(ctx, milestone)
+	res, err := s.coll.InsertOne(ctx, milestone)
+	if err != nil {
+		return nil, err
+	}
+	milestone.ID = res.InsertedID.(primitive.ObjectID).Hex()
+	return milestone, nil
+}
+func (s *MongoMilestoneStore) GetMilestoneByID(ctx context.Context, id string) (*types.Milestone, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	var milestone *types.Milestone
+	if err := s.coll.FindOne(ctx, bson.M{mongoIDField: oid}).Decode(&milestone); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
+		return nil, err
+	}
+	if !inWorkspace(ctx, milestone.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
+	return milestone, nil
+}
+func (s *MongoMilestoneStore) GetMilestones(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Milestone, error) {
+	opts := pagination.getOptions()
+	cur, err := s.coll.Find(ctx, scopeFilter(ctx, filter).ToBSON(), opts)
+	if err != nil {
+		return nil, err
+	}
+	var milestones []*types.Milestone
+	if err := cur.All(ctx, &milestones); err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+func (s *MongoMilestoneStore) Update(ctx context.Context, id string, params Update) error {
+	return updateByID(ctx, s.coll, id, params)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -30,10 +32,19 @@ func NewStore() (*Store, error) {
 	}
 	taskStore := NewMongoTaskStore(client)
 	return &Store{
-		Auth:    NewDynamoDBAuthStore(dynamoClient),
-		User:    NewMongoUserStore(client),
-		Task:    taskStore,
-		Project: NewMongoProjectStore(client, taskStore),
+		Auth:         NewDynamoDBAuthStore(dynamoClient),
+		User:         NewMongoUserStore(client),
+		Task:         taskStore,
+		Project:      NewMongoProjectStore(client, taskStore),
+		ProjectStats: NewMongoProjectStatsStore(client),
+		Milestone:    NewMongoMilestoneStore(client),
+		Template:     NewMongoTemplateStore(client),
+		Token:        NewMongoTokenStore(client),
+		AccessToken:  NewMongoAccessTokenStore(client),
+		Team:         NewMongoTeamStore(client),
+		Workspace:    NewMongoWorkspaceStore(client),
+		Invitation:   NewMongoInvitationStore(client),
+		LoginAttempt: NewMemoryLoginAttemptStore(LoginAttemptTTL),
 	}, nil
 }
 func NewMongoClient() (*mongo.Client, error) {
@@ -58,3 +69,55 @@ func SetupMongoConfigFromEnv() error {
 	}
 	return nil
 }
+
+func updateByID(ctx context.Context, coll *mongo.Collection, id string, params Update) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	update, err := params.ToBSON()
+	if err != nil {
+		return err
+	}
+	res, err := coll.UpdateOne(ctx, scopeIDFilter(ctx, oid), update)
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount == 0 {
+		return ErrorNotFound
+	}
+	return nil
+}
+func deleteByID(ctx context.Context, coll *mongo.Collection, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	res, err := coll.DeleteOne(ctx, scopeIDFilter(ctx, oid))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrorNotFound
+	}
+	return nil
+}
+
+// insertWithID inserts item with its hex ID stored as an ObjectID, like InsertOne does for new documents.
+func insertWithID(ctx context.Context, coll *mongo.Collection, id string, item interface{}) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	data, err := bson.Marshal(item)
+	if err != nil {
+		return err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	doc[mongoIDField] = oid
+	_, err = coll.InsertOne(ctx, doc)
+	return err
+}
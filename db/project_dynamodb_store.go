@@ -2,7 +2,6 @@ package db
 
 import (
 	"context"
-	"log"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -24,6 +23,7 @@ func NewDynamoDBProjectStore(client *dynamodb.Client) *DynamoDBProjectStore {
 	}
 }
 func (s *DynamoDBProjectStore) InsertProject(ctx context.Context, project *types.Project) (*types.Project, error) {
+	scopeItem(ctx, project)
 	project.ID = uuid.New().String()
 	item, err := attributevalue.MarshalMap(project)
 	if err != nil {
@@ -57,33 +57,85 @@ func (s *DynamoDBProjectStore) GetProjectByID(ctx context.Context, id string) (*
 	if err := attributevalue.UnmarshalMap(res.Item, &project); err != nil {
 		return nil, err
 	}
+	if !inWorkspace(ctx, project.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
 	return project, nil
 }
+func (s *DynamoDBProjectStore) Update(ctx context.Context, id string, params Update) error {
+	input, err := newUpdateItemInput(ctx, s.table, id, params)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.UpdateItem(ctx, input)
+	return notFoundIfConditionFailed(err)
+}
 
-// TODO: review
 func (s *DynamoDBProjectStore) TransactAddTask(ctx context.Context, actions []*UpdateAction) error {
+	dbActions := make([]DBAction, 0, len(actions))
+	for _, action := range actions {
+		dbActions = append(dbActions, action)
+	}
+	return s.Transact(ctx, dbActions)
+}
+
+func (s *DynamoDBProjectStore) NewID() string {
+	return uuid.New().String()
+}
+func (s *DynamoDBProjectStore) Transact(ctx context.Context, actions []DBAction) error {
+	if len(actions) == 0 || len(actions) > MaxTransactItems {
+		return ErrInvalidBatchSize
+	}
 	operations := make([]dynamodbtypes.TransactWriteItem, 0, len(actions))
 	for _, action := range actions {
-		operation, err := action.get()
+		switch a := action.(type) {
+		case *InsertAction:
+			scopeItem(ctx, a.Item)
+		case *UpdateAction:
+			a.scope = scopeCondition(ctx)
+		case *DeleteAction:
+			a.scope = scopeCondition(ctx)
+		}
+		writeItem, err := newTransactWriteItem(action)
 		if err != nil {
 			return err
 		}
-		update, ok := operation.(*dynamodbtypes.Update)
-		if !ok {
-			return ErrInvalidOperationType
-		}
-		writeItem := dynamodbtypes.TransactWriteItem{
-			Update: update,
-		}
 		operations = append(operations, writeItem)
-
 	}
 	_, err := s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
 		TransactItems: operations,
 	})
+	return err
+}
+
+func (s *DynamoDBProjectStore) GetProjects(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Project, error) {
+	expr, err := scopeFilter(ctx, filter).ToExpression()
 	if err != nil {
-		log.Fatal(err)
-		return err
+		return nil, err
+	}
+	pagination.generatePaginationForDynamoDB()
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 s.table,
+		IndexName:                 aws.String(filter.GetIndexName()),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		Limit:                     aws.Int32(int32(pagination.Limit)),
+	}
+	opts := NewDynamoDBQueryOptions(queryInput, pagination)
+	collectiveResult, err := PaginatedDynamoDBQuery(ctx, s.client, opts)
+	if err != nil {
+		return nil, err
+	}
+	start := pagination.Offset
+	var projects []*types.Project
+	if start > len(collectiveResult) {
+		return projects, nil
+	}
+	endIdx := Min(start+int(pagination.Limit), len(collectiveResult))
+	if err := attributevalue.UnmarshalListOfMaps(collectiveResult[start:endIdx], &projects); err != nil {
+		return nil, err
 	}
-	return nil
+	return projects, nil
 }
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ficontini/gotasks/types"
+)
+
+// taskSummaryPrefix names the attributes of the summary item holding a task each, so
+// a transaction can set or remove one without reading the others.
+const taskSummaryPrefix = "task_"
+
+type DynamoDBProjectStatsStore struct {
+	client *dynamodb.Client
+	table  *string
+}
+
+func NewDynamoDBProjectStatsStore(client *dynamodb.Client) *DynamoDBProjectStatsStore {
+	return &DynamoDBProjectStatsStore{
+		client: client,
+		table:  aws.String(projectStatsColl),
+	}
+}
+
+func (s *DynamoDBProjectStatsStore) GetProjectStats(ctx context.Context, projectID string) (*types.ProjectStats, error) {
+	key, err := GetKey(projectID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: s.table,
+		Key:       key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]types.TaskSummary, 0, len(res.Item))
+	for name, value := range res.Item {
+		if !strings.HasPrefix(name, taskSummaryPrefix) {
+			continue
+		}
+		var summary types.TaskSummary
+		if err := attributevalue.Unmarshal(value, &summary); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return types.NewProjectStats(projectID, summaries, time.Now()), nil
+}
+
+// newProjectStatsItem returns the summary item of the project with its tasks.
+func newProjectStatsItem(projectID string, tasks []*types.Task) (map[string]dynamodbtypes.AttributeValue, error) {
+	item, err := GetKey(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		summary, err := attributevalue.Marshal(types.NewTaskSummary(task))
+		if err != nil {
+			return nil, err
+		}
+		item[taskSummaryName(task.ID)] = summary
+	}
+	return item, nil
+}
+
+func taskSummaryName(taskID string) string {
+	return taskSummaryPrefix + taskID
+}
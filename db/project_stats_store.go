@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const projectStatsColl = "projectStats"
+
+// ProjectStatsStore computes project statistics. Backends that cannot aggregate on
+// read keep a summary of the tasks of every project, written by ProjectStatsAction in
+// the transactions changing them.
+type ProjectStatsStore interface {
+	GetProjectStats(context.Context, string) (*types.ProjectStats, error)
+}
+
+type MongoProjectStatsStore struct {
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+func NewMongoProjectStatsStore(client *mongo.Client) *MongoProjectStatsStore {
+	return &MongoProjectStatsStore{
+		client: client,
+		coll:   client.Database(DBNAME).Collection(taskColl),
+	}
+}
+
+type statusCount struct {
+	Completed bool `bson:"_id"`
+	Count     int  `bson:"count"`
+}
+
+type assigneeCount struct {
+	AssignedTo string `bson:"_id"`
+	Total      int    `bson:"total"`
+	Completed  int    `bson:"completed"`
+}
+
+type dateCount struct {
+	Date  string `bson:"_id"`
+	Count int    `bson:"count"`
+}
+
+type projectStatsFacets struct {
+	Status      []statusCount   `bson:"status"`
+	Overdue     []dateCount     `bson:"overdue"`
+	DueThisWeek []dateCount     `bson:"dueThisWeek"`
+	Assignees   []assigneeCount `bson:"assignees"`
+	Trend       []dateCount     `bson:"trend"`
+}
+
+func (s *MongoProjectStatsStore) GetProjectStats(ctx context.Context, projectID string) (*types.ProjectStats, error) {
+	oid, err := primitive.ObjectIDFromHex(projectID)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	var (
+		now      = time.Now().UTC()
+		pipeline = mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{projectIDField: bson.M{"$in": bson.A{oid, projectID}}}}},
+			{{Key: "$facet", Value: bson.M{
+				"status": bson.A{
+					bson.M{"$group": bson.M{mongoIDField: "$" + completedField, "count": bson.M{"$sum": 1}}},
+				},
+				"overdue": bson.A{
+					bson.M{"$match": bson.M{completedField: false, dueDateField: bson.M{"$lt": now}}},
+					bson.M{"$count": "count"},
+				},
+				"dueThisWeek": bson.A{
+					bson.M{"$match": bson.M{completedField: false, dueDateField: bson.M{"$gte": now, "$lt": now.AddDate(0, 0, 7)}}},
+					bson.M{"$count": "count"},
+				},
+				"assignees": bson.A{
+					bson.M{"$group": bson.M{
+						mongoIDField: bson.M{"$ifNull": bson.A{bson.M{"$toString": "$" + assignedToField}, ""}},
+						"total":      bson.M{"$sum": 1},
+						"completed":  bson.M{"$sum": bson.M{"$cond": bson.A{"$" + completedField, 1, 0}}},
+					}},
+				},
+				"trend": bson.A{
+					bson.M{"$match": bson.M{completedField: true, completedAtField: bson.M{"$gte": types.TrendStart(now)}}},
+					bson.M{"$group": bson.M{
+						mongoIDField: bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$" + completedAtField}},
+						"count":      bson.M{"$sum": 1},
+					}},
+				},
+			}}},
+		}
+	)
+	cur, err := s.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var facets []projectStatsFacets
+	if err := cur.All(ctx, &facets); err != nil {
+		return nil, err
+	}
+	stats := types.NewEmptyProjectStats(projectID)
+	if len(facets) == 0 {
+		stats.SetTrend(nil, now)
+		return stats, nil
+	}
+	res := facets[0]
+	for _, status := range res.Status {
+		stats.Total += status.Count
+		if status.Completed {
+			stats.Completed += status.Count
+		} else {
+			stats.Open += status.Count
+		}
+	}
+	if len(res.Overdue) > 0 {
+		stats.Overdue = res.Overdue[0].Count
+	}
+	if len(res.DueThisWeek) > 0 {
+		stats.DueThisWeek = res.DueThisWeek[0].Count
+	}
+	for _, assignee := range res.Assignees {
+		key := assignee.AssignedTo
+		if len(key) == 0 {
+			key = types.UnassignedTasksKey
+		}
+		stats.ByAssignee[key] = &types.AssigneeStats{
+			Total:     assignee.Total,
+			Completed: assignee.Completed,
+			Open:      assignee.Total - assignee.Completed,
+		}
+	}
+	completions := make(map[string]int, len(res.Trend))
+	for _, day := range res.Trend {
+		completions[day.Date] = day.Count
+	}
+	stats.SetPercentComplete()
+	stats.SetTrend(completions, now)
+	return stats, nil
+}
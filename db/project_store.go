@@ -14,8 +14,12 @@ const projectColl = "projects"
 
 type ProjectStore interface {
 	GetProjectByID(context.Context, string) (*types.Project, error)
+	GetProjects(context.Context, Filter, *Pagination) ([]*types.Project, error)
 	InsertProject(context.Context, *types.Project) (*types.Project, error)
+	Update(context.Context, string, Update) error
 	TransactAddTask(context.Context, []*UpdateAction) error
+	Transact(context.Context, []DBAction) error
+	NewID() string
 }
 
 type MongoProjectStore struct {
@@ -32,6 +36,7 @@ func NewMongoProjectStore(client *mongo.Client, taskStore TaskStore) *MongoProje
 	}
 }
 func (s *MongoProjectStore) InsertProject(ctx context.Context, project *types.Project) (*types.Project, error) {
+	scopeItem(ctx, project)
 	res, err := s.coll.InsertOne(ctx, project)
 	if err != nil {
 		return nil, ErrInvalidID
@@ -51,51 +56,70 @@ func (s *MongoProjectStore) GetProjectByID(ctx context.Context, id string) (*typ
 		}
 		return nil, err
 	}
+	if !inWorkspace(ctx, project.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
 	return project, nil
 }
 func (s *MongoProjectStore) Update(ctx context.Context, id string, params Update) error {
-	oid, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
-	}
-	update, err := params.ToBSON()
+	return updateByID(ctx, s.coll, id, params)
+}
+
+func (s *MongoProjectStore) GetProjects(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Project, error) {
+	opts := pagination.getOptions()
+	cur, err := s.coll.Find(ctx, scopeFilter(ctx, filter).ToBSON(), opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	res, err := s.coll.UpdateByID(ctx, oid, update)
-	if err != nil {
-		return err
+	var projects []*types.Project
+	if err := cur.All(ctx, &projects); err != nil {
+		return nil, err
 	}
-	if res.ModifiedCount == 0 {
-		return ErrorNotFound
+	return projects, nil
+}
+func (s *MongoProjectStore) TransactAddTask(ctx context.Context, actions []*UpdateAction) error {
+	dbActions := make([]DBAction, 0, len(actions))
+	for _, action := range actions {
+		dbActions = append(dbActions, action)
 	}
-	return nil
+	return s.Transact(ctx, dbActions)
 }
 
-// TODO: Review
-func (s *MongoProjectStore) TransactAddTask(ctx context.Context, actions []*UpdateAction) error {
+// Transact runs every action inside a single session transaction.
+func (s *MongoProjectStore) Transact(ctx context.Context, actions []DBAction) error {
 	session, err := s.client.StartSession()
-	defer session.EndSession(ctx)
 	if err != nil {
 		return err
 	}
-	session.StartTransaction()
-	for _, action := range actions {
-		switch action.TableName {
-		case taskColl:
-			err = s.TaskStore.Update(ctx, action.ID, action.Params)
-			if err != nil {
-				session.AbortTransaction(ctx)
-				return err
-			}
-		default:
-			err = s.Update(ctx, action.ID, action.Params)
-			if err != nil {
-				session.AbortTransaction(ctx)
-				return err
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		for _, action := range actions {
+			if err := s.apply(sessCtx, action); err != nil {
+				return nil, err
 			}
 		}
-	}
-	err = session.CommitTransaction(ctx)
+		return nil, nil
+	})
 	return err
 }
+func (s *MongoProjectStore) apply(ctx context.Context, action DBAction) error {
+	switch a := action.(type) {
+	case *UpdateAction:
+		return updateByID(ctx, s.collection(a.TableName), a.ID, a.Params)
+	case *DeleteAction:
+		return deleteByID(ctx, s.collection(a.TableName), a.ID)
+	case *InsertAction:
+		scopeItem(ctx, a.Item)
+		return insertWithID(ctx, s.collection(a.TableName), a.ID, a.Item)
+	case *ProjectStatsAction:
+		return nil
+	default:
+		return ErrInvalidOperationType
+	}
+}
+func (s *MongoProjectStore) NewID() string {
+	return primitive.NewObjectID().Hex()
+}
+func (s *MongoProjectStore) collection(name string) *mongo.Collection {
+	return s.client.Database(DBNAME).Collection(name)
+}
@@ -1,10 +1,19 @@
 package db
 
 type Store struct {
-	Auth    AuthStore
-	User    UserStore
-	Task    TaskStore
-	Project ProjectStore
+	Auth         AuthStore
+	User         UserStore
+	Task         TaskStore
+	Project      ProjectStore
+	ProjectStats ProjectStatsStore
+	Milestone    MilestoneStore
+	Template     TemplateStore
+	Token        TokenStore
+	AccessToken  AccessTokenStore
+	Team         TeamStore
+	Workspace    WorkspaceStore
+	Invitation   InvitationStore
+	LoginAttempt LoginAttemptStore
 }
 
 type Option struct {
@@ -4,9 +4,7 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/ficontini/gotasks/types"
 	"github.com/google/uuid"
@@ -19,18 +17,17 @@ const (
 type DynamoDBTaskStore struct {
 	client *dynamodb.Client
 	table  *string
-	gsi    *string
 }
 
 func NewDynamoDBTaskStore(client *dynamodb.Client) *DynamoDBTaskStore {
 	return &DynamoDBTaskStore{
 		client: client,
 		table:  aws.String(taskColl),
-		gsi:    aws.String(dataTypeGSI),
 	}
 }
 
 func (s *DynamoDBTaskStore) InsertTask(ctx context.Context, task *types.Task) (*types.Task, error) {
+	scopeItem(ctx, task)
 	task.ID = uuid.New().String()
 	item, err := attributevalue.MarshalMap(task)
 	if err != nil {
@@ -45,40 +42,21 @@ func (s *DynamoDBTaskStore) InsertTask(ctx context.Context, task *types.Task) (*
 	return task, nil
 }
 func (s *DynamoDBTaskStore) Update(ctx context.Context, id string, params Update) error {
-	key, err := GetKey(id)
+	input, err := newUpdateItemInput(ctx, s.table, id, params)
 	if err != nil {
 		return err
 	}
-	update := params.ToExpression()
-	expr, err := expression.NewBuilder().WithUpdate(update).Build()
-	if err != nil {
-		return err
-	}
-	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
-		TableName:                 s.table,
-		Key:                       key,
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		UpdateExpression:          expr.Update(),
-		ReturnValues:              dynamodbtypes.ReturnValueUpdatedNew,
-	})
-	if err != nil {
-		return err
-	}
-	return nil
+	_, err = s.client.UpdateItem(ctx, input)
+	return notFoundIfConditionFailed(err)
 }
 func (s *DynamoDBTaskStore) Delete(ctx context.Context, id string) error {
-	key, err := GetKey(id)
+	input, err := newDeleteItemInput(ctx, s.table, id)
 	if err != nil {
 		return err
 	}
-	res, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		TableName:    s.table,
-		Key:          key,
-		ReturnValues: ReturnAllOld,
-	})
+	res, err := s.client.DeleteItem(ctx, input)
 	if err != nil {
-		return err
+		return notFoundIfConditionFailed(err)
 	}
 	if len(res.Attributes) == 0 {
 		return ErrorNotFound
@@ -104,12 +82,15 @@ func (s *DynamoDBTaskStore) GetTaskByID(ctx context.Context, id string) (*types.
 	if err := attributevalue.UnmarshalMap(res.Item, &task); err != nil {
 		return nil, ErrorNotFound
 	}
+	if !inWorkspace(ctx, task.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
 	return task, nil
 }
 
 // TODO: review
 func (s *DynamoDBTaskStore) GetTasks(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Task, error) {
-	expr, err := filter.ToExpression()
+	expr, err := scopeFilter(ctx, filter).ToExpression()
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +98,7 @@ func (s *DynamoDBTaskStore) GetTasks(ctx context.Context, filter Filter, paginat
 
 	queryInput := &dynamodb.QueryInput{
 		TableName:                 s.table,
-		IndexName:                 s.gsi,
+		IndexName:                 aws.String(filter.GetIndexName()),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		KeyConditionExpression:    expr.KeyCondition(),
@@ -23,6 +23,7 @@ func NewMongoTaskStore(client *mongo.Client) *MongoTaskStore {
 	}
 }
 func (s *MongoTaskStore) InsertTask(ctx context.Context, task *types.Task) (*types.Task, error) {
+	scopeItem(ctx, task)
 	res, err := s.coll.InsertOne(ctx, task)
 	if err != nil {
 		return nil, err
@@ -32,37 +33,11 @@ func (s *MongoTaskStore) InsertTask(ctx context.Context, task *types.Task) (*typ
 }
 
 func (s *MongoTaskStore) Update(ctx context.Context, id string, params Update) error {
-	oid, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
-	}
-	update, err := params.ToBSON()
-	if err != nil {
-		return err
-	}
-	res, err := s.coll.UpdateByID(ctx, oid, update)
-	if err != nil {
-		return err
-	}
-	if res.ModifiedCount == 0 {
-		return ErrorNotFound
-	}
-	return nil
+	return updateByID(ctx, s.coll, id, params)
 }
 
 func (s *MongoTaskStore) Delete(ctx context.Context, id string) error {
-	oid, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
-	}
-	res, err := s.coll.DeleteOne(ctx, bson.M{mongoIDField: oid})
-	if err != nil {
-		return err
-	}
-	if res.DeletedCount == 0 {
-		return ErrorNotFound
-	}
-	return nil
+	return deleteByID(ctx, s.coll, id)
 }
 func (s *MongoTaskStore) GetTaskByID(ctx context.Context, id string) (*types.Task, error) {
 	oid, err := primitive.ObjectIDFromHex(id)
@@ -76,11 +51,14 @@ func (s *MongoTaskStore) GetTaskByID(ctx context.Context, id string) (*types.Tas
 		}
 		return nil, err
 	}
+	if !inWorkspace(ctx, task.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
 	return task, nil
 }
 func (s *MongoTaskStore) GetTasks(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Task, error) {
 	opts := pagination.getOptions()
-	cur, err := s.coll.Find(ctx, filter.ToBSON(), opts)
+	cur, err := s.coll.Find(ctx, scopeFilter(ctx, filter).ToBSON(), opts)
 	if err != nil {
 		return nil, err
 	}
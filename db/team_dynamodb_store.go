@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ficontini/gotasks/types"
+	"github.com/google/uuid"
+)
+
+type DynamoDBTeamStore struct {
+	client *dynamodb.Client
+	table  *string
+}
+
+func NewDynamoDBTeamStore(client *dynamodb.Client) *DynamoDBTeamStore {
+	return &DynamoDBTeamStore{
+		client: client,
+		table:  aws.String(teamColl),
+	}
+}
+func (s *DynamoDBTeamStore) InsertTeam(ctx context.Context, team *types.Team) (*types.Team, error) {
+	scopeItem(ctx, team)
+	team.ID = uuid.New().String()
+	item, err := attributevalue.MarshalMap(team)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: s.table, Item: item,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+func (s *DynamoDBTeamStore) GetTeamByID(ctx context.Context, id string) (*types.Team, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: s.table,
+		Key:       key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Item == nil {
+		return nil, ErrorNotFound
+	}
+	var team *types.Team
+	if err := attributevalue.UnmarshalMap(res.Item, &team); err != nil {
+		return nil, err
+	}
+	if !inWorkspace(ctx, team.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
+	return team, nil
+}
+func (s *DynamoDBTeamStore) GetTeams(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Team, error) {
+	expr, err := scopeFilter(ctx, filter).ToExpression()
+	if err != nil {
+		return nil, err
+	}
+	pagination.generatePaginationForDynamoDB()
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 s.table,
+		IndexName:                 aws.String(filter.GetIndexName()),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		Limit:                     aws.Int32(int32(pagination.Limit)),
+	}
+	opts := NewDynamoDBQueryOptions(queryInput, pagination)
+	collectiveResult, err := PaginatedDynamoDBQuery(ctx, s.client, opts)
+	if err != nil {
+		return nil, err
+	}
+	start := pagination.Offset
+	var teams []*types.Team
+	if start > len(collectiveResult) {
+		return teams, nil
+	}
+	endIdx := Min(start+int(pagination.Limit), len(collectiveResult))
+	if err := attributevalue.UnmarshalListOfMaps(collectiveResult[start:endIdx], &teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+func (s *DynamoDBTeamStore) Update(ctx context.Context, id string, params Update) error {
+	input, err := newUpdateItemInput(ctx, s.table, id, params)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.UpdateItem(ctx, input)
+	return notFoundIfConditionFailed(err)
+}
+func (s *DynamoDBTeamStore) Delete(ctx context.Context, id string) error {
+	input, err := newDeleteItemInput(ctx, s.table, id)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.DeleteItem(ctx, input)
+	if err != nil {
+		return notFoundIfConditionFailed(err)
+	}
+	if len(res.Attributes) == 0 {
+		return ErrorNotFound
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const teamColl = "teams"
+
+type TeamStore interface {
+	InsertTeam(context.Context, *types.Team) (*types.Team, error)
+	GetTeamByID(context.Context, string) (*types.Team, error)
+	GetTeams(context.Context, Filter, *Pagination) ([]*types.Team, error)
+	Update(context.Context, string, Update) error
+	Deleter
+}
+
+type MongoTeamStore struct {
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+func NewMongoTeamStore(client *mongo.Client) *MongoTeamStore {
+	return &MongoTeamStore{
+		client: client,
+		coll:   client.Database(DBNAME).Collection(teamColl),
+	}
+}
+func (s *MongoTeamStore) InsertTeam(ctx context.Context, team *types.Team) (*types.Team, error) {
+	scopeItem(ctx, team)
+	res, err := s.coll.InsertOne(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	team.ID = res.InsertedID.(primitive.ObjectID).Hex()
+	return team, nil
+}
+func (s *MongoTeamStore) GetTeamByID(ctx context.Context, id string) (*types.Team, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	var team *types.Team
+	if err := s.coll.FindOne(ctx, bson.M{mongoIDField: oid}).Decode(&team); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
+		return nil, err
+	}
+	if !inWorkspace(ctx, team.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
+	return team, nil
+}
+func (s *MongoTeamStore) GetTeams(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Team, error) {
+	opts := pagination.getOptions()
+	cur, err := s.coll.Find(ctx, scopeFilter(ctx, filter).ToBSON(), opts)
+	if err != nil {
+		return nil, err
+	}
+	var teams []*types.Team
+	if err := cur.All(ctx, &teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+func (s *MongoTeamStore) Update(ctx context.Context, id string, params Update) error {
+	return updateByID(ctx, s.coll, id, params)
+}
+func (s *MongoTeamStore) Delete(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.coll, id)
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ficontini/gotasks/types"
+	"github.com/google/uuid"
+)
+
+type DynamoDBTemplateStore struct {
+	client *dynamodb.Client
+	table  *string
+}
+
+func NewDynamoDBTemplateStore(client *dynamodb.Client) *DynamoDBTemplateStore {
+	return &DynamoDBTemplateStore{
+		client: client,
+		table:  aws.String(templateColl),
+	}
+}
+func (s *DynamoDBTemplateStore) InsertTemplate(ctx context.Context, template *types.Template) (*types.Template, error) {
+	scopeItem(ctx, template)
+	template.ID = uuid.New().String()
+	item, err := attributevalue.MarshalMap(template)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: s.table, Item: item,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+func (s *DynamoDBTemplateStore) GetTemplateByID(ctx context.Context, id string) (*types.Template, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: s.table,
+		Key:       key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Item == nil {
+		return nil, ErrorNotFound
+	}
+	var template *types.Template
+	if err := attributevalue.UnmarshalMap(res.Item, &template); err != nil {
+		return nil, err
+	}
+	if !inWorkspace(ctx, template.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
+	return template, nil
+}
+func (s *DynamoDBTemplateStore) GetTemplates(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Template, error) {
+	expr, err := scopeFilter(ctx, filter).ToExpression()
+	if err != nil {
+		return nil, err
+	}
+	pagination.generatePaginationForDynamoDB()
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 s.table,
+		IndexName:                 aws.String(filter.GetIndexName()),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		Limit:                     aws.Int32(int32(pagination.Limit)),
+	}
+	opts := NewDynamoDBQueryOptions(queryInput, pagination)
+	collectiveResult, err := PaginatedDynamoDBQuery(ctx, s.client, opts)
+	if err != nil {
+		return nil, err
+	}
+	start := pagination.Offset
+	var templates []*types.Template
+	if start > len(collectiveResult) {
+		return templates, nil
+	}
+	endIdx := Min(start+int(pagination.Limit), len(collectiveResult))
+	if err := attributevalue.UnmarshalListOfMaps(collectiveResult[start:endIdx], &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+func (s *DynamoDBTemplateStore) Delete(ctx context.Context, id string) error {
+	input, err := newDeleteItemInput(ctx, s.table, id)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.DeleteItem(ctx, input)
+	if err != nil {
+		return notFoundIfConditionFailed(err)
+	}
+	if len(res.Attributes) == 0 {
+		return ErrorNotFound
+	}
+	return nil
+}
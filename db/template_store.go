@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const templateColl = "templates"
+
+type TemplateStore interface {
+	InsertTemplate(context.Context, *types.Template) (*types.Template, error)
+	GetTemplateByID(context.Context, string) (*types.Template, error)
+	GetTemplates(context.Context, Filter, *Pagination) ([]*types.Template, error)
+	Deleter
+}
+
+type MongoTemplateStore struct {
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+func NewMongoTemplateStore(client *mongo.Client) *MongoTemplateStore {
+	return &MongoTemplateStore{
+		client: client,
+		coll:   client.Database(DBNAME).Collection(templateColl),
+	}
+}
+func (s *MongoTemplateStore) InsertTemplate(ctx context.Context, template *types.Template) (*types.Template, error) {
+	scopeItem(ctx, template)
+	res, err := s.coll.InsertOne(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+	template.ID = res.InsertedID.(primitive.ObjectID).Hex()
+	return template, nil
+}
+func (s *MongoTemplateStore) GetTemplateByID(ctx context.Context, id string) (*types.Template, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	var template *types.Template
+	if err := s.coll.FindOne(ctx, bson.M{mongoIDField: oid}).Decode(&template); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
+		return nil, err
+	}
+	if !inWorkspace(ctx, template.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
+	return template, nil
+}
+func (s *MongoTemplateStore) GetTemplates(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.Template, error) {
+	opts := pagination.getOptions()
+	cur, err := s.coll.Find(ctx, scopeFilter(ctx, filter).ToBSON(), opts)
+	if err != nil {
+		return nil, err
+	}
+	var templates []*types.Template
+	if err := cur.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+func (s *MongoTemplateStore) Delete(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.coll, id)
+}
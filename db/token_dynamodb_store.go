@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ficontini/gotasks/types"
+)
+
+type DynamoDBTokenStore struct {
+	client *dynamodb.Client
+	table  *string
+}
+
+func NewDynamoDBTokenStore(client *dynamodb.Client) *DynamoDBTokenStore {
+	return &DynamoDBTokenStore{
+		client: client,
+		table:  aws.String(tokenColl),
+	}
+}
+
+func (s *DynamoDBTokenStore) InsertToken(ctx context.Context, token *types.Token) error {
+	item, err := attributevalue.MarshalMap(token)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: s.table, Item: item,
+	})
+	return err
+}
+func (s *DynamoDBTokenStore) GetToken(ctx context.Context, id string) (*types.Token, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: s.table,
+		Key:       key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Item == nil {
+		return nil, ErrorNotFound
+	}
+	var token *types.Token
+	if err := attributevalue.UnmarshalMap(res.Item, &token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+func (s *DynamoDBTokenStore) ConsumeToken(ctx context.Context, id string) (*types.Token, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    s.table,
+		Key:          key,
+		ReturnValues: ReturnAllOld,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Attributes) == 0 {
+		return nil, ErrorNotFound
+	}
+	var token *types.Token
+	if err := attributevalue.UnmarshalMap(res.Attributes, &token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
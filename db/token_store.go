@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const tokenColl = "tokens"
+
+type TokenStore interface {
+	InsertToken(context.Context, *types.Token) error
+	GetToken(context.Context, string) (*types.Token, error)
+	// ConsumeToken deletes the token and returns it, so a token can only be used once.
+	ConsumeToken(context.Context, string) (*types.Token, error)
+}
+
+type MongoTokenStore struct {
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+func NewMongoTokenStore(client *mongo.Client) *MongoTokenStore {
+	return &MongoTokenStore{
+		client: client,
+		coll:   client.Database(DBNAME).Collection(tokenColl),
+	}
+}
+
+func (s *MongoTokenStore) InsertToken(ctx context.Context, token *types.Token) error {
+	_, err := s.coll.InsertOne(ctx, token)
+	return err
+}
+func (s *MongoTokenStore) GetToken(ctx context.Context, id string) (*types.Token, error) {
+	var token *types.Token
+	if err := s.coll.FindOne(ctx, bson.M{mongoIDField: id}).Decode(&token); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
+		return nil, err
+	}
+	return token, nil
+}
+func (s *MongoTokenStore) ConsumeToken(ctx context.Context, id string) (*types.Token, error) {
+	var token *types.Token
+	if err := s.coll.FindOneAndDelete(ctx, bson.M{mongoIDField: id}).Decode(&token); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
+		return nil, err
+	}
+	return token, nil
+}
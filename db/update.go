@@ -1,9 +1,11 @@
 package db
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/ficontini/gotasks/types"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -26,6 +28,103 @@ func (u StatusUpdater) ToExpression() expression.UpdateBuilder {
 	return expression.Set(expression.Name(enabledField), expression.Value(u.Enabled))
 }
 
+type VerifiedUpdater struct {
+	Verified bool
+}
+
+func (u VerifiedUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set": bson.M{verifiedField: u.Verified},
+	}, nil
+}
+func (u VerifiedUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(verifiedField), expression.Value(u.Verified))
+}
+
+// TwoFactorUpdater replaces the TOTP state of the user. Its zero value turns two-factor
+// authentication off.
+type TwoFactorUpdater struct {
+	Secret        string
+	Enabled       bool
+	LastStep      int64
+	RecoveryCodes []string
+}
+
+func (u TwoFactorUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set": bson.M{
+			totpSecretField:    u.Secret,
+			totpEnabledField:   u.Enabled,
+			totpLastStepField:  u.LastStep,
+			recoveryCodesField: u.RecoveryCodes,
+		},
+	}, nil
+}
+func (u TwoFactorUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(totpSecretField), expression.Value(u.Secret)).
+		Set(expression.Name(totpEnabledField), expression.Value(u.Enabled)).
+		Set(expression.Name(totpLastStepField), expression.Value(u.LastStep)).
+		Set(expression.Name(recoveryCodesField), expression.Value(u.RecoveryCodes))
+}
+
+type TOTPStepUpdater struct {
+	LastStep int64
+}
+
+func (u TOTPStepUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set": bson.M{totpLastStepField: u.LastStep},
+	}, nil
+}
+func (u TOTPStepUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(totpLastStepField), expression.Value(u.LastStep))
+}
+
+type RecoveryCodesUpdater struct {
+	RecoveryCodes []string
+}
+
+func (u RecoveryCodesUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set": bson.M{recoveryCodesField: u.RecoveryCodes},
+	}, nil
+}
+func (u RecoveryCodesUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(recoveryCodesField), expression.Value(u.RecoveryCodes))
+}
+
+// SSOLinkUpdater links the user to its identity provider subject. The provider
+// verified the email, so the user is marked verified too.
+type SSOLinkUpdater struct {
+	Subject string
+}
+
+func (u SSOLinkUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set": bson.M{
+			ssoSubjectField: u.Subject,
+			verifiedField:   true,
+		},
+	}, nil
+}
+func (u SSOLinkUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(ssoSubjectField), expression.Value(u.Subject)).
+		Set(expression.Name(verifiedField), expression.Value(true))
+}
+
+type RolesUpdater struct {
+	Roles []types.Role
+}
+
+func (u RolesUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set": bson.M{rolesField: u.Roles},
+	}, nil
+}
+func (u RolesUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(rolesField), expression.Value(u.Roles))
+}
+
 type PasswordUpdater struct {
 	EncryptedPassword string
 }
@@ -39,6 +138,42 @@ func (u PasswordUpdater) ToExpression() expression.UpdateBuilder {
 	return expression.Set(expression.Name(encryptedPasswordField), expression.Value(u.EncryptedPassword))
 }
 
+// UserProfileUpdater marks the user unverified when the email changes.
+type UserProfileUpdater struct {
+	FirstName string
+	LastName  string
+	Email     string
+}
+
+func (u UserProfileUpdater) ToBSON() (bson.M, error) {
+	fields := bson.M{}
+	if len(u.FirstName) > 0 {
+		fields[firstNameField] = u.FirstName
+	}
+	if len(u.LastName) > 0 {
+		fields[lastNameField] = u.LastName
+	}
+	if len(u.Email) > 0 {
+		fields[emailField] = u.Email
+		fields[verifiedField] = false
+	}
+	return bson.M{"$set": fields}, nil
+}
+func (u UserProfileUpdater) ToExpression() expression.UpdateBuilder {
+	update := expression.UpdateBuilder{}
+	if len(u.FirstName) > 0 {
+		update = update.Set(expression.Name(firstNameField), expression.Value(u.FirstName))
+	}
+	if len(u.LastName) > 0 {
+		update = update.Set(expression.Name(lastNameField), expression.Value(u.LastName))
+	}
+	if len(u.Email) > 0 {
+		update = update.Set(expression.Name(emailField), expression.Value(u.Email)).
+			Set(expression.Name(verifiedField), expression.Value(false))
+	}
+	return update
+}
+
 type TaskDueDateUpdater struct {
 	DueDate time.Time
 }
@@ -53,16 +188,27 @@ func (u TaskDueDateUpdater) ToExpression() expression.UpdateBuilder {
 }
 
 type TaskCompleteUpdater struct {
-	Completed bool
+	Completed   bool
+	CompletedAt time.Time
 }
 
 func (u TaskCompleteUpdater) ToBSON() (bson.M, error) {
+	if !u.Completed {
+		return bson.M{
+			"$set":   bson.M{completedField: u.Completed},
+			"$unset": bson.M{completedAtField: ""},
+		}, nil
+	}
 	return bson.M{
-		"$set": bson.M{completedField: u.Completed},
+		"$set": bson.M{completedField: u.Completed, completedAtField: u.CompletedAt},
 	}, nil
 }
 func (u TaskCompleteUpdater) ToExpression() expression.UpdateBuilder {
-	return expression.Set(expression.Name(completedField), expression.Value(u.Completed))
+	update := expression.Set(expression.Name(completedField), expression.Value(u.Completed))
+	if !u.Completed {
+		return update.Remove(expression.Name(completedAtField))
+	}
+	return update.Set(expression.Name(completedAtField), expression.Value(u.CompletedAt))
 }
 
 type TaskAssignationUpdater struct {
@@ -82,6 +228,15 @@ func (u TaskAssignationUpdater) ToExpression() expression.UpdateBuilder {
 	return expression.Set(expression.Name(assignedToField), expression.Value(u.AssignedTo))
 }
 
+type TaskUnassignUpdater struct{}
+
+func (u TaskUnassignUpdater) ToBSON() (bson.M, error) {
+	return bson.M{"$unset": bson.M{assignedToField: ""}}, nil
+}
+func (u TaskUnassignUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Remove(expression.Name(assignedToField))
+}
+
 type AddTaskToProjectUpdater struct {
 	TaskID string
 }
@@ -99,6 +254,36 @@ func (u AddTaskToProjectUpdater) ToExpression() expression.UpdateBuilder {
 	return expression.Set(expression.Name(tasksField), expression.ListAppend(expression.Name(tasksField), expression.Value([]string{u.TaskID})))
 }
 
+// ConditionalUpdate is implemented by updates that must only be applied
+// when the condition holds at write time.
+type ConditionalUpdate interface {
+	Condition() expression.ConditionBuilder
+}
+
+type RemoveTaskFromProjectUpdater struct {
+	TaskID string
+	Index  int
+}
+
+func (u RemoveTaskFromProjectUpdater) ToBSON() (bson.M, error) {
+	values := []interface{}{u.TaskID}
+	if oid, err := primitive.ObjectIDFromHex(u.TaskID); err == nil {
+		values = append(values, oid)
+	}
+	return bson.M{
+		"$pull": bson.M{tasksField: bson.M{"$in": values}},
+	}, nil
+}
+func (u RemoveTaskFromProjectUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Remove(expression.Name(u.taskPath()))
+}
+func (u RemoveTaskFromProjectUpdater) Condition() expression.ConditionBuilder {
+	return expression.Equal(expression.Name(u.taskPath()), expression.Value(u.TaskID))
+}
+func (u RemoveTaskFromProjectUpdater) taskPath() string {
+	return fmt.Sprintf("%s[%d]", tasksField, u.Index)
+}
+
 type TaskProjectIDUpdater struct {
 	ProjectID string
 }
@@ -108,8 +293,257 @@ func (u TaskProjectIDUpdater) ToBSON() (bson.M, error) {
 	if err != nil {
 		return nil, err
 	}
-	return bson.M{"$set": bson.M{projectIDField: oid}}, nil
+	return bson.M{
+		"$set":   bson.M{projectIDField: oid},
+		"$unset": bson.M{milestoneIDField: ""},
+	}, nil
 }
+
+// Milestones belong to a single project, so changing the project drops the milestone.
 func (u TaskProjectIDUpdater) ToExpression() expression.UpdateBuilder {
-	return expression.Set(expression.Name(projectIDField), expression.Value(u.ProjectID))
+	return expression.Set(expression.Name(projectIDField), expression.Value(u.ProjectID)).
+		Remove(expression.Name(milestoneIDField))
+}
+
+type ProjectMemberUpdater struct {
+	UserID string
+	Role   types.ProjectRole
+}
+
+func (u ProjectMemberUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set": bson.M{memberField(u.UserID): u.Role},
+	}, nil
+}
+func (u ProjectMemberUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(memberField(u.UserID)), expression.Value(u.Role))
+}
+
+type ProjectMembersUpdater struct {
+	Members map[string]types.ProjectRole
+}
+
+func (u ProjectMembersUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set": bson.M{membersField: u.Members},
+	}, nil
+}
+func (u ProjectMembersUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(membersField), expression.Value(u.Members))
+}
+
+type ProjectMemberRemover struct {
+	UserID string
+}
+
+func (u ProjectMemberRemover) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$unset": bson.M{memberField(u.UserID): ""},
+	}, nil
+}
+func (u ProjectMemberRemover) ToExpression() expression.UpdateBuilder {
+	return expression.Remove(expression.Name(memberField(u.UserID)))
+}
+
+// ProjectOwnerUpdater hands the project over to a new owner and drops the previous one.
+type ProjectOwnerUpdater struct {
+	UserID         string
+	PreviousUserID string
+}
+
+func (u ProjectOwnerUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set":   bson.M{userIDField: u.UserID, memberField(u.UserID): types.ProjectRoleOwner},
+		"$unset": bson.M{memberField(u.PreviousUserID): ""},
+	}, nil
+}
+func (u ProjectOwnerUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(userIDField), expression.Value(u.UserID)).
+		Set(expression.Name(memberField(u.UserID)), expression.Value(types.ProjectRoleOwner)).
+		Remove(expression.Name(memberField(u.PreviousUserID)))
+}
+
+func memberField(userID string) string {
+	return fmt.Sprintf("%s.%s", membersField, userID)
+}
+
+type ProjectUpdater struct {
+	Name        string
+	Description string
+}
+
+func (u ProjectUpdater) ToBSON() (bson.M, error) {
+	fields := bson.M{}
+	if len(u.Name) > 0 {
+		fields[nameField] = u.Name
+	}
+	if len(u.Description) > 0 {
+		fields[descriptionField] = u.Description
+	}
+	return bson.M{"$set": fields}, nil
+}
+func (u ProjectUpdater) ToExpression() expression.UpdateBuilder {
+	update := expression.UpdateBuilder{}
+	if len(u.Name) > 0 {
+		update = update.Set(expression.Name(nameField), expression.Value(u.Name))
+	}
+	if len(u.Description) > 0 {
+		update = update.Set(expression.Name(descriptionField), expression.Value(u.Description))
+	}
+	return update
+}
+
+type ProjectArchiveUpdater struct {
+	Archived bool
+}
+
+func (u ProjectArchiveUpdater) ToBSON() (bson.M, error) {
+	return bson.M{
+		"$set": bson.M{archivedField: u.Archived},
+	}, nil
+}
+func (u ProjectArchiveUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(archivedField), expression.Value(u.Archived))
+}
+
+type TaskProjectIDRemover struct{}
+
+func (u TaskProjectIDRemover) ToBSON() (bson.M, error) {
+	return bson.M{"$unset": bson.M{projectIDField: "", milestoneIDField: ""}}, nil
+}
+func (u TaskProjectIDRemover) ToExpression() expression.UpdateBuilder {
+	return expression.Remove(expression.Name(projectIDField)).
+		Remove(expression.Name(milestoneIDField))
+}
+
+type TaskMilestoneUpdater struct {
+	MilestoneID string
+}
+
+func (u TaskMilestoneUpdater) ToBSON() (bson.M, error) {
+	return bson.M{"$set": bson.M{milestoneIDField: u.MilestoneID}}, nil
+}
+func (u TaskMilestoneUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(milestoneIDField), expression.Value(u.MilestoneID))
+}
+
+type TaskMilestoneRemover struct{}
+
+func (u TaskMilestoneRemover) ToBSON() (bson.M, error) {
+	return bson.M{"$unset": bson.M{milestoneIDField: ""}}, nil
+}
+func (u TaskMilestoneRemover) ToExpression() expression.UpdateBuilder {
+	return expression.Remove(expression.Name(milestoneIDField))
+}
+
+type MilestoneUpdater struct {
+	Name        string
+	Description string
+	TargetDate  time.Time
+}
+
+func (u MilestoneUpdater) ToBSON() (bson.M, error) {
+	fields := bson.M{}
+	if len(u.Name) > 0 {
+		fields[nameField] = u.Name
+	}
+	if len(u.Description) > 0 {
+		fields[descriptionField] = u.Description
+	}
+	if !u.TargetDate.IsZero() {
+		fields[targetDateField] = u.TargetDate
+	}
+	return bson.M{"$set": fields}, nil
+}
+func (u MilestoneUpdater) ToExpression() expression.UpdateBuilder {
+	update := expression.UpdateBuilder{}
+	if len(u.Name) > 0 {
+		update = update.Set(expression.Name(nameField), expression.Value(u.Name))
+	}
+	if len(u.Description) > 0 {
+		update = update.Set(expression.Name(descriptionField), expression.Value(u.Description))
+	}
+	if !u.TargetDate.IsZero() {
+		update = update.Set(expression.Name(targetDateField), expression.Value(u.TargetDate))
+	}
+	return update
+}
+
+// TaskTeamUpdater hands the task over to a team, unassigning it until one of the
+// team members picks it up.
+type TaskTeamUpdater struct {
+	TeamID string
+}
+
+func (u TaskTeamUpdater) ToBSON() (bson.M, error) {
+	return bson.M{"$set": bson.M{teamIDField: u.TeamID, assignedToField: ""}}, nil
+}
+func (u TaskTeamUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(teamIDField), expression.Value(u.TeamID)).
+		Set(expression.Name(assignedToField), expression.Value(""))
+}
+
+type TaskTeamRemover struct{}
+
+func (u TaskTeamRemover) ToBSON() (bson.M, error) {
+	return bson.M{"$unset": bson.M{teamIDField: ""}}, nil
+}
+func (u TaskTeamRemover) ToExpression() expression.UpdateBuilder {
+	return expression.Remove(expression.Name(teamIDField))
+}
+
+type TeamUpdater struct {
+	Name        string
+	Description string
+	LeadID      string
+}
+
+func (u TeamUpdater) ToBSON() (bson.M, error) {
+	fields := bson.M{}
+	if len(u.Name) > 0 {
+		fields[nameField] = u.Name
+	}
+	if len(u.Description) > 0 {
+		fields[descriptionField] = u.Description
+	}
+	if len(u.LeadID) > 0 {
+		fields[leadIDField] = u.LeadID
+	}
+	return bson.M{"$set": fields}, nil
+}
+func (u TeamUpdater) ToExpression() expression.UpdateBuilder {
+	update := expression.UpdateBuilder{}
+	if len(u.Name) > 0 {
+		update = update.Set(expression.Name(nameField), expression.Value(u.Name))
+	}
+	if len(u.Description) > 0 {
+		update = update.Set(expression.Name(descriptionField), expression.Value(u.Description))
+	}
+	if len(u.LeadID) > 0 {
+		update = update.Set(expression.Name(leadIDField), expression.Value(u.LeadID))
+	}
+	return update
+}
+
+// TeamMembersUpdater replaces the members of the team.
+type TeamMembersUpdater struct {
+	Members []string
+}
+
+func (u TeamMembersUpdater) ToBSON() (bson.M, error) {
+	return bson.M{"$set": bson.M{membersField: u.Members}}, nil
+}
+func (u TeamMembersUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(membersField), expression.Value(u.Members))
+}
+
+type WorkspaceUpdater struct {
+	Name string
+}
+
+func (u WorkspaceUpdater) ToBSON() (bson.M, error) {
+	return bson.M{"$set": bson.M{nameField: u.Name}}, nil
+}
+func (u WorkspaceUpdater) ToExpression() expression.UpdateBuilder {
+	return expression.Set(expression.Name(nameField), expression.Value(u.Name))
 }
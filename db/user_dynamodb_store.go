@@ -30,6 +30,7 @@ func NewDynamoDBUserStore(client *dynamodb.Client) *DynamoDBUserStore {
 	}
 }
 func (s *DynamoDBUserStore) InsertUser(ctx context.Context, user *types.User) (*types.User, error) {
+	scopeItem(ctx, user)
 	user.ID = uuid.New().String()
 	item, err := attributevalue.MarshalMap(user)
 	if err != nil {
@@ -62,6 +63,9 @@ func (s *DynamoDBUserStore) GetUserByID(ctx context.Context, idStr string) (*typ
 	if err := attributevalue.UnmarshalMap(res.Item, &user); err != nil {
 		return nil, ErrorNotFound
 	}
+	if !inWorkspace(ctx, user.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
 	return user, nil
 }
 func (s *DynamoDBUserStore) GetUserByEmail(ctx context.Context, email string) (*types.User, error) {
@@ -87,12 +91,15 @@ func (s *DynamoDBUserStore) GetUserByEmail(ctx context.Context, email string) (*
 	if err := attributevalue.UnmarshalMap(queryOutput.Items[0], &user); err != nil {
 		return nil, ErrorNotFound
 	}
+	if !inWorkspace(ctx, user.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
 	return user, nil
 }
 
 // TODO: Review
 func (s *DynamoDBUserStore) GetUsers(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.User, error) {
-	expr, err := filter.ToExpression()
+	expr, err := scopeFilter(ctx, filter).ToExpression()
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +110,7 @@ func (s *DynamoDBUserStore) GetUsers(ctx context.Context, filter Filter, paginat
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
 		Limit:                     aws.Int32(int32(pagination.Limit)),
 	}
 	opts := NewDynamoDBQueryOptions(queryInput, pagination)
@@ -123,27 +131,30 @@ func (s *DynamoDBUserStore) GetUsers(ctx context.Context, filter Filter, paginat
 }
 
 func (s *DynamoDBUserStore) Update(ctx context.Context, idStr string, params Update) error {
-	key, err := GetKey(idStr)
+	input, err := newUpdateItemInput(ctx, s.table, idStr, params)
 	if err != nil {
 		return err
 	}
-	update := params.ToExpression()
-	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	input.ReturnValues = dynamodbtypes.ReturnValueUpdatedNew
+	res, err := s.client.UpdateItem(ctx, input)
 	if err != nil {
-		return err
+		return notFoundIfConditionFailed(err)
 	}
-	res, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
-		TableName:                 s.table,
-		Key:                       key,
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		UpdateExpression:          expr.Update(),
-		ReturnValues:              dynamodbtypes.ReturnValueUpdatedNew,
-	})
+	//TODO: Review
+	if len(res.Attributes) == 0 {
+		return ErrorNotFound
+	}
+	return nil
+}
+func (s *DynamoDBUserStore) Delete(ctx context.Context, id string) error {
+	input, err := newDeleteItemInput(ctx, s.table, id)
 	if err != nil {
 		return err
 	}
-	//TODO: Review
+	res, err := s.client.DeleteItem(ctx, input)
+	if err != nil {
+		return notFoundIfConditionFailed(err)
+	}
 	if len(res.Attributes) == 0 {
 		return ErrorNotFound
 	}
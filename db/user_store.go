@@ -28,6 +28,7 @@ type UserStore interface {
 	UserGetter
 	UserInserter
 	UserUpdater
+	Deleter
 	Dropper
 }
 
@@ -51,6 +52,9 @@ func (s *MongoUserStore) GetUserByEmail(ctx context.Context, email string) (*typ
 		}
 		return nil, err
 	}
+	if !inWorkspace(ctx, user.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
 	return user, nil
 }
 func (s *MongoUserStore) GetUserByID(ctx context.Context, id string) (*types.User, error) {
@@ -65,9 +69,13 @@ func (s *MongoUserStore) GetUserByID(ctx context.Context, id string) (*types.Use
 		}
 		return nil, err
 	}
+	if !inWorkspace(ctx, user.WorkspaceID) {
+		return nil, ErrorNotFound
+	}
 	return user, nil
 }
 func (s *MongoUserStore) InsertUser(ctx context.Context, user *types.User) (*types.User, error) {
+	scopeItem(ctx, user)
 	res, err := s.coll.InsertOne(ctx, user)
 	if err != nil {
 		return nil, err
@@ -76,26 +84,14 @@ func (s *MongoUserStore) InsertUser(ctx context.Context, user *types.User) (*typ
 	return user, nil
 }
 func (s *MongoUserStore) Update(ctx context.Context, id string, params Update) error {
-	oid, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
-	}
-	update, err := params.ToBSON()
-	if err != nil {
-		return err
-	}
-	res, err := s.coll.UpdateByID(ctx, oid, update)
-	if err != nil {
-		return err
-	}
-	if res.ModifiedCount == 0 {
-		return ErrorNotFound
-	}
-	return nil
+	return updateByID(ctx, s.coll, id, params)
+}
+func (s *MongoUserStore) Delete(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.coll, id)
 }
 func (s *MongoUserStore) GetUsers(ctx context.Context, filter Filter, pagination *Pagination) ([]*types.User, error) {
 	opts := pagination.getOptions()
-	cur, err := s.coll.Find(ctx, filter.ToBSON(), opts)
+	cur, err := s.coll.Find(ctx, scopeFilter(ctx, filter).ToBSON(), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type contextKey string
+
+// WorkspaceContextKey holds the ID of the workspace a request runs in. Stores restrict
+// their queries and new items to it, and leave them unrestricted when the context has
+// none, as when signing users in before their workspace is known.
+const WorkspaceContextKey contextKey = "workspaceID"
+
+func WithWorkspace(ctx context.Context, workspaceID string) context.Context {
+	return context.WithValue(ctx, WorkspaceContextKey, workspaceID)
+}
+
+// WithoutWorkspace lifts the restriction of the context, for lookups that span every
+// workspace such as checking that an email is not registered yet.
+func WithoutWorkspace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, WorkspaceContextKey, nil)
+}
+func workspaceFromContext(ctx context.Context) (string, bool) {
+	workspaceID, ok := ctx.Value(WorkspaceContextKey).(string)
+	return workspaceID, ok
+}
+func scopeFilter(ctx context.Context, filter Filter) Filter {
+	if workspaceID, ok := workspaceFromContext(ctx); ok {
+		return filter.InWorkspace(workspaceID)
+	}
+	return filter
+}
+func scopeItem(ctx context.Context, item interface{}) {
+	scoped, ok := item.(types.WorkspaceScoped)
+	if !ok {
+		return
+	}
+	if workspaceID, ok := workspaceFromContext(ctx); ok {
+		scoped.SetWorkspace(workspaceID)
+	}
+}
+
+// inWorkspace tells whether an item fetched by its ID belongs to the workspace of the
+// context. Items of other workspaces are reported as not found.
+func inWorkspace(ctx context.Context, workspaceID string) bool {
+	id, ok := workspaceFromContext(ctx)
+	return !ok || id == workspaceID
+}
+
+// scopeIDFilter matches the item with the ID, if it belongs to the workspace of the
+// context, so updates and deletes by ID can't reach items of other workspaces.
+func scopeIDFilter(ctx context.Context, id interface{}) bson.M {
+	filter := bson.M{mongoIDField: id}
+	if workspaceID, ok := workspaceFromContext(ctx); ok {
+		return bson.M{"$and": bson.A{filter, NewWorkspaceFieldFilterer(workspaceID).GetBSONFilter()}}
+	}
+	return filter
+}
+
+// scopeCondition is the DynamoDB counterpart of scopeIDFilter. Writes by key create
+// missing items, so the item also has to exist.
+func scopeCondition(ctx context.Context) *expression.ConditionBuilder {
+	workspaceID, ok := workspaceFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	condition := expression.AttributeExists(expression.Name(dynamoIDField)).And(NewWorkspaceFieldFilterer(workspaceID).GetFilter())
+	return &condition
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ficontini/gotasks/types"
+	"github.com/google/uuid"
+)
+
+type DynamoDBWorkspaceStore struct {
+	client *dynamodb.Client
+	table  *string
+}
+
+func NewDynamoDBWorkspaceStore(client *dynamodb.Client) *DynamoDBWorkspaceStore {
+	return &DynamoDBWorkspaceStore{
+		client: client,
+		table:  aws.String(workspaceColl),
+	}
+}
+func (s *DynamoDBWorkspaceStore) InsertWorkspace(ctx context.Context, workspace *types.Workspace) (*types.Workspace, error) {
+	workspace.ID = uuid.New().String()
+	item, err := attributevalue.MarshalMap(workspace)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: s.table, Item: item,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+func (s *DynamoDBWorkspaceStore) GetWorkspaceByID(ctx context.Context, id string) (*types.Workspace, error) {
+	key, err := GetKey(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: s.table,
+		Key:       key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Item == nil {
+		return nil, ErrorNotFound
+	}
+	var workspace *types.Workspace
+	if err := attributevalue.UnmarshalMap(res.Item, &workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+func (s *DynamoDBWorkspaceStore) Update(ctx context.Context, id string, params Update) error {
+	key, err := GetKey(id)
+	if err != nil {
+		return err
+	}
+	expr, err := expression.NewBuilder().WithUpdate(params.ToExpression()).Build()
+	if err != nil {
+		return err
+	}
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 s.table,
+		Key:                       key,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+	})
+	return err
+}
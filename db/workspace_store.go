@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ficontini/gotasks/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const workspaceColl = "workspaces"
+
+type WorkspaceStore interface {
+	InsertWorkspace(context.Context, *types.Workspace) (*types.Workspace, error)
+	GetWorkspaceByID(context.Context, string) (*types.Workspace, error)
+	Update(context.Context, string, Update) error
+}
+
+type MongoWorkspaceStore struct {
+	client *mongo.Client
+	coll   *mongo.Collection
+}
+
+func NewMongoWorkspaceStore(client *mongo.Client) *MongoWorkspaceStore {
+	return &MongoWorkspaceStore{
+		client: client,
+		coll:   client.Database(DBNAME).Collection(workspaceColl),
+	}
+}
+func (s *MongoWorkspaceStore) InsertWorkspace(ctx context.Context, workspace *types.Workspace) (*types.Workspace, error) {
+	res, err := s.coll.InsertOne(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+	workspace.ID = res.InsertedID.(primitive.ObjectID).Hex()
+	return workspace, nil
+}
+func (s *MongoWorkspaceStore) GetWorkspaceByID(ctx context.Context, id string) (*types.Workspace, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	var workspace *types.Workspace
+	if err := s.coll.FindOne(ctx, bson.M{mongoIDField: oid}).Decode(&workspace); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrorNotFound
+		}
+		return nil, err
+	}
+	return workspace, nil
+}
+func (s *MongoWorkspaceStore) Update(ctx context.Context, id string, params Update) error {
+	// Workspaces are not stored in a workspace themselves.
+	return updateByID(WithoutWorkspace(ctx), s.coll, id, params)
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// LogMailer writes messages to w instead of delivering them.
+type LogMailer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLogMailer(w io.Writer) *LogMailer {
+	return &LogMailer{
+		w: w,
+	}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := fmt.Fprintf(m.w, "--- %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().UTC().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+const (
+	SMTPHostEnvName     = "SMTP_HOST"
+	SMTPPortEnvName     = "SMTP_PORT"
+	SMTPUsernameEnvName = "SMTP_USERNAME"
+	SMTPPasswordEnvName = "SMTP_PASSWORD"
+	MailFromEnvName     = "MAIL_FROM"
+	MailFileEnvName     = "MAIL_FILE"
+	defaultSMTPPort     = "587"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(context.Context, Message) error
+}
+
+// NewMailerFromEnv returns an SMTP mailer when SMTP_HOST is set. Otherwise messages
+// are written to MAIL_FILE, or to stdout when it is not set either, for local use.
+func NewMailerFromEnv() (Mailer, error) {
+	host := os.Getenv(SMTPHostEnvName)
+	if host == "" {
+		return newLogMailerFromEnv()
+	}
+	from := os.Getenv(MailFromEnvName)
+	if from == "" {
+		return nil, fmt.Errorf("%s env variable not set", MailFromEnvName)
+	}
+	port := os.Getenv(SMTPPortEnvName)
+	if port == "" {
+		port = defaultSMTPPort
+	}
+	return NewSMTPMailer(host, port, os.Getenv(SMTPUsernameEnvName), os.Getenv(SMTPPasswordEnvName), from), nil
+}
+func newLogMailerFromEnv() (Mailer, error) {
+	path := os.Getenv(MailFileEnvName)
+	if path == "" {
+		return NewLogMailer(os.Stdout), nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogMailer(f), nil
+}
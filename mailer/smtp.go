@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if len(username) > 0 {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, port),
+		from: from,
+		auth: auth,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", m.from)
+	fmt.Fprintf(&body, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	body.WriteString(msg.Body)
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, body.Bytes())
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"github.com/ficontini/gotasks/api"
 	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/oidc"
+	"github.com/ficontini/gotasks/service"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/joho/godotenv"
@@ -31,15 +35,36 @@ func main() {
 	var (
 		app = fiber.New(config)
 	)
+	if err := service.SetupAuthConfigFromEnv(); err != nil {
+		log.Fatal(err)
+	}
+	if err := service.SetupPasswordPolicyFromEnv(); err != nil {
+		log.Fatal(err)
+	}
 	cfg, err := db.NewConfig()
 	if err != nil {
 		log.Fatal(err)
 	}
+	mailer, err := mailer.NewMailerFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	provider, err := newOIDCProviderFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
 	app.Use(logger.New(loggerConfig))
-	MakeRoutes(app, cfg.Store)
+	MakeRoutes(app, cfg.Store, mailer, provider)
 	listenAddr := os.Getenv("HTTP_LISTEN_ADDRESS")
 	log.Fatal(app.Listen(listenAddr))
 }
+func newOIDCProviderFromEnv() (*oidc.Provider, error) {
+	config, err := oidc.NewConfigFromEnv()
+	if err != nil || config == nil {
+		return nil, err
+	}
+	return oidc.NewProvider(context.Background(), *config)
+}
 func init() {
 	if err := godotenv.Load(); err != nil {
 		log.Fatal(err)
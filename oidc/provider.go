@@ -0,0 +1,231 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ficontini/gotasks/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	IssuerEnvName       = "OIDC_ISSUER"
+	ClientIDEnvName     = "OIDC_CLIENT_ID"
+	ClientSecretEnvName = "OIDC_CLIENT_SECRET"
+	RedirectURLEnvName  = "OIDC_REDIRECT_URL"
+	AutoCreateEnvName   = "OIDC_AUTO_CREATE"
+	discoveryPath       = "/.well-known/openid-configuration"
+	requestTimeout      = 10 * time.Second
+)
+
+var ErrInvalidIDToken = errors.New("id token is invalid")
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// AutoCreate allows signing in users that don't have an account yet.
+	AutoCreate bool
+}
+
+// NewConfigFromEnv returns nil when OIDC_ISSUER is not set, as single sign-on is
+// optional.
+func NewConfigFromEnv() (*Config, error) {
+	issuer := os.Getenv(IssuerEnvName)
+	if issuer == "" {
+		return nil, nil
+	}
+	config := &Config{
+		Issuer:       issuer,
+		ClientID:     os.Getenv(ClientIDEnvName),
+		ClientSecret: os.Getenv(ClientSecretEnvName),
+		RedirectURL:  os.Getenv(RedirectURLEnvName),
+		AutoCreate:   os.Getenv(AutoCreateEnvName) == "true",
+	}
+	for name, value := range map[string]string{
+		ClientIDEnvName:    config.ClientID,
+		RedirectURLEnvName: config.RedirectURL,
+	} {
+		if value == "" {
+			return nil, fmt.Errorf("%s env variable not set", name)
+		}
+	}
+	return config, nil
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider signs users in with the authorization code flow of an OpenID Connect
+// identity provider.
+type Provider struct {
+	config   Config
+	client   *http.Client
+	metadata metadata
+
+	mu   sync.Mutex
+	keys map[string]crypto.PublicKey
+}
+
+// NewProvider discovers the endpoints of the issuer.
+func NewProvider(ctx context.Context, config Config) (*Provider, error) {
+	p := &Provider{
+		config: config,
+		client: &http.Client{Timeout: requestTimeout},
+		keys:   map[string]crypto.PublicKey{},
+	}
+	if err := p.getJSON(ctx, strings.TrimSuffix(config.Issuer, "/")+discoveryPath, &p.metadata); err != nil {
+		return nil, fmt.Errorf("failed to discover the OIDC provider: %w", err)
+	}
+	if p.metadata.Issuer != config.Issuer {
+		return nil, fmt.Errorf("OIDC provider issuer %s does not match %s", p.metadata.Issuer, config.Issuer)
+	}
+	return p, nil
+}
+
+func (p *Provider) Config() Config {
+	return p.config
+}
+
+// AuthCodeURL returns where to send the user to sign in, with the S256 challenge of
+// the PKCE verifier.
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", "openid email profile")
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	separator := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// Exchange redeems the authorization code and returns the claims of the validated
+// ID token.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", verifier)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if len(p.config.ClientSecret) > 0 {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OIDC token endpoint responded with status %d", resp.StatusCode)
+	}
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+	return p.Verify(ctx, token.IDToken, nonce)
+}
+
+// Claims are the ID token claims used to find or create the user.
+type Claims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// Verify checks the signature, issuer, audience, expiration and nonce of the ID token.
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
+
+// getKey fetches the provider keys again when the kid is unknown, as the provider
+// may have rotated them.
+func (p *Provider) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	var jwks types.JWKS
+	if err := p.getJSON(ctx, p.metadata.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", kid)
+	}
+	return key, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s responded with status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -3,42 +3,141 @@ package main
 import (
 	"github.com/ficontini/gotasks/api"
 	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/oidc"
 	"github.com/ficontini/gotasks/service"
+	"github.com/ficontini/gotasks/types"
 	"github.com/gofiber/fiber/v2"
 )
 
-func MakeRoutes(app *fiber.App, store *db.Store) {
+func MakeRoutes(app *fiber.App, store *db.Store, mailer mailer.Mailer, provider *oidc.Provider) {
 	var (
-		svc     = service.NewService(store)
+		svc     = service.NewService(store, mailer, provider)
 		handler = api.NewHandler(svc)
 		auth    = app.Group("/api")
 		apiv1   = app.Group("/api/v1", api.JWTAuthentication(svc.Auth))
-		admin   = apiv1.Group("/admin", api.AdminAuth)
+		admin   = apiv1.Group("/admin")
+
+		tasksRead      = api.RequireScope(types.ScopeTasksRead)
+		tasksWrite     = api.RequireScope(types.ScopeTasksWrite)
+		projectsRead   = api.RequireScope(types.ScopeProjectsRead)
+		projectsWrite  = api.RequireScope(types.ScopeProjectsWrite)
+		templatesRead  = api.RequireScope(types.ScopeTemplatesRead)
+		templatesWrite = api.RequireScope(types.ScopeTemplatesWrite)
+		userRead       = api.RequireScope(types.ScopeUserRead)
+		ownSession     = api.DenyImpersonation
+
+		can = func(permission types.Permission) fiber.Handler {
+			return api.RequirePermission(svc.Policy, permission)
+		}
 	)
 
+	app.Get("/.well-known/jwks.json", handler.Auth.HandleGetJWKS)
 	auth.Post("/auth", handler.Auth.HandleAuthenticate)
+	auth.Post("/auth/refresh", handler.Auth.HandleRefresh)
+	auth.Post("/auth/2fa", handler.Auth.HandleAuthenticateTwoFactor)
+	auth.Get("/auth/sso/login", handler.SSO.HandleSSOLogin)
+	auth.Get("/auth/sso/callback", handler.SSO.HandleSSOCallback)
+	apiv1.Post("/auth/logout", ownSession, handler.User.HandleLogout)
 
 	auth.Post("/user", handler.User.HandlePostUser)
-	apiv1.Post("/user/reset-password", handler.User.HandleResetPassword)
-	apiv1.Get("/user", handler.User.HandleGetUser)
-
-	apiv1.Get("/task/all", handler.Task.HandleGetTasks)
-	apiv1.Get("/task", handler.Task.HandleGetUserTasks)
-	apiv1.Post("/task", handler.Task.HandlePostTask)
-	apiv1.Get("/task/:id", handler.Task.HandleGetTask)
-	apiv1.Post("/task/:id/assign", handler.Task.HandleAssignTaskToSelf)
-	apiv1.Post("/task/:id/complete", handler.Task.HandleCompleteTask)
-	apiv1.Put("/task/:id/due-date", handler.Task.HandlePutDueDateTask)
-	admin.Post("/task/:id/assign", handler.Task.HandleAssignTaskToUser)
-	admin.Delete("/task/:id", handler.Task.HandleDeleteTask)
-	admin.Get("/task", handler.Task.HandleGetTasks)
-
-	admin.Get("/user", handler.User.HandleGetUsers)
-	admin.Get("/user/:id", handler.User.HandleAdminGetUser)
-	admin.Put("/user/:id/enable", handler.User.HandleEnableUser)
-	admin.Put("/user/:id/disable", handler.User.HandleDisableUser)
-
-	apiv1.Post("/project", handler.Project.HandlePostProject)
-	apiv1.Get("/project/:id", handler.Project.HandleGetProject)
-	apiv1.Post("/project/:id/task", handler.Project.HandlePostTask)
+	auth.Get("/user/verify", handler.User.HandleVerifyEmail)
+	auth.Post("/user/verify", handler.User.HandleResendVerification)
+	auth.Post("/user/forgot-password", handler.User.HandleForgotPassword)
+	auth.Post("/user/forgot-password/reset", handler.User.HandleResetForgottenPassword)
+	auth.Post("/user/invitation", handler.Workspace.HandleAcceptInvitation)
+	apiv1.Post("/user/reset-password", ownSession, handler.User.HandleResetPassword)
+	apiv1.Get("/user", userRead, handler.User.HandleGetUser)
+	apiv1.Get("/user/export", handler.User.HandleExportUser)
+	apiv1.Get("/user/sessions", handler.User.HandleGetSessions)
+	apiv1.Delete("/user/sessions/:uuid", ownSession, handler.User.HandleDeleteSession)
+	apiv1.Post("/user/2fa/totp", ownSession, handler.User.HandlePostTOTP)
+	apiv1.Post("/user/2fa/totp/confirm", ownSession, handler.User.HandleConfirmTOTP)
+	apiv1.Delete("/user/2fa", ownSession, handler.User.HandleDeleteTwoFactor)
+	apiv1.Post("/user/tokens", ownSession, handler.User.HandlePostAccessToken)
+	apiv1.Get("/user/tokens", handler.User.HandleGetAccessTokens)
+	apiv1.Delete("/user/tokens/:id", ownSession, handler.User.HandleDeleteAccessToken)
+	apiv1.Put("/user", ownSession, handler.User.HandlePutUser)
+	apiv1.Delete("/user", ownSession, handler.User.HandleDeleteUser)
+
+	apiv1.Get("/task/all", tasksRead, can(types.PermissionProjectReadAny), handler.Task.HandleGetTasks)
+	apiv1.Get("/task", tasksRead, handler.Task.HandleGetUserTasks)
+	apiv1.Get("/task/team", tasksRead, handler.Task.HandleGetTeamTasks)
+	apiv1.Post("/task", tasksWrite, handler.Task.HandlePostTask)
+	apiv1.Get("/task/:id", tasksRead, handler.Task.HandleGetTask)
+	apiv1.Post("/task/:id/assign", tasksWrite, handler.Task.HandleAssignTaskToSelf)
+	apiv1.Post("/task/:id/team", tasksWrite, handler.Task.HandleAssignTaskToTeam)
+	apiv1.Post("/task/:id/complete", tasksWrite, handler.Task.HandleCompleteTask)
+	apiv1.Put("/task/:id/due-date", tasksWrite, handler.Task.HandlePutDueDateTask)
+	apiv1.Post("/task/:id/template", templatesWrite, handler.Template.HandlePostTaskTemplate)
+	apiv1.Post("/task/:id/duplicate", tasksWrite, handler.Task.HandleDuplicateTask)
+	admin.Post("/task/:id/assign", can(types.PermissionTaskAssign), handler.Task.HandleAssignTaskToUser)
+	admin.Delete("/task/:id", can(types.PermissionTaskDelete), handler.Task.HandleDeleteTask)
+	admin.Get("/task", can(types.PermissionTaskReadAny), handler.Task.HandleGetTasks)
+
+	admin.Get("/user", can(types.PermissionUserRead), handler.User.HandleGetUsers)
+	admin.Put("/user/bulk/enable", can(types.PermissionUserEnable), handler.User.HandleBulkEnableUsers)
+	admin.Put("/user/bulk/disable", can(types.PermissionUserDisable), handler.User.HandleBulkDisableUsers)
+	admin.Put("/user/bulk/roles", can(types.PermissionRoleAssign), handler.Role.HandleBulkPutUserRoles)
+	admin.Post("/user/bulk/delete", can(types.PermissionUserDelete), handler.User.HandleBulkDeleteUsers)
+	admin.Get("/user/:id", can(types.PermissionUserRead), handler.User.HandleAdminGetUser)
+	admin.Put("/user/:id/enable", can(types.PermissionUserEnable), handler.User.HandleEnableUser)
+	admin.Put("/user/:id/disable", can(types.PermissionUserDisable), handler.User.HandleDisableUser)
+	admin.Delete("/user/:id/lockout", can(types.PermissionUserUnlock), handler.User.HandleAdminUnlockUser)
+	admin.Post("/user/:id/impersonate", can(types.PermissionUserImpersonate), handler.Auth.HandleImpersonate)
+	admin.Delete("/user/:id", can(types.PermissionUserDelete), handler.User.HandleAdminDeleteUser)
+	admin.Delete("/user/:id/sessions", can(types.PermissionUserSessionRevoke), handler.User.HandleAdminDeleteSessions)
+	admin.Delete("/user/:id/2fa", can(types.PermissionUserTwoFactorReset), handler.User.HandleAdminDeleteTwoFactor)
+	admin.Put("/user/:id/roles", can(types.PermissionRoleAssign), handler.Role.HandlePutUserRoles)
+	admin.Get("/role", can(types.PermissionRoleAssign), handler.Role.HandleGetRoles)
+	admin.Post("/workspace", can(types.PermissionWorkspaceCreate), handler.Workspace.HandlePostWorkspace)
+
+	apiv1.Get("/workspace", handler.Workspace.HandleGetWorkspace)
+	apiv1.Put("/workspace", can(types.PermissionWorkspaceManage), handler.Workspace.HandlePutWorkspace)
+	apiv1.Post("/workspace/invitation", can(types.PermissionUserInvite), handler.Workspace.HandlePostInvitation)
+	apiv1.Get("/workspace/invitation", can(types.PermissionUserInvite), handler.Workspace.HandleGetInvitations)
+	apiv1.Delete("/workspace/invitation/:id", can(types.PermissionUserInvite), handler.Workspace.HandleDeleteInvitation)
+
+	apiv1.Post("/project", projectsWrite, handler.Project.HandlePostProject)
+	apiv1.Get("/project", projectsRead, handler.Project.HandleGetProjects)
+	apiv1.Get("/project/:id", projectsRead, handler.Project.HandleGetProject)
+	apiv1.Get("/project/:id/stats", projectsRead, handler.Project.HandleGetProjectStats)
+	apiv1.Get("/project/:id/task", projectsRead, handler.Project.HandleGetProjectTasks)
+	apiv1.Put("/project/:id", projectsWrite, handler.Project.HandlePutProject)
+	apiv1.Post("/project/:id/clone", projectsWrite, handler.Project.HandleCloneProject)
+	apiv1.Delete("/project/:id", projectsWrite, handler.Project.HandleDeleteProject)
+	apiv1.Post("/project/:id/archive", projectsWrite, handler.Project.HandleArchiveProject)
+	apiv1.Post("/project/:id/unarchive", projectsWrite, handler.Project.HandleUnarchiveProject)
+	apiv1.Post("/project/:id/task", projectsWrite, handler.Project.HandlePostTask)
+	apiv1.Delete("/project/:id/task/:taskID", projectsWrite, handler.Project.HandleDeleteTask)
+	apiv1.Post("/project/:id/task/:taskID/move", projectsWrite, handler.Project.HandleMoveTask)
+	apiv1.Post("/project/:id/member", projectsWrite, handler.Project.HandlePostMember)
+	apiv1.Put("/project/:id/member/:userID", projectsWrite, handler.Project.HandlePutMemberRole)
+	apiv1.Delete("/project/:id/member/:userID", projectsWrite, handler.Project.HandleDeleteMember)
+	apiv1.Post("/project/:id/milestone", projectsWrite, handler.Milestone.HandlePostMilestone)
+	apiv1.Get("/project/:id/milestone", projectsRead, handler.Milestone.HandleGetMilestones)
+
+	apiv1.Post("/project/:id/template", templatesWrite, handler.Template.HandlePostProjectTemplate)
+
+	apiv1.Get("/template", templatesRead, handler.Template.HandleGetTemplates)
+	apiv1.Get("/template/:id", templatesRead, handler.Template.HandleGetTemplate)
+	apiv1.Delete("/template/:id", templatesWrite, handler.Template.HandleDeleteTemplate)
+	apiv1.Post("/template/:id/project", templatesWrite, handler.Template.HandleInstantiateProject)
+	apiv1.Post("/template/:id/task", templatesWrite, handler.Template.HandleInstantiateTask)
+
+	apiv1.Post("/team", handler.Team.HandlePostTeam)
+	apiv1.Get("/team", handler.Team.HandleGetTeams)
+	apiv1.Get("/team/:id", handler.Team.HandleGetTeam)
+	apiv1.Get("/team/:id/task", tasksRead, handler.Team.HandleGetTeamTasks)
+	apiv1.Put("/team/:id", handler.Team.HandlePutTeam)
+	apiv1.Delete("/team/:id", handler.Team.HandleDeleteTeam)
+	apiv1.Post("/team/:id/member", handler.Team.HandlePostMember)
+	apiv1.Delete("/team/:id/member/:userID", handler.Team.HandleDeleteMember)
+
+	apiv1.Get("/milestone/:id", projectsRead, handler.Milestone.HandleGetMilestone)
+	apiv1.Put("/milestone/:id", projectsWrite, handler.Milestone.HandlePutMilestone)
+	apiv1.Delete("/milestone/:id", projectsWrite, handler.Milestone.HandleDeleteMilestone)
+	apiv1.Get("/milestone/:id/progress", projectsRead, handler.Milestone.HandleGetMilestoneProgress)
+	apiv1.Post("/milestone/:id/task", projectsWrite, handler.Milestone.HandlePostTask)
+	apiv1.Delete("/milestone/:id/task/:taskID", projectsWrite, handler.Milestone.HandleDeleteTask)
 }
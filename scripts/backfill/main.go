@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/joho/godotenv"
+)
+
+// backfill fills in the fields added by newer versions to the stored items. Run it once
+// after upgrading, it skips the items that already have them.
+func main() {
+	mongo := flag.Bool("mongo", false, "backfill the MongoDB database instead of DynamoDB")
+	flag.Parse()
+	run, err := newRunner(context.Background(), *mongo)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, backfill := range db.Backfills {
+		updated, err := run(backfill)
+		if err != nil {
+			log.Fatalf("%s: %v", backfill.Name, err)
+		}
+		log.Printf("%s: %d items updated", backfill.Name, updated)
+	}
+}
+func newRunner(ctx context.Context, mongo bool) (func(db.Backfill) (int64, error), error) {
+	if mongo {
+		client, err := db.NewMongoClient()
+		if err != nil {
+			return nil, err
+		}
+		database := client.Database(db.DBNAME)
+		return func(backfill db.Backfill) (int64, error) {
+			if backfill.Mongo == nil {
+				return 0, nil
+			}
+			return backfill.Mongo(ctx, database)
+		}, nil
+	}
+	client, err := db.NewDynamoDBClient()
+	if err != nil {
+		return nil, err
+	}
+	return func(backfill db.Backfill) (int64, error) {
+		if backfill.DynamoDB == nil {
+			return 0, nil
+		}
+		return backfill.DynamoDB(ctx, client)
+	}, nil
+}
+func init() {
+	if err := godotenv.Load(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -18,10 +18,14 @@ func NewAuthLogMiddleware(next AuthServicer) AuthServicer {
 		next: next,
 	}
 }
-func (m *AuthLogMiddleware) AuthenticateUser(ctx context.Context, params *types.AuthParams) (auth *types.Auth, err error) {
+func (m *AuthLogMiddleware) AuthenticateUser(ctx context.Context, params *types.AuthParams) (auth *types.Auth, challenge *types.TwoFactorChallenge, err error) {
 	defer func(start time.Time) {
 		if err != nil {
 			logrus.WithError(err).Error("Failed to authenticate user")
+		} else if challenge != nil {
+			logrus.WithFields(logrus.Fields{
+				"took": time.Since(start),
+			}).Info("AuthenticatedUser requires two-factor authentication")
 		} else {
 			logrus.WithFields(logrus.Fields{
 				"took":   time.Since(start),
@@ -29,7 +33,71 @@ func (m *AuthLogMiddleware) AuthenticateUser(ctx context.Context, params *types.
 			}).Info("AuthenticatedUser successfully completed")
 		}
 	}(time.Now())
-	auth, err = m.next.AuthenticateUser(ctx, params)
+	auth, challenge, err = m.next.AuthenticateUser(ctx, params)
+	return auth, challenge, err
+}
+
+func (m *AuthLogMiddleware) AuthenticateTwoFactor(ctx context.Context, params *types.TwoFactorAuthParams) (auth *types.Auth, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to authenticate user with two-factor")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"took":   time.Since(start),
+				"userID": auth.UserID,
+			}).Info("AuthenticateTwoFactor successfully completed")
+		}
+	}(time.Now())
+	auth, err = m.next.AuthenticateTwoFactor(ctx, params)
+	return auth, err
+}
+
+func (m *AuthLogMiddleware) AuthenticateAccessToken(ctx context.Context, tokenStr string) (user *types.User, token *types.AccessToken, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to authenticate access token")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"took":   time.Since(start),
+				"userID": user.ID,
+				"token":  token.Prefix,
+			}).Info("AuthenticateAccessToken successfully completed")
+		}
+	}(time.Now())
+	user, token, err = m.next.AuthenticateAccessToken(ctx, tokenStr)
+	return user, token, err
+}
+
+func (m *AuthLogMiddleware) RefreshAuth(ctx context.Context, tokenStr string) (auth *types.Auth, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to refresh auth")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"took":       time.Since(start),
+				"userID":     auth.UserID,
+				"generation": auth.RefreshGeneration,
+			}).Info("RefreshAuth successfully completed")
+		}
+	}(time.Now())
+	auth, err = m.next.RefreshAuth(ctx, tokenStr)
+	return auth, err
+}
+
+func (m *AuthLogMiddleware) Impersonate(ctx context.Context, admin *types.User, userID, ip, userAgent string) (auth *types.Auth, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to impersonate user")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"took":           time.Since(start),
+				"userID":         auth.UserID,
+				"impersonatorID": auth.ImpersonatorID,
+				"expirationTime": auth.ExpirationTime,
+			}).Warn("Impersonate successfully completed")
+		}
+	}(time.Now())
+	auth, err = m.next.Impersonate(ctx, admin, userID, ip, userAgent)
 	return auth, err
 }
 
@@ -73,6 +141,16 @@ func (m *AuthLogMiddleware) CreateTokenFromAuth(auth *types.Auth) (token string,
 	return token, err
 }
 
+func (m *AuthLogMiddleware) CreateRefreshToken(auth *types.Auth) (token string, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create refresh token from auth")
+		}
+	}(time.Now())
+	token, err = m.next.CreateRefreshToken(auth)
+	return token, err
+}
+
 func (m *AuthLogMiddleware) ValidateToken(tokenStr string) (claims jwt.MapClaims, err error) {
 	defer func(start time.Time) {
 		if err != nil {
@@ -82,3 +160,7 @@ func (m *AuthLogMiddleware) ValidateToken(tokenStr string) (claims jwt.MapClaims
 	claims, err = m.next.ValidateToken(tokenStr)
 	return claims, err
 }
+
+func (m *AuthLogMiddleware) JWKS() types.JWKS {
+	return m.next.JWKS()
+}
@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/mailer"
 	"github.com/ficontini/gotasks/types"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -17,39 +21,239 @@ type AuthGetter interface {
 }
 type AuthServicer interface {
 	AuthGetter
-	AuthenticateUser(context.Context, *types.AuthParams) (*types.Auth, error)
+	AuthenticateUser(context.Context, *types.AuthParams) (*types.Auth, *types.TwoFactorChallenge, error)
+	AuthenticateTwoFactor(context.Context, *types.TwoFactorAuthParams) (*types.Auth, error)
+	AuthenticateAccessToken(context.Context, string) (*types.User, *types.AccessToken, error)
+	RefreshAuth(context.Context, string) (*types.Auth, error)
+	Impersonate(context.Context, *types.User, string, string, string) (*types.Auth, error)
 	CreateTokenFromAuth(*types.Auth) (string, error)
+	CreateRefreshToken(*types.Auth) (string, error)
 	ValidateToken(string) (jwt.MapClaims, error)
+	JWKS() types.JWKS
 }
+
+const (
+	AccessTokenTTLEnvName  = "ACCESS_TOKEN_TTL"
+	RefreshTokenTTLEnvName = "REFRESH_TOKEN_TTL"
+	refreshAudience        = "refresh"
+)
+
+// SetupAuthConfigFromEnv loads the key set and overrides the default token lifetimes
+// with the ones set in the environment, as Go durations like "15m" or "168h".
+func SetupAuthConfigFromEnv() error {
+	ks, err := NewKeySetFromEnv()
+	if err != nil {
+		return err
+	}
+	UseKeySet(ks)
+	for name, ttl := range map[string]*time.Duration{
+		AccessTokenTTLEnvName:  &types.AccessTokenTTL,
+		RefreshTokenTTLEnvName: &types.RefreshTokenTTL,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("%s env variable must be a positive duration", name)
+		}
+		*ttl = d
+	}
+	return nil
+}
+
 type AuthService struct {
-	store *db.Store
+	store  *db.Store
+	mailer mailer.Mailer
 }
 
-func NewAuthService(store *db.Store) AuthServicer {
+func NewAuthService(store *db.Store, mailer mailer.Mailer) AuthServicer {
 	return &AuthService{
-		store: store,
+		store:  store,
+		mailer: mailer,
 	}
 }
-func (svc *AuthService) AuthenticateUser(ctx context.Context, params *types.AuthParams) (*types.Auth, error) {
+
+// AuthenticateUser signs the user in with its password. Users with two-factor
+// authentication get a challenge to complete with AuthenticateTwoFactor instead.
+// Failed attempts slow further ones down for the email and the IP, see LockoutPolicy.
+func (svc *AuthService) AuthenticateUser(ctx context.Context, params *types.AuthParams) (*types.Auth, *types.TwoFactorChallenge, error) {
+	now := time.Now()
+	if err := checkLoginAttempts(ctx, svc.store, params, now); err != nil {
+		return nil, nil, err
+	}
 	user, err := svc.store.User.GetUserByEmail(ctx, params.Email)
 	if err != nil {
 		if errors.Is(err, db.ErrorNotFound) {
-			return nil, ErrInvalidCredentials
+			svc.addFailedLogin(ctx, nil, params, now)
+			return nil, nil, ErrInvalidCredentials
 		}
-		return nil, err
+		return nil, nil, err
 	}
 	if !user.IsPasswordValid(params.Password) {
-		return nil, ErrInvalidCredentials
+		svc.addFailedLogin(ctx, user, params, now)
+		return nil, nil, ErrInvalidCredentials
+	}
+	if !user.Verified {
+		return nil, nil, ErrEmailNotVerified
+	}
+	if !user.Enabled {
+		return nil, nil, ErrForbidden
+	}
+	if user.TOTPEnabled {
+		token, err := newChallengeToken(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		// The failed attempts are only reset once the second factor is verified too.
+		return nil, &types.TwoFactorChallenge{TwoFactorRequired: true, ChallengeToken: token}, nil
+	}
+	if err := svc.store.LoginAttempt.ResetLoginAttempts(ctx, emailAttemptsKey(user.Email)); err != nil {
+		return nil, nil, err
+	}
+	auth, err := svc.newSession(ctx, user, params.IP, params.UserAgent)
+	return auth, nil, err
+}
+
+// AuthenticateTwoFactor completes the challenge of AuthenticateUser with a code. Wrong
+// codes count as failed logins of the user, so they can't be guessed either.
+func (svc *AuthService) AuthenticateTwoFactor(ctx context.Context, params *types.TwoFactorAuthParams) (*types.Auth, error) {
+	claims, err := parseChallengeToken(params.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := svc.store.User.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, ErrInvalidChallengeToken
+		}
+		return nil, err
+	}
+	now := time.Now()
+	attempt := &types.AuthParams{Email: user.Email, IP: params.IP, UserAgent: params.UserAgent}
+	if err := checkLoginAttempts(ctx, svc.store, attempt, now); err != nil {
+		return nil, err
 	}
 	if !user.Enabled {
 		return nil, ErrForbidden
 	}
-	auth, err := svc.store.Auth.Insert(ctx, types.NewAuth(user.ID))
+	if err := verifySecondFactor(ctx, svc.store, user, params.Code); err != nil {
+		switch {
+		case errors.Is(err, ErrTwoFactorNotEnabled):
+			return nil, ErrInvalidChallengeToken
+		case errors.Is(err, ErrInvalidTwoFactorCode):
+			svc.addFailedLogin(ctx, user, attempt, now)
+		}
+		return nil, err
+	}
+	if err := svc.store.LoginAttempt.ResetLoginAttempts(ctx, emailAttemptsKey(user.Email)); err != nil {
+		return nil, err
+	}
+	return svc.newSession(ctx, user, params.IP, params.UserAgent)
+}
+
+// AuthenticateAccessToken returns the user of a personal access token along with the
+// token, whose scopes limit what it can be used for.
+func (svc *AuthService) AuthenticateAccessToken(ctx context.Context, tokenStr string) (*types.User, *types.AccessToken, error) {
+	token, err := svc.store.AccessToken.GetAccessToken(ctx, types.HashToken(tokenStr))
 	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, nil, ErrInvalidAccessToken
+		}
+		return nil, nil, err
+	}
+	if token.IsExpired(time.Now()) {
+		return nil, nil, ErrInvalidAccessToken
+	}
+	user, err := svc.store.User.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, nil, ErrInvalidAccessToken
+		}
+		return nil, nil, err
+	}
+	if !user.Enabled {
+		return nil, nil, ErrForbidden
+	}
+	return user, token, nil
+}
+
+// Impersonate starts a session of the user for the admin, which ends after
+// ImpersonationTTL. Users with roles can't be impersonated, so admins never get
+// permissions through it.
+func (svc *AuthService) Impersonate(ctx context.Context, admin *types.User, userID, ip, userAgent string) (*types.Auth, error) {
+	user, err := svc.store.User.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) || errors.Is(err, db.ErrInvalidID) {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
+	if len(user.Roles) > 0 {
+		return nil, ErrImpersonationForbidden
+	}
+	if !user.Enabled {
+		return nil, ErrForbidden
+	}
+	auth := types.NewImpersonationAuth(user.ID, admin.ID)
+	auth.IP = ip
+	auth.UserAgent = userAgent
+	return svc.store.Auth.Insert(ctx, auth)
+}
+func (svc *AuthService) newSession(ctx context.Context, user *types.User, ip, userAgent string) (*types.Auth, error) {
+	auth := types.NewAuth(user.ID)
+	auth.IP = ip
+	auth.UserAgent = userAgent
+	return svc.store.Auth.Insert(ctx, auth)
+}
+
+// RefreshAuth rotates the refresh token of the session. A refresh token that was
+// already used means it leaked, so the whole session is revoked.
+func (svc *AuthService) RefreshAuth(ctx context.Context, tokenStr string) (*types.Auth, error) {
+	claims, err := parseRefreshToken(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	filter := &types.AuthFilter{
+		UserID:   claims.Subject,
+		AuthUUID: claims.AuthUUID,
+	}
+	auth, err := svc.store.Auth.Get(ctx, filter)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if auth.IsExpired(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if claims.Generation != auth.RefreshGeneration {
+		return nil, svc.revokeReusedSession(ctx, filter)
+	}
+	if err := svc.store.Auth.RotateRefreshToken(ctx, filter, claims.Generation); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, svc.revokeReusedSession(ctx, filter)
+		}
+		return nil, err
+	}
+	user, err := svc.store.User.GetUserByID(ctx, auth.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Enabled {
+		return nil, ErrForbidden
+	}
+	auth.RefreshGeneration++
 	return auth, nil
 }
+func (svc *AuthService) revokeReusedSession(ctx context.Context, filter *types.AuthFilter) error {
+	if err := svc.store.Auth.Delete(ctx, filter); err != nil && !errors.Is(err, db.ErrorNotFound) {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
 func (svc *AuthService) GetUser(ctx context.Context, claims jwt.MapClaims) (*types.User, error) {
 	user, err := svc.store.User.GetUserByID(ctx, claims["id"].(string))
 	if err != nil {
@@ -72,24 +276,75 @@ func (svc *AuthService) CreateTokenFromAuth(auth *types.Auth) (string, error) {
 	claims := jwt.MapClaims{
 		"id":        auth.UserID,
 		"auth_uuid": auth.AuthUUID,
-		"exp":       auth.ExpirationTime,
+		"exp":       auth.AccessTokenExpiration(time.Now()),
 	}
-	secret := os.Getenv("JWT_SECRET")
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString([]byte(secret))
+	if auth.IsImpersonation() {
+		claims["impersonator"] = auth.ImpersonatorID
+	}
+	tokenStr, err := currentKeySet().Sign(claims)
 	if err != nil {
 		return tokenStr, fmt.Errorf("failed to generate auth token")
 	}
 	return tokenStr, nil
 }
+func (svc *AuthService) CreateRefreshToken(auth *types.Auth) (string, error) {
+	claims := refreshClaims{
+		AuthUUID:   auth.AuthUUID,
+		Generation: auth.RefreshGeneration,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   auth.UserID,
+			Audience:  jwt.ClaimStrings{refreshAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Unix(auth.ExpirationTime, 0)),
+		},
+	}
+	tokenStr, err := signWithDerivedKey(claims, refreshAudience)
+	if err != nil {
+		return tokenStr, fmt.Errorf("failed to generate refresh token")
+	}
+	return tokenStr, nil
+}
+
+type refreshClaims struct {
+	AuthUUID   string `json:"auth_uuid"`
+	Generation int    `json:"gen"`
+	jwt.RegisteredClaims
+}
+
+func parseRefreshToken(tokenStr string) (*refreshClaims, error) {
+	claims := &refreshClaims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return deriveKey(refreshAudience)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(refreshAudience))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	return claims, nil
+}
+
+// deriveKey returns a key for the given purpose derived from the JWT secret, so
+// tokens signed for one purpose are never accepted for another. Without a secret
+// anyone could derive the key, so it fails instead.
+func deriveKey(purpose string) ([]byte, error) {
+	secret := os.Getenv(JWTSecretEnvName)
+	if len(secret) == 0 {
+		return nil, ErrMissingJWTSecret
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil), nil
+}
+
+// signWithDerivedKey signs the claims with HS256 and the key of the purpose.
+func signWithDerivedKey(claims jwt.Claims, purpose string) (string, error) {
+	key, err := deriveKey(purpose)
+	if err != nil {
+		return "", err
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+}
+
 func (svc *AuthService) ValidateToken(tokenStr string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrUnAuthorized
-		}
-		secret := os.Getenv("JWT_SECRET")
-		return []byte(secret), nil
-	})
+	token, err := currentKeySet().Parse(tokenStr)
 	if err != nil {
 		return nil, ErrUnAuthorized
 	}
@@ -102,8 +357,19 @@ func (svc *AuthService) ValidateToken(tokenStr string) (jwt.MapClaims, error) {
 	}
 	return claims, nil
 }
+func (svc *AuthService) JWKS() types.JWKS {
+	return currentKeySet().JWKS()
+}
 
 var (
+	ErrMissingJWTSecret   = fmt.Errorf("%s env variable must be set", JWTSecretEnvName)
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrForbidden          = errors.New("forbidden")
+	ErrEmailNotVerified   = errors.New("email address is not verified")
+
+	ErrInvalidAccessToken  = errors.New("access token is invalid or expired")
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session has been revoked")
+
+	ErrImpersonationForbidden = errors.New("users with roles can't be impersonated")
 )
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/types"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+func newInvitationMessage(workspace *types.Workspace, email, token string) mailer.Message {
+	link := fmt.Sprintf("%s/accept-invitation?token=%s", os.Getenv(AppBaseURLEnvName), url.QueryEscape(token))
+	return mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("You're invited to the %s workspace", workspace.Name),
+		Body: fmt.Sprintf("Hi,\n\nYou were invited to join the %s workspace. Open the link below within %d days to create your account:\n\n%s\n",
+			workspace.Name, int(invitationTTL.Hours()/24), link),
+	}
+}
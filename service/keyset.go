@@ -0,0 +1,219 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ficontini/gotasks/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	JWTSecretEnvName               = "JWT_SECRET"
+	JWTSigningKeyFileEnvName       = "JWT_SIGNING_KEY_FILE"
+	JWTVerificationKeyFilesEnvName = "JWT_VERIFICATION_KEY_FILES"
+	minRSAKeyBits                  = 2048
+)
+
+type verificationKey struct {
+	jwk    types.JWK
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+// KeySet signs access tokens with its signing key, setting its kid in the header,
+// and verifies them with any of its verification keys. Keeping the previous keys
+// as verification keys lets the signing key be rotated without signing anyone out.
+// A key set without a signing key signs and verifies HS256 tokens with the secret.
+type KeySet struct {
+	secret       []byte
+	signingKey   crypto.Signer
+	signing      *verificationKey
+	verification map[string]*verificationKey
+}
+
+// NewKeySet returns a key set signing with signingKey, or with the secret when it
+// is nil. The public key of signingKey is always a verification key.
+func NewKeySet(secret []byte, signingKey crypto.Signer, verificationKeys ...crypto.PublicKey) (*KeySet, error) {
+	ks := &KeySet{
+		secret:       secret,
+		verification: map[string]*verificationKey{},
+	}
+	if signingKey != nil {
+		key, err := ks.addVerificationKey(signingKey.Public())
+		if err != nil {
+			return nil, err
+		}
+		ks.signingKey = signingKey
+		ks.signing = key
+	}
+	for _, pub := range verificationKeys {
+		if _, err := ks.addVerificationKey(pub); err != nil {
+			return nil, err
+		}
+	}
+	if ks.signing == nil && len(ks.secret) == 0 {
+		return nil, fmt.Errorf("either %s or %s env variable must be set", JWTSigningKeyFileEnvName, JWTSecretEnvName)
+	}
+	return ks, nil
+}
+
+// NewKeySetFromEnv loads the signing key from the PEM file in JWT_SIGNING_KEY_FILE
+// and the extra verification keys from the comma separated PEM files in
+// JWT_VERIFICATION_KEY_FILES. JWT_SECRET is required even with a signing key, as
+// refresh, verification and other single purpose tokens are signed with it.
+func NewKeySetFromEnv() (*KeySet, error) {
+	if len(os.Getenv(JWTSecretEnvName)) == 0 {
+		return nil, ErrMissingJWTSecret
+	}
+	var signingKey crypto.Signer
+	if path := os.Getenv(JWTSigningKeyFileEnvName); len(path) > 0 {
+		key, err := readSigningKey(path)
+		if err != nil {
+			return nil, err
+		}
+		signingKey = key
+	}
+	var verificationKeys []crypto.PublicKey
+	for _, path := range strings.Split(os.Getenv(JWTVerificationKeyFilesEnvName), ",") {
+		path = strings.TrimSpace(path)
+		if len(path) == 0 {
+			continue
+		}
+		key, err := readVerificationKey(path)
+		if err != nil {
+			return nil, err
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+	return NewKeySet([]byte(os.Getenv(JWTSecretEnvName)), signingKey, verificationKeys...)
+}
+
+func (ks *KeySet) addVerificationKey(pub crypto.PublicKey) (*verificationKey, error) {
+	var method jwt.SigningMethod
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	}
+	jwk, err := types.NewJWK(pub)
+	if err != nil {
+		return nil, err
+	}
+	key := &verificationKey{
+		jwk:    jwk,
+		method: method,
+		key:    pub,
+	}
+	ks.verification[jwk.Kid] = key
+	return key, nil
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	token.Header["kid"] = ks.signing.jwk.Kid
+	return token.SignedString(ks.signingKey)
+}
+
+// Parse verifies the token with the key its kid names. Tokens without a kid are
+// HS256 tokens verified with the secret, which keeps the access tokens signed before
+// switching to a signing key valid until they expire. Once every installation has
+// signed with a key for longer than types.AccessTokenTTL, they can be rejected when
+// the key set has a signing key.
+func (ks *KeySet) Parse(tokenStr string) (*jwt.Token, error) {
+	return jwt.Parse(tokenStr, ks.keyfunc, jwt.WithValidMethods([]string{
+		jwt.SigningMethodHS256.Alg(),
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}))
+}
+func (ks *KeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || len(ks.secret) == 0 {
+			return nil, ErrUnAuthorized
+		}
+		return ks.secret, nil
+	}
+	key, ok := ks.verification[kid]
+	if !ok || key.method.Alg() != token.Method.Alg() {
+		return nil, ErrUnAuthorized
+	}
+	return key.key, nil
+}
+
+// JWKS returns the public verification keys.
+func (ks *KeySet) JWKS() types.JWKS {
+	jwks := types.JWKS{Keys: []types.JWK{}}
+	for _, key := range ks.verification {
+		jwks.Keys = append(jwks.Keys, key.jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
+
+func readSigningKey(path string) (crypto.Signer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(b); err == nil {
+		return key, nil
+	}
+	key, err := jwt.ParseEdPrivateKeyFromPEM(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an RSA or Ed25519 private key in PEM format", path)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
+func readVerificationKey(path string) (crypto.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(b); err == nil {
+		return key, nil
+	}
+	key, err := jwt.ParseEdPublicKeyFromPEM(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an RSA or Ed25519 public key in PEM format", path)
+	}
+	return key, nil
+}
+
+// keySet is set up from the environment on start. Until then the JWT secret is
+// read when needed, as tests do not set the key set up.
+var keySet *KeySet
+
+// UseKeySet sets the key set access tokens are signed and verified with.
+func UseKeySet(ks *KeySet) {
+	keySet = ks
+}
+func currentKeySet() *KeySet {
+	if keySet != nil {
+		return keySet
+	}
+	return &KeySet{
+		secret:       []byte(os.Getenv(JWTSecretEnvName)),
+		verification: map[string]*verificationKey{},
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/types"
+	"github.com/sirupsen/logrus"
+)
+
+// EmailLockoutPolicy protects each account, and IPLockoutPolicy stops a single client
+// from guessing the passwords of many accounts. IPs are often shared, so they are
+// allowed more attempts.
+var (
+	EmailLockoutPolicy = types.LockoutPolicy{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		LockoutThreshold: 10,
+		LockoutDuration:  30 * time.Minute,
+	}
+	IPLockoutPolicy = types.LockoutPolicy{
+		FreeAttempts:     20,
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		LockoutThreshold: 100,
+		LockoutDuration:  30 * time.Minute,
+	}
+)
+
+// LoginLockoutError rejects a sign-in attempt made before the lockout policy allows it.
+type LoginLockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockoutError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+func emailAttemptsKey(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+func ipAttemptsKey(ip string) string {
+	return "ip:" + ip
+}
+
+func checkLoginAttempts(ctx context.Context, store *db.Store, params *types.AuthParams, now time.Time) error {
+	policies := map[string]types.LockoutPolicy{emailAttemptsKey(params.Email): EmailLockoutPolicy}
+	if len(params.IP) > 0 {
+		policies[ipAttemptsKey(params.IP)] = IPLockoutPolicy
+	}
+	var retryAt time.Time
+	for key, policy := range policies {
+		attempts, err := store.LoginAttempt.GetLoginAttempts(ctx, key)
+		if err != nil {
+			return err
+		}
+		if at := policy.RetryAt(attempts); at.After(retryAt) {
+			retryAt = at
+		}
+	}
+	if now.Before(retryAt) {
+		return &LoginLockoutError{RetryAfter: retryAt.Sub(now)}
+	}
+	return nil
+}
+
+// addFailedLogin counts a failed attempt for the email and the IP, and lets the owner
+// of the account know when it gets locked. A failure to count is only logged, so it
+// doesn't tell whether the email is registered.
+func (svc *AuthService) addFailedLogin(ctx context.Context, user *types.User, params *types.AuthParams, now time.Time) {
+	if len(params.IP) > 0 {
+		if _, err := svc.store.LoginAttempt.AddFailedLogin(ctx, ipAttemptsKey(params.IP), now); err != nil {
+			logrus.WithError(err).WithField("ip", params.IP).Warn("Failed to count failed login")
+		}
+	}
+	attempts, err := svc.store.LoginAttempt.AddFailedLogin(ctx, emailAttemptsKey(params.Email), now)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to count failed login")
+		return
+	}
+	if user == nil || attempts.Failures != EmailLockoutPolicy.LockoutThreshold {
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"userID":   user.ID,
+		"ip":       params.IP,
+		"failures": attempts.Failures,
+	}).Warn("Account locked after failed logins")
+	if err := svc.mailer.Send(ctx, newLockoutMessage(user, attempts, params.IP)); err != nil {
+		logrus.WithError(err).WithField("userID", user.ID).Warn("Failed to send lockout email")
+	}
+}
+
+func newLockoutMessage(user *types.User, attempts *types.LoginAttempts, ip string) mailer.Message {
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Your account was locked",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone failed to sign in to your account %d times, most recently from %s, so it is locked for %d minutes.\n\nIf it wasn't you, consider changing your password once the lock expires, or ask an admin to unlock your account.\n",
+			user.FirstName, attempts.Failures, ip, int(EmailLockoutPolicy.LockoutDuration.Minutes())),
+	}
+}
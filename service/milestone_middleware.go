@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/types"
+	"github.com/sirupsen/logrus"
+)
+
+type MilestoneLogMiddleware struct {
+	next MilestoneServicer
+}
+
+func NewMilestoneLogMiddleware(next MilestoneServicer) MilestoneServicer {
+	return &MilestoneLogMiddleware{
+		next: next,
+	}
+}
+
+func (m *MilestoneLogMiddleware) CreateMilestone(ctx context.Context, projectID, userID string, params types.NewMilestoneParams) (milestone *types.Milestone, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create milestone")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"milestoneID": milestone.ID,
+				"projectID":   projectID,
+				"took":        time.Since(start),
+			}).Info("Milestone created successfully")
+		}
+	}(time.Now())
+	milestone, err = m.next.CreateMilestone(ctx, projectID, userID, params)
+	return milestone, err
+}
+func (m *MilestoneLogMiddleware) GetMilestoneByID(ctx context.Context, id, userID string) (milestone *types.Milestone, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get milestone")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"milestoneID": id,
+				"took":        time.Since(start),
+			}).Info("Milestone retrieved successfully")
+		}
+	}(time.Now())
+	milestone, err = m.next.GetMilestoneByID(ctx, id, userID)
+	return milestone, err
+}
+func (m *MilestoneLogMiddleware) GetMilestones(ctx context.Context, projectID, userID string, pagination db.Pagination) (milestones []*types.Milestone, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get milestones")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": projectID,
+				"took":      time.Since(start),
+			}).Info("Get milestones")
+		}
+	}(time.Now())
+	milestones, err = m.next.GetMilestones(ctx, projectID, userID, pagination)
+	return milestones, err
+}
+func (m *MilestoneLogMiddleware) GetMilestoneProgress(ctx context.Context, id, userID string) (progress *types.MilestoneProgress, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get milestone progress")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"milestoneID": id,
+				"took":        time.Since(start),
+			}).Info("Get milestone progress")
+		}
+	}(time.Now())
+	progress, err = m.next.GetMilestoneProgress(ctx, id, userID)
+	return progress, err
+}
+func (m *MilestoneLogMiddleware) UpdateMilestone(ctx context.Context, id, userID string, params types.UpdateMilestoneParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to update milestone")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"milestoneID": id,
+				"took":        time.Since(start),
+			}).Info("Milestone updated successfully")
+		}
+	}(time.Now())
+	err = m.next.UpdateMilestone(ctx, id, userID, params)
+	return err
+}
+func (m *MilestoneLogMiddleware) DeleteMilestone(ctx context.Context, id, userID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to delete milestone")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"milestoneID": id,
+				"took":        time.Since(start),
+			}).Info("Milestone deleted successfully")
+		}
+	}(time.Now())
+	err = m.next.DeleteMilestone(ctx, id, userID)
+	return err
+}
+func (m *MilestoneLogMiddleware) AssignTask(ctx context.Context, id, userID string, params types.AssignMilestoneParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to assign task to milestone")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"milestoneID": id,
+				"taskID":      params.TaskID,
+				"took":        time.Since(start),
+			}).Info("Task assigned to milestone successfully")
+		}
+	}(time.Now())
+	err = m.next.AssignTask(ctx, id, userID, params)
+	return err
+}
+func (m *MilestoneLogMiddleware) UnassignTask(ctx context.Context, id, userID, taskID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to unassign task from milestone")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"milestoneID": id,
+				"taskID":      taskID,
+				"took":        time.Since(start),
+			}).Info("Task unassigned from milestone successfully")
+		}
+	}(time.Now())
+	err = m.next.UnassignTask(ctx, id, userID, taskID)
+	return err
+}
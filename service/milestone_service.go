@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/types"
+)
+
+var (
+	ErrMilestoneNotFound      = errors.New("milestone resource not found")
+	ErrTaskAlreadyInMilestone = errors.New("task is already assigned to this milestone")
+	ErrTaskNotInMilestone     = errors.New("task is not assigned to this milestone")
+)
+
+type MilestoneGetter interface {
+	GetMilestoneByID(context.Context, string, string) (*types.Milestone, error)
+	GetMilestones(context.Context, string, string, db.Pagination) ([]*types.Milestone, error)
+	GetMilestoneProgress(context.Context, string, string) (*types.MilestoneProgress, error)
+}
+type MilestoneCreator interface {
+	CreateMilestone(context.Context, string, string, types.NewMilestoneParams) (*types.Milestone, error)
+}
+type MilestoneUpdater interface {
+	UpdateMilestone(context.Context, string, string, types.UpdateMilestoneParams) error
+}
+type MilestoneDeleter interface {
+	DeleteMilestone(context.Context, string, string) error
+}
+type MilestoneTaskManager interface {
+	AssignTask(context.Context, string, string, types.AssignMilestoneParams) error
+	UnassignTask(context.Context, string, string, string) error
+}
+type MilestoneServicer interface {
+	MilestoneGetter
+	MilestoneCreator
+	MilestoneUpdater
+	MilestoneDeleter
+	MilestoneTaskManager
+}
+
+type MilestoneService struct {
+	store *db.Store
+}
+
+func NewMilestoneService(store *db.Store) MilestoneServicer {
+	return &MilestoneService{
+		store: store,
+	}
+}
+
+func (svc *MilestoneService) CreateMilestone(ctx context.Context, projectID, userID string, params types.NewMilestoneParams) (*types.Milestone, error) {
+	if _, err := authorizeProjectUpdate(ctx, svc.store, projectID, userID, types.ProjectRoleEditor); err != nil {
+		return nil, err
+	}
+	milestone := types.NewMilestoneFromParams(projectID, params)
+	return svc.store.Milestone.InsertMilestone(ctx, milestone)
+}
+func (svc *MilestoneService) GetMilestoneByID(ctx context.Context, id, userID string) (*types.Milestone, error) {
+	return svc.authorizeMilestone(ctx, id, userID, types.ProjectRoleViewer)
+}
+func (svc *MilestoneService) GetMilestones(ctx context.Context, projectID, userID string, pagination db.Pagination) ([]*types.Milestone, error) {
+	if _, err := authorizeProject(ctx, svc.store, projectID, userID, types.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+	filter := db.NewProjectMilestonesFilter(projectID)
+	return svc.store.Milestone.GetMilestones(ctx, filter, &pagination)
+}
+func (svc *MilestoneService) GetMilestoneProgress(ctx context.Context, id, userID string) (*types.MilestoneProgress, error) {
+	milestone, err := svc.authorizeMilestone(ctx, id, userID, types.ProjectRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := getAllTasks(ctx, svc.store, db.NewMilestoneTasksFilter(milestone.ProjectID, milestone.ID))
+	if err != nil {
+		return nil, err
+	}
+	return types.NewMilestoneProgress(milestone, tasks, time.Now()), nil
+}
+func (svc *MilestoneService) UpdateMilestone(ctx context.Context, id, userID string, params types.UpdateMilestoneParams) error {
+	if _, err := svc.authorizeMilestoneUpdate(ctx, id, userID); err != nil {
+		return err
+	}
+	update := db.MilestoneUpdater{
+		Name:        params.Name,
+		Description: params.Description,
+		TargetDate:  params.TargetDate,
+	}
+	return svc.store.Milestone.Update(ctx, id, update)
+}
+
+// DeleteMilestone removes the milestone and unassigns its tasks in a single transaction.
+func (svc *MilestoneService) DeleteMilestone(ctx context.Context, id, userID string) error {
+	milestone, err := svc.authorizeMilestoneUpdate(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	tasks, err := getAllTasks(ctx, svc.store, db.NewMilestoneTasksFilter(milestone.ProjectID, milestone.ID))
+	if err != nil {
+		return err
+	}
+	actions := make([]db.DBAction, 0, len(tasks)+1)
+	for _, task := range tasks {
+		action, err := db.NewTaskUpdateAction(task.ID, db.TaskMilestoneRemover{})
+		if err != nil {
+			return err
+		}
+		actions = append(actions, action)
+	}
+	actions = append(actions, db.NewMilestoneDeleteAction(milestone.ID))
+	return svc.store.Project.Transact(ctx, actions)
+}
+func (svc *MilestoneService) AssignTask(ctx context.Context, id, userID string, params types.AssignMilestoneParams) error {
+	milestone, err := svc.authorizeMilestoneUpdate(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	task, err := svc.store.Task.GetTaskByID(ctx, params.TaskID)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) || errors.Is(err, db.ErrInvalidID) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+	if task.ProjectID != milestone.ProjectID {
+		return ErrTaskNotInProject
+	}
+	if task.MilestoneID == milestone.ID {
+		return ErrTaskAlreadyInMilestone
+	}
+	return svc.store.Task.Update(ctx, task.ID, db.TaskMilestoneUpdater{MilestoneID: milestone.ID})
+}
+func (svc *MilestoneService) UnassignTask(ctx context.Context, id, userID, taskID string) error {
+	milestone, err := svc.authorizeMilestoneUpdate(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	task, err := svc.store.Task.GetTaskByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) || errors.Is(err, db.ErrInvalidID) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+	if task.MilestoneID != milestone.ID {
+		return ErrTaskNotInMilestone
+	}
+	return svc.store.Task.Update(ctx, task.ID, db.TaskMilestoneRemover{})
+}
+
+func (svc *MilestoneService) getMilestone(ctx context.Context, id string) (*types.Milestone, error) {
+	milestone, err := svc.store.Milestone.GetMilestoneByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) || errors.Is(err, db.ErrInvalidID) {
+			return nil, ErrMilestoneNotFound
+		}
+		return nil, err
+	}
+	return milestone, nil
+}
+func (svc *MilestoneService) authorizeMilestone(ctx context.Context, id, userID string, role types.ProjectRole) (*types.Milestone, error) {
+	milestone, err := svc.getMilestone(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := authorizeProject(ctx, svc.store, milestone.ProjectID, userID, role); err != nil {
+		return nil, err
+	}
+	return milestone, nil
+}
+func (svc *MilestoneService) authorizeMilestoneUpdate(ctx context.Context, id, userID string) (*types.Milestone, error) {
+	milestone, err := svc.getMilestone(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := authorizeProjectUpdate(ctx, svc.store, milestone.ProjectID, userID, types.ProjectRoleEditor); err != nil {
+		return nil, err
+	}
+	return milestone, nil
+}
+
+const maxPageLimit = 100
+
+// getAllTasks pages through every task matching the filter.
+func getAllTasks(ctx context.Context, store *db.Store, filter db.Filter) ([]*types.Task, error) {
+	var tasks []*types.Task
+	for page := int64(1); ; page++ {
+		pagination := db.Pagination{Page: page, Limit: maxPageLimit}
+		batch, err := store.Task.GetTasks(ctx, filter, &pagination)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, batch...)
+		if len(batch) < maxPageLimit {
+			return tasks, nil
+		}
+	}
+}
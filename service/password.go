@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ficontini/gotasks/types"
+)
+
+const (
+	PasswordMinLengthEnvName      = "PASSWORD_MIN_LENGTH"
+	PasswordMinCharClassesEnvName = "PASSWORD_MIN_CHAR_CLASSES"
+	PasswordCommonListFileEnvName = "PASSWORD_COMMON_LIST_FILE"
+	maxPasswordCharClasses        = 4
+)
+
+// SetupPasswordPolicyFromEnv overrides the default password policy with the one set in
+// the environment. The passwords of the PASSWORD_COMMON_LIST_FILE file, one per line,
+// are rejected along with the bundled list of common passwords.
+func SetupPasswordPolicyFromEnv() error {
+	policy := types.NewPasswordPolicy(types.DefaultMinPasswordLen, 0)
+	if value := os.Getenv(PasswordMinLengthEnvName); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > types.MaxPasswordBytes {
+			return fmt.Errorf("%s env variable must be a number between 1 and %d", PasswordMinLengthEnvName, types.MaxPasswordBytes)
+		}
+		policy.MinLength = n
+	}
+	if value := os.Getenv(PasswordMinCharClassesEnvName); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > maxPasswordCharClasses {
+			return fmt.Errorf("%s env variable must be a number between 0 and %d", PasswordMinCharClassesEnvName, maxPasswordCharClasses)
+		}
+		policy.MinCharClasses = n
+	}
+	if path := os.Getenv(PasswordCommonListFileEnvName); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		common, err := types.ReadCommonPasswords(f)
+		if err != nil {
+			return err
+		}
+		for password := range common {
+			policy.Common[password] = true
+		}
+	}
+	types.UsePasswordPolicy(policy)
+	return nil
+}
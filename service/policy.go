@@ -0,0 +1,74 @@
+package service
+
+import (
+	"github.com/ficontini/gotasks/types"
+)
+
+// DefaultPolicy grants admins every permission and support staff the management of
+// user accounts.
+var DefaultPolicy = NewPolicy(map[types.Role][]types.Permission{
+	types.RoleAdmin: types.Permissions,
+	types.RoleSupport: {
+		types.PermissionUserRead,
+		types.PermissionUserEnable,
+		types.PermissionUserDisable,
+		types.PermissionUserUnlock,
+		types.PermissionUserImpersonate,
+		types.PermissionUserSessionRevoke,
+		types.PermissionUserTwoFactorReset,
+	},
+})
+
+// Policy decides what users may do from the permissions of their roles.
+type Policy struct {
+	roles map[types.Role]map[types.Permission]bool
+}
+
+func NewPolicy(roles map[types.Role][]types.Permission) *Policy {
+	p := &Policy{
+		roles: map[types.Role]map[types.Permission]bool{},
+	}
+	for role, permissions := range roles {
+		p.roles[role] = map[types.Permission]bool{}
+		for _, permission := range permissions {
+			p.roles[role][permission] = true
+		}
+	}
+	return p
+}
+
+func (p *Policy) Can(user *types.User, permission types.Permission) bool {
+	for _, role := range user.Roles {
+		if p.roles[role][permission] {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) Authorize(user *types.User, permission types.Permission) error {
+	if !p.Can(user, permission) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Roles returns the roles of the policy with their permissions, in the order of
+// types.Roles and types.Permissions.
+func (p *Policy) Roles() []types.RoleDefinition {
+	roles := []types.RoleDefinition{}
+	for _, role := range types.Roles {
+		permissions, ok := p.roles[role]
+		if !ok {
+			continue
+		}
+		definition := types.RoleDefinition{Role: role, Permissions: []types.Permission{}}
+		for _, permission := range types.Permissions {
+			if permissions[permission] {
+				definition.Permissions = append(definition.Permissions, permission)
+			}
+		}
+		roles = append(roles, definition)
+	}
+	return roles
+}
@@ -32,7 +32,22 @@ func (m *ProjectLogMiddleware) CreateProject(ctx context.Context, params types.N
 	project, err = m.next.CreateProject(ctx, params, userID)
 	return project, err
 }
-func (m *ProjectLogMiddleware) GetProjectByID(ctx context.Context, id string) (project *types.Project, err error) {
+func (m *ProjectLogMiddleware) CloneProject(ctx context.Context, id, userID string, params types.CloneProjectParams) (project *types.Project, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to clone project")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"sourceID":  id,
+				"projectID": project.ID,
+				"took":      time.Since(start),
+			}).Info("Project cloned successfully")
+		}
+	}(time.Now())
+	project, err = m.next.CloneProject(ctx, id, userID, params)
+	return project, err
+}
+func (m *ProjectLogMiddleware) GetProjectByID(ctx context.Context, id, userID string) (project *types.Project, err error) {
 	defer func(start time.Time) {
 		if err != nil {
 			logrus.WithError(err).Error("Failed to get project")
@@ -43,11 +58,11 @@ func (m *ProjectLogMiddleware) GetProjectByID(ctx context.Context, id string) (p
 			}).Info("Project retrieved succesfully")
 		}
 	}(time.Now())
-	project, err = m.next.GetProjectByID(ctx, id)
+	project, err = m.next.GetProjectByID(ctx, id, userID)
 	return project, err
 
 }
-func (m *ProjectLogMiddleware) AddTask(ctx context.Context, projectID string, params types.AddTaskParams) (err error) {
+func (m *ProjectLogMiddleware) AddTask(ctx context.Context, projectID, userID string, params types.AddTaskParams) (err error) {
 	defer func(start time.Time) {
 		if err != nil {
 			logrus.WithError(err).Error("Failed to add task to project")
@@ -59,6 +74,182 @@ func (m *ProjectLogMiddleware) AddTask(ctx context.Context, projectID string, pa
 			}).Info("Task added to project succesfully")
 		}
 	}(time.Now())
-	err = m.next.AddTask(ctx, projectID, params)
+	err = m.next.AddTask(ctx, projectID, userID, params)
+	return err
+}
+func (m *ProjectLogMiddleware) AddMember(ctx context.Context, projectID, userID string, params types.AddMemberParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to add member to project")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": projectID,
+				"role":      params.Role,
+				"took":      time.Since(start),
+			}).Info("Member added to project succesfully")
+		}
+	}(time.Now())
+	err = m.next.AddMember(ctx, projectID, userID, params)
+	return err
+}
+func (m *ProjectLogMiddleware) UpdateMemberRole(ctx context.Context, projectID, userID, memberID string, params types.UpdateMemberRoleParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to update project member role")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": projectID,
+				"memberID":  memberID,
+				"role":      params.Role,
+				"took":      time.Since(start),
+			}).Info("Project member role updated succesfully")
+		}
+	}(time.Now())
+	err = m.next.UpdateMemberRole(ctx, projectID, userID, memberID, params)
+	return err
+}
+func (m *ProjectLogMiddleware) RemoveMember(ctx context.Context, projectID, userID, memberID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to remove member from project")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": projectID,
+				"memberID":  memberID,
+				"took":      time.Since(start),
+			}).Info("Member removed from project succesfully")
+		}
+	}(time.Now())
+	err = m.next.RemoveMember(ctx, projectID, userID, memberID)
+	return err
+}
+func (m *ProjectLogMiddleware) GetProjects(ctx context.Context, userID string, params ProjectQueryParams) (projects []*types.Project, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get projects")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": userID,
+				"took":   time.Since(start),
+			}).Info("Get projects")
+		}
+	}(time.Now())
+	projects, err = m.next.GetProjects(ctx, userID, params)
+	return projects, err
+}
+func (m *ProjectLogMiddleware) GetProjectTasks(ctx context.Context, id, userID string, params ProjectTaskQueryParams) (tasks []*types.Task, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get project tasks")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": id,
+				"took":      time.Since(start),
+			}).Info("Get project tasks")
+		}
+	}(time.Now())
+	tasks, err = m.next.GetProjectTasks(ctx, id, userID, params)
+	return tasks, err
+}
+func (m *ProjectLogMiddleware) GetProjectStats(ctx context.Context, id, userID string) (stats *types.ProjectStats, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get project stats")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": id,
+				"took":      time.Since(start),
+			}).Info("Get project stats")
+		}
+	}(time.Now())
+	stats, err = m.next.GetProjectStats(ctx, id, userID)
+	return stats, err
+}
+func (m *ProjectLogMiddleware) UpdateProject(ctx context.Context, id, userID string, params types.UpdateProjectParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to update project")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": id,
+				"took":      time.Since(start),
+			}).Info("Project updated succesfully")
+		}
+	}(time.Now())
+	err = m.next.UpdateProject(ctx, id, userID, params)
+	return err
+}
+func (m *ProjectLogMiddleware) ArchiveProject(ctx context.Context, id, userID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to archive project")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": id,
+				"took":      time.Since(start),
+			}).Info("Project archived succesfully")
+		}
+	}(time.Now())
+	err = m.next.ArchiveProject(ctx, id, userID)
+	return err
+}
+func (m *ProjectLogMiddleware) UnarchiveProject(ctx context.Context, id, userID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to unarchive project")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": id,
+				"took":      time.Since(start),
+			}).Info("Project unarchived succesfully")
+		}
+	}(time.Now())
+	err = m.next.UnarchiveProject(ctx, id, userID)
+	return err
+}
+func (m *ProjectLogMiddleware) DeleteProject(ctx context.Context, id, userID string, params types.DeleteProjectParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to delete project")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": id,
+				"tasks":     params.Tasks,
+				"took":      time.Since(start),
+			}).Info("Project deleted succesfully")
+		}
+	}(time.Now())
+	err = m.next.DeleteProject(ctx, id, userID, params)
+	return err
+}
+func (m *ProjectLogMiddleware) RemoveTask(ctx context.Context, projectID, userID, taskID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to remove task from project")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID": projectID,
+				"taskID":    taskID,
+				"took":      time.Since(start),
+			}).Info("Task removed from project succesfully")
+		}
+	}(time.Now())
+	err = m.next.RemoveTask(ctx, projectID, userID, taskID)
+	return err
+}
+func (m *ProjectLogMiddleware) MoveTask(ctx context.Context, projectID, userID, taskID string, params types.MoveTaskParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to move task to project")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"projectID":       projectID,
+				"targetProjectID": params.ProjectID,
+				"taskID":          taskID,
+				"took":            time.Since(start),
+			}).Info("Task moved to project succesfully")
+		}
+	}(time.Now())
+	err = m.next.MoveTask(ctx, projectID, userID, taskID, params)
 	return err
 }
@@ -11,12 +11,52 @@ import (
 var (
 	ErrTaskAlreadyAssociated = errors.New("task is already associated with this project")
 	ErrProjectNotFound       = errors.New("project resource not found")
+	ErrMemberAlreadyExists   = errors.New("user is already a member of this project")
+	ErrMemberNotFound        = errors.New("project member not found")
+	ErrMemberRoleUnchanged   = errors.New("project member role unchanged")
+	ErrLastProjectOwner      = errors.New("project must have at least one owner")
+	ErrProjectArchived       = errors.New("project is archived")
+	ErrProjectStateUnchanged = errors.New("project state unchanged")
+	ErrTaskInAnotherProject  = errors.New("task belongs to another project, move it instead")
+	ErrTaskNotInProject      = errors.New("task is not associated with this project")
+	ErrTooManyTasks          = errors.New("too many tasks to create in a single transaction")
 )
 
-type ProjectServicer interface {
+type ProjectGetter interface {
+	GetProjectByID(context.Context, string, string) (*types.Project, error)
+	GetProjects(context.Context, string, ProjectQueryParams) ([]*types.Project, error)
+	GetProjectStats(context.Context, string, string) (*types.ProjectStats, error)
+	GetProjectTasks(context.Context, string, string, ProjectTaskQueryParams) ([]*types.Task, error)
+}
+type ProjectCreator interface {
 	CreateProject(context.Context, types.NewProjectParams, string) (*types.Project, error)
-	GetProjectByID(context.Context, string) (*types.Project, error)
-	AddTask(context.Context, string, types.AddTaskParams) error
+	CloneProject(context.Context, string, string, types.CloneProjectParams) (*types.Project, error)
+}
+type ProjectUpdater interface {
+	UpdateProject(context.Context, string, string, types.UpdateProjectParams) error
+	ArchiveProject(context.Context, string, string) error
+	UnarchiveProject(context.Context, string, string) error
+}
+type ProjectDeleter interface {
+	DeleteProject(context.Context, string, string, types.DeleteProjectParams) error
+}
+type ProjectTaskManager interface {
+	AddTask(context.Context, string, string, types.AddTaskParams) error
+	RemoveTask(context.Context, string, string, string) error
+	MoveTask(context.Context, string, string, string, types.MoveTaskParams) error
+}
+type ProjectMemberManager interface {
+	AddMember(context.Context, string, string, types.AddMemberParams) error
+	UpdateMemberRole(context.Context, string, string, string, types.UpdateMemberRoleParams) error
+	RemoveMember(context.Context, string, string, string) error
+}
+type ProjectServicer interface {
+	ProjectGetter
+	ProjectCreator
+	ProjectUpdater
+	ProjectDeleter
+	ProjectTaskManager
+	ProjectMemberManager
 }
 type ProjectService struct {
 	store *db.Store
@@ -31,55 +71,292 @@ func NewProjectService(store *db.Store) ProjectServicer {
 func (svc *ProjectService) CreateProject(ctx context.Context, params types.NewProjectParams, userID string) (*types.Project, error) {
 	project := types.NewProjectFromParams(params)
 	project.UserID = userID
+	project.Members[userID] = types.ProjectRoleOwner
 	return svc.store.Project.InsertProject(ctx, project)
 }
 
-func (svc *ProjectService) GetProjectByID(ctx context.Context, id string) (*types.Project, error) {
-	project, err := svc.store.Project.GetProjectByID(ctx, id)
+// CloneProject copies the project, and optionally its tasks, into a new project owned by the user.
+// Copied tasks keep their due dates but lose their completion, assignee and milestone.
+func (svc *ProjectService) CloneProject(ctx context.Context, id, userID string, params types.CloneProjectParams) (*types.Project, error) {
+	source, err := authorizeProject(ctx, svc.store, id, userID, types.ProjectRoleViewer)
 	if err != nil {
-		if errors.Is(err, db.ErrorNotFound) {
-			return nil, ErrProjectNotFound
+		return nil, err
+	}
+	project := types.NewProjectFromParams(types.NewProjectParams{
+		Name:        valueOrDefault(params.Name, source.Name),
+		Description: valueOrDefault(params.Description, source.Description),
+	})
+	project.UserID = userID
+	project.Members[userID] = types.ProjectRoleOwner
+	tasks := []*types.Task{}
+	if params.IncludeTasks {
+		sourceTasks, err := getAllTasks(ctx, svc.store, db.NewProjectTasksFilter(source.ID, nil, ""))
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range sourceTasks {
+			tasks = append(tasks, task.Duplicate())
 		}
+	}
+	if err := insertProjectWithTasks(ctx, svc.store, project, tasks); err != nil {
 		return nil, err
 	}
 	return project, nil
 }
+func (svc *ProjectService) GetProjectByID(ctx context.Context, id, userID string) (*types.Project, error) {
+	return authorizeProject(ctx, svc.store, id, userID, types.ProjectRoleViewer)
+}
+
+type ProjectQueryParams struct {
+	db.Pagination
+	Archived bool
+}
+
+type ProjectTaskQueryParams struct {
+	TaskQueryParams
+	AssignedTo string
+}
+
+func (svc *ProjectService) GetProjectTasks(ctx context.Context, id, userID string, params ProjectTaskQueryParams) ([]*types.Task, error) {
+	if _, err := authorizeProject(ctx, svc.store, id, userID, types.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+	filter := db.NewProjectTasksFilter(id, params.Completed, params.AssignedTo)
+	return svc.store.Task.GetTasks(ctx, filter, &params.Pagination)
+}
+func (svc *ProjectService) GetProjectStats(ctx context.Context, id, userID string) (*types.ProjectStats, error) {
+	if _, err := authorizeProject(ctx, svc.store, id, userID, types.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+	return svc.store.ProjectStats.GetProjectStats(ctx, id)
+}
+func (svc *ProjectService) GetProjects(ctx context.Context, userID string, params ProjectQueryParams) ([]*types.Project, error) {
+	filter := db.NewUserProjectsFilter(params.Archived, userID)
+	return svc.store.Project.GetProjects(ctx, filter, &params.Pagination)
+}
+
+func (svc *ProjectService) UpdateProject(ctx context.Context, id, userID string, params types.UpdateProjectParams) error {
+	project, err := authorizeProjectUpdate(ctx, svc.store, id, userID, types.ProjectRoleEditor)
+	if err != nil {
+		return err
+	}
+	update := db.ProjectUpdater{}
+	if len(params.Name) > 0 && params.Name != project.Name {
+		update.Name = params.Name
+	}
+	if len(params.Description) > 0 && params.Description != project.Description {
+		update.Description = params.Description
+	}
+	if update == (db.ProjectUpdater{}) {
+		return ErrProjectStateUnchanged
+	}
+	if err := svc.store.Project.Update(ctx, id, update); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (svc *ProjectService) setArchived(ctx context.Context, id, userID string, archived bool) error {
+	project, err := authorizeProject(ctx, svc.store, id, userID, types.ProjectRoleOwner)
+	if err != nil {
+		return err
+	}
+	if project.Archived == archived {
+		return ErrProjectStateUnchanged
+	}
+	return svc.store.Project.Update(ctx, id, db.ProjectArchiveUpdater{Archived: archived})
+}
+
+func (svc *ProjectService) ArchiveProject(ctx context.Context, id, userID string) error {
+	return svc.setArchived(ctx, id, userID, true)
+}
+
+func (svc *ProjectService) UnarchiveProject(ctx context.Context, id, userID string) error {
+	return svc.setArchived(ctx, id, userID, false)
+}
+
+func (svc *ProjectService) DeleteProject(ctx context.Context, id, userID string, params types.DeleteProjectParams) error {
+	project, err := authorizeProject(ctx, svc.store, id, userID, types.ProjectRoleOwner)
+	if err != nil {
+		return err
+	}
+	return deleteProject(ctx, svc.store, project, params)
+}
+
+// deleteProject removes the project and its milestones, and detaches or deletes its
+// tasks. A project can have more tasks than fit in a transaction, so the actions run
+// in chunks, with the project deleted in the last one: a deletion that fails halfway
+// leaves the project in place and can be retried, skipping what is already done.
+func deleteProject(ctx context.Context, store *db.Store, project *types.Project, params types.DeleteProjectParams) error {
+	var (
+		actions []db.DBAction
+		taskIDs []string
+	)
+	for _, taskID := range project.Tasks {
+		task, err := store.Task.GetTaskByID(ctx, taskID)
+		if errors.Is(err, db.ErrorNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if task.ProjectID != project.ID {
+			continue
+		}
+		taskIDs = append(taskIDs, taskID)
+		if params.Tasks == types.DeleteProjectTasks {
+			actions = append(actions, db.NewTaskDeleteAction(taskID))
+			continue
+		}
+		action, err := db.NewTaskUpdateAction(taskID, db.TaskProjectIDRemover{})
+		if err != nil {
+			return err
+		}
+		actions = append(actions, action)
+	}
+	// Each chunk of tasks drops their summaries from the project stats with them.
+	for start := 0; start < len(actions); start += db.MaxTransactItems - 1 {
+		end := min(start+db.MaxTransactItems-1, len(actions))
+		chunk := append(actions[start:end:end], db.NewTaskSummaryRemoveAction(project.ID, taskIDs[start:end]...))
+		if err := store.Project.Transact(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	actions = nil
+	milestones, err := getAllMilestones(ctx, store, db.NewProjectMilestonesFilter(project.ID))
+	if err != nil {
+		return err
+	}
+	for _, milestone := range milestones {
+		actions = append(actions, db.NewMilestoneDeleteAction(milestone.ID))
+	}
+	actions = append(actions, db.NewProjectStatsDeleteAction(project.ID), db.NewProjectDeleteAction(project.ID))
+	for start := 0; start < len(actions); start += db.MaxTransactItems {
+		end := min(start+db.MaxTransactItems, len(actions))
+		if err := store.Project.Transact(ctx, actions[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func getAllMilestones(ctx context.Context, store *db.Store, filter db.Filter) ([]*types.Milestone, error) {
+	var milestones []*types.Milestone
+	for page := int64(1); ; page++ {
+		pagination := db.Pagination{Page: page, Limit: maxPageLimit}
+		batch, err := store.Milestone.GetMilestones(ctx, filter, &pagination)
+		if err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, batch...)
+		if len(batch) < maxPageLimit {
+			return milestones, nil
+		}
+	}
+}
 
-func (svc *ProjectService) AddTask(ctx context.Context, projectID string, params types.AddTaskParams) error {
+func (svc *ProjectService) AddTask(ctx context.Context, projectID, userID string, params types.AddTaskParams) error {
 	exists, task := svc.taskExists(ctx, params.TaskID)
 	if !exists {
 		return ErrTaskNotFound
 	}
-	if !svc.projectExists(ctx, projectID) {
-		return ErrProjectNotFound
+	project, err := authorizeProjectUpdate(ctx, svc.store, projectID, userID, types.ProjectRoleEditor)
+	if err != nil {
+		return err
 	}
-	if task.ProjectID == projectID {
+	if task.ProjectID == projectID || project.ContainsTask(task.ID) {
 		return ErrTaskAlreadyAssociated
 	}
-	actions, err := createActions(projectID, params.TaskID)
-	if err != nil {
-		return err
+	if len(task.ProjectID) > 0 {
+		return ErrTaskInAnotherProject
 	}
-	if err := svc.store.Project.TransactAddTask(ctx, actions); err != nil {
+	actions, err := createActions(projectID, task)
+	if err != nil {
 		return err
 	}
-	return nil
+	return svc.store.Project.Transact(ctx, actions)
 }
-func createActions(projectID, taskID string) ([]*db.UpdateAction, error) {
-	actions := []*db.UpdateAction{}
 
-	taskAction, err := db.NewTaskUpdateAction(taskID, db.TaskProjectIDUpdater{ProjectID: projectID})
+// createActions adds the task to the project, summarizing it in the project stats.
+func createActions(projectID string, task *types.Task) ([]db.DBAction, error) {
+	actions := []db.DBAction{}
+
+	taskAction, err := db.NewTaskUpdateAction(task.ID, db.TaskProjectIDUpdater{ProjectID: projectID})
 	if err != nil {
 		return nil, err
 	}
 	actions = append(actions, taskAction)
-	projectAction, err := db.NewProjectUpdateAction(projectID, db.AddTaskToProjectUpdater{TaskID: taskID})
+	projectAction, err := db.NewProjectUpdateAction(projectID, db.AddTaskToProjectUpdater{TaskID: task.ID})
 	if err != nil {
 		return nil, err
 	}
-	actions = append(actions, projectAction)
+	actions = append(actions, projectAction, db.NewTaskSummaryAction(projectID, task))
 	return actions, nil
 }
+func (svc *ProjectService) RemoveTask(ctx context.Context, projectID, userID, taskID string) error {
+	project, err := authorizeProjectUpdate(ctx, svc.store, projectID, userID, types.ProjectRoleEditor)
+	if err != nil {
+		return err
+	}
+	exists, task := svc.taskExists(ctx, taskID)
+	if !exists {
+		return ErrTaskNotFound
+	}
+	index := project.TaskIndex(taskID)
+	if index < 0 {
+		return ErrTaskNotInProject
+	}
+	actions := []db.DBAction{}
+	if task.ProjectID == projectID {
+		taskAction, err := db.NewTaskUpdateAction(taskID, db.TaskProjectIDRemover{})
+		if err != nil {
+			return err
+		}
+		actions = append(actions, taskAction, db.NewTaskSummaryRemoveAction(projectID, taskID))
+	}
+	projectAction, err := db.NewProjectUpdateAction(projectID, db.RemoveTaskFromProjectUpdater{TaskID: taskID, Index: index})
+	if err != nil {
+		return err
+	}
+	actions = append(actions, projectAction)
+	return svc.store.Project.Transact(ctx, actions)
+}
+
+// MoveTask detaches the task from its project and adds it to the target one in a single transaction.
+func (svc *ProjectService) MoveTask(ctx context.Context, projectID, userID, taskID string, params types.MoveTaskParams) error {
+	source, err := authorizeProjectUpdate(ctx, svc.store, projectID, userID, types.ProjectRoleEditor)
+	if err != nil {
+		return err
+	}
+	target, err := authorizeProjectUpdate(ctx, svc.store, params.ProjectID, userID, types.ProjectRoleEditor)
+	if err != nil {
+		return err
+	}
+	exists, task := svc.taskExists(ctx, taskID)
+	if !exists {
+		return ErrTaskNotFound
+	}
+	index := source.TaskIndex(taskID)
+	if index < 0 || task.ProjectID != source.ID {
+		return ErrTaskNotInProject
+	}
+	if target.ContainsTask(taskID) {
+		return ErrTaskAlreadyAssociated
+	}
+	removeAction, err := db.NewProjectUpdateAction(source.ID, db.RemoveTaskFromProjectUpdater{TaskID: taskID, Index: index})
+	if err != nil {
+		return err
+	}
+	addActions, err := createActions(target.ID, task)
+	if err != nil {
+		return err
+	}
+	actions := append([]db.DBAction{removeAction, db.NewTaskSummaryRemoveAction(source.ID, taskID)}, addActions...)
+	return svc.store.Project.Transact(ctx, actions)
+}
+
 func (svc *ProjectService) taskExists(ctx context.Context, id string) (bool, *types.Task) {
 	task, err := svc.store.Task.GetTaskByID(ctx, id)
 	if err != nil {
@@ -87,10 +364,128 @@ func (svc *ProjectService) taskExists(ctx context.Context, id string) (bool, *ty
 	}
 	return task != nil, task
 }
-func (svc *ProjectService) projectExists(ctx context.Context, id string) bool {
-	project, err := svc.store.Project.GetProjectByID(ctx, id)
+
+func (svc *ProjectService) AddMember(ctx context.Context, projectID, userID string, params types.AddMemberParams) error {
+	project, err := authorizeProjectUpdate(ctx, svc.store, projectID, userID, types.ProjectRoleOwner)
+	if err != nil {
+		return err
+	}
+	member, err := svc.store.User.GetUserByEmail(ctx, params.Email)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if _, ok := project.GetRole(member.ID); ok {
+		return ErrMemberAlreadyExists
+	}
+	if len(project.Members) == 0 {
+		members := map[string]types.ProjectRole{
+			project.UserID: types.ProjectRoleOwner,
+			member.ID:      params.Role,
+		}
+		return svc.store.Project.Update(ctx, projectID, db.ProjectMembersUpdater{Members: members})
+	}
+	return svc.store.Project.Update(ctx, projectID, db.ProjectMemberUpdater{UserID: member.ID, Role: params.Role})
+}
+
+func (svc *ProjectService) UpdateMemberRole(ctx context.Context, projectID, userID, memberID string, params types.UpdateMemberRoleParams) error {
+	project, err := authorizeProjectUpdate(ctx, svc.store, projectID, userID, types.ProjectRoleOwner)
+	if err != nil {
+		return err
+	}
+	role, ok := project.GetRole(memberID)
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if role == params.Role {
+		return ErrMemberRoleUnchanged
+	}
+	if role == types.ProjectRoleOwner && project.CountOwners() <= 1 {
+		return ErrLastProjectOwner
+	}
+	return svc.store.Project.Update(ctx, projectID, db.ProjectMemberUpdater{UserID: memberID, Role: params.Role})
+}
+
+// RemoveMember lets owners remove any member and any member leave the project.
+func (svc *ProjectService) RemoveMember(ctx context.Context, projectID, userID, memberID string) error {
+	requiredRole := types.ProjectRoleOwner
+	if userID == memberID {
+		requiredRole = types.ProjectRoleViewer
+	}
+	project, err := authorizeProjectUpdate(ctx, svc.store, projectID, userID, requiredRole)
+	if err != nil {
+		return err
+	}
+	role, ok := project.GetRole(memberID)
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if role == types.ProjectRoleOwner && project.CountOwners() <= 1 {
+		return ErrLastProjectOwner
+	}
+	return svc.store.Project.Update(ctx, projectID, db.ProjectMemberRemover{UserID: memberID})
+}
+
+// authorizeProject returns the project when the user holds at least the given role in it.
+// Users allowed to read any project are viewers of every project.
+func authorizeProject(ctx context.Context, store *db.Store, projectID, userID string, role types.ProjectRole) (*types.Project, error) {
+	project, err := store.Project.GetProjectByID(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) || errors.Is(err, db.ErrInvalidID) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	if project.HasRole(userID, role) {
+		return project, nil
+	}
+	if role == types.ProjectRoleViewer && canReadAnyProject(ctx, store, userID) {
+		return project, nil
+	}
+	return nil, ErrUnAuthorized
+}
+func canReadAnyProject(ctx context.Context, store *db.Store, userID string) bool {
+	user, err := store.User.GetUserByID(ctx, userID)
 	if err != nil {
 		return false
 	}
-	return project != nil
+	return DefaultPolicy.Can(user, types.PermissionProjectReadAny)
+}
+
+// authorizeProjectUpdate is like authorizeProject but also rejects archived projects.
+func authorizeProjectUpdate(ctx context.Context, store *db.Store, projectID, userID string, role types.ProjectRole) (*types.Project, error) {
+	project, err := authorizeProject(ctx, store, projectID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if project.Archived {
+		return nil, ErrProjectArchived
+	}
+	return project, nil
+}
+
+// insertProjectWithTasks inserts a new project and its tasks in a single transaction,
+// so a partially created project is never visible.
+func insertProjectWithTasks(ctx context.Context, store *db.Store, project *types.Project, tasks []*types.Task) error {
+	project.ID = store.Project.NewID()
+	actions := make([]db.DBAction, 0, len(tasks)+1)
+	for _, task := range tasks {
+		task.ID = store.Project.NewID()
+		task.ProjectID = project.ID
+		project.Tasks = append(project.Tasks, task.ID)
+		actions = append(actions, db.NewTaskInsertAction(task))
+	}
+	actions = append([]db.DBAction{db.NewProjectInsertAction(project)}, actions...)
+	if len(tasks) > 0 {
+		actions = append(actions, db.NewTaskSummaryAction(project.ID, tasks...))
+	}
+	if err := store.Project.Transact(ctx, actions); err != nil {
+		if errors.Is(err, db.ErrInvalidBatchSize) {
+			return ErrTooManyTasks
+		}
+		return err
+	}
+	return nil
 }
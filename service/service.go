@@ -1,19 +1,35 @@
 package service
 
-import "github.com/ficontini/gotasks/db"
+import (
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/oidc"
+)
 
 type Service struct {
-	Auth    AuthServicer
-	User    UserServicer
-	Task    TaskServicer
-	Project ProjectServicer
+	Auth      AuthServicer
+	SSO       SSOServicer
+	User      UserServicer
+	Task      TaskServicer
+	Project   ProjectServicer
+	Milestone MilestoneServicer
+	Template  TemplateServicer
+	Team      TeamServicer
+	Workspace WorkspaceServicer
+	Policy    *Policy
 }
 
-func NewService(store *db.Store) *Service {
+func NewService(store *db.Store, mailer mailer.Mailer, provider *oidc.Provider) *Service {
 	return &Service{
-		Auth:    NewAuthLogMiddleware(NewAuthService(store)),
-		User:    NewUserLogMiddleware(NewUserService(store)),
-		Task:    NewTaskLogMiddleware(NewTaskService(store)),
-		Project: NewProjectLogMiddleware(NewProjectService(store)),
+		Auth:      NewAuthLogMiddleware(NewAuthService(store, mailer)),
+		SSO:       NewSSOLogMiddleware(NewSSOService(store, provider)),
+		User:      NewUserLogMiddleware(NewUserService(store, mailer)),
+		Task:      NewTaskLogMiddleware(NewTaskService(store)),
+		Project:   NewProjectLogMiddleware(NewProjectService(store)),
+		Milestone: NewMilestoneLogMiddleware(NewMilestoneService(store)),
+		Template:  NewTemplateLogMiddleware(NewTemplateService(store)),
+		Team:      NewTeamLogMiddleware(NewTeamService(store)),
+		Workspace: NewWorkspaceLogMiddleware(NewWorkspaceService(store, mailer, DefaultPolicy)),
+		Policy:    DefaultPolicy,
 	}
 }
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ficontini/gotasks/types"
+	"github.com/sirupsen/logrus"
+)
+
+type SSOLogMiddleware struct {
+	next SSOServicer
+}
+
+func NewSSOLogMiddleware(next SSOServicer) SSOServicer {
+	return &SSOLogMiddleware{
+		next: next,
+	}
+}
+
+func (m *SSOLogMiddleware) StartSSO() (login *types.SSOLogin, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to start single sign-on")
+		}
+	}(time.Now())
+	login, err = m.next.StartSSO()
+	return login, err
+}
+func (m *SSOLogMiddleware) CompleteSSO(ctx context.Context, params *types.SSOCallbackParams) (auth *types.Auth, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to complete single sign-on")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": auth.UserID,
+				"took":   time.Since(start),
+			}).Info("CompleteSSO successfully completed")
+		}
+	}(time.Now())
+	auth, err = m.next.CompleteSSO(ctx, params)
+	return auth, err
+}
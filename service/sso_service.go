@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/oidc"
+	"github.com/ficontini/gotasks/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ssoStateTTL      = 10 * time.Minute
+	ssoStateAudience = "sso-state"
+)
+
+type SSOServicer interface {
+	StartSSO() (*types.SSOLogin, error)
+	CompleteSSO(context.Context, *types.SSOCallbackParams) (*types.Auth, error)
+}
+
+type SSOService struct {
+	store    *db.Store
+	provider *oidc.Provider
+}
+
+// NewSSOService returns a service that refuses every sign in when provider is nil,
+// that is when single sign-on is not configured.
+func NewSSOService(store *db.Store, provider *oidc.Provider) SSOServicer {
+	return &SSOService{
+		store:    store,
+		provider: provider,
+	}
+}
+
+// ssoStateClaims keep the state, nonce and PKCE verifier of a sign in in the user
+// browser, so nothing has to be stored until it comes back.
+type ssoStateClaims struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+func (svc *SSOService) StartSSO() (*types.SSOLogin, error) {
+	if svc.provider == nil {
+		return nil, ErrSSONotConfigured
+	}
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	claims := ssoStateClaims{
+		State:    values[0],
+		Nonce:    values[1],
+		Verifier: values[2],
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{ssoStateAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ssoStateTTL)),
+		},
+	}
+	stateToken, err := signWithDerivedKey(claims, ssoStateAudience)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SSOLogin{
+		URL:        svc.provider.AuthCodeURL(claims.State, claims.Nonce, claims.Verifier),
+		StateToken: stateToken,
+	}, nil
+}
+
+// CompleteSSO redeems the authorization code and signs in the user with the email
+// of the ID token, linking the account to the provider subject the first time.
+// Users without an account are created when the provider config allows it.
+func (svc *SSOService) CompleteSSO(ctx context.Context, params *types.SSOCallbackParams) (*types.Auth, error) {
+	if svc.provider == nil {
+		return nil, ErrSSONotConfigured
+	}
+	state := &ssoStateClaims{}
+	_, err := jwt.ParseWithClaims(params.StateToken, state, func(token *jwt.Token) (interface{}, error) {
+		return deriveKey(ssoStateAudience)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(ssoStateAudience))
+	if err != nil || subtle.ConstantTimeCompare([]byte(state.State), []byte(params.State)) != 1 {
+		return nil, ErrInvalidSSOState
+	}
+	claims, err := svc.provider.Exchange(ctx, params.Code, state.Verifier, state.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			return nil, ErrInvalidSSOIdentity
+		}
+		return nil, err
+	}
+	if len(claims.Email) == 0 || !claims.EmailVerified {
+		return nil, ErrInvalidSSOIdentity
+	}
+	user, err := svc.getOrCreateUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Enabled {
+		return nil, ErrForbidden
+	}
+	auth := types.NewAuth(user.ID)
+	auth.IP = params.IP
+	auth.UserAgent = params.UserAgent
+	return svc.store.Auth.Insert(ctx, auth)
+}
+func (svc *SSOService) getOrCreateUser(ctx context.Context, claims *oidc.Claims) (*types.User, error) {
+	email := strings.ToLower(claims.Email)
+	user, err := svc.store.User.GetUserByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, db.ErrorNotFound) {
+			return nil, err
+		}
+		if !svc.provider.Config().AutoCreate {
+			return nil, ErrSSOUserNotFound
+		}
+		firstName, lastName := claims.GivenName, claims.FamilyName
+		if len(firstName) == 0 {
+			firstName = email[:strings.Index(email, "@")]
+		}
+		return svc.store.User.InsertUser(ctx, types.NewSSOUser(claims.Subject, email, firstName, lastName))
+	}
+	switch user.SSOSubject {
+	case claims.Subject:
+		return user, nil
+	case "":
+		if err := svc.store.User.Update(ctx, user.ID, db.SSOLinkUpdater{Subject: claims.Subject}); err != nil {
+			return nil, err
+		}
+		user.SSOSubject = claims.Subject
+		user.Verified = true
+		return user, nil
+	default:
+		return nil, ErrInvalidSSOIdentity
+	}
+}
+
+var (
+	ErrSSONotConfigured   = errors.New("single sign-on is not configured")
+	ErrInvalidSSOState    = errors.New("single sign-on state is invalid or expired")
+	ErrInvalidSSOIdentity = errors.New("identity provider did not return a valid identity for this account")
+	ErrSSOUserNotFound    = errors.New("no account for this identity, ask an admin to create it")
+)
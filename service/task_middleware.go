@@ -17,7 +17,7 @@ func NewTaskLogMiddleware(next TaskServicer) TaskServicer {
 		next: next,
 	}
 }
-func (m *TaskLogMiddleware) GetTaskByID(ctx context.Context, id string) (task *types.Task, err error) {
+func (m *TaskLogMiddleware) GetTaskByID(ctx context.Context, id, userID string) (task *types.Task, err error) {
 	defer func(start time.Time) {
 		if err != nil {
 			logrus.WithError(err).Error("Failed to get task")
@@ -28,7 +28,7 @@ func (m *TaskLogMiddleware) GetTaskByID(ctx context.Context, id string) (task *t
 			}).Info("Task retrieved successfully")
 		}
 	}(time.Now())
-	task, err = m.next.GetTaskByID(ctx, id)
+	task, err = m.next.GetTaskByID(ctx, id, userID)
 	return task, err
 }
 func (m *TaskLogMiddleware) CreateTask(ctx context.Context, params types.NewTaskParams) (task *types.Task, err error) {
@@ -148,3 +148,49 @@ func (m *TaskLogMiddleware) UpdateDueDate(ctx context.Context, id string, params
 	err = m.next.UpdateDueDate(ctx, id, params)
 	return err
 }
+func (m *TaskLogMiddleware) DuplicateTask(ctx context.Context, id, userID string) (task *types.Task, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to duplicate task")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"sourceID": id,
+				"taskID":   task.ID,
+				"took":     time.Since(start),
+			}).Info("Task duplicated successfully")
+		}
+	}(time.Now())
+	task, err = m.next.DuplicateTask(ctx, id, userID)
+	return task, err
+}
+
+func (m *TaskLogMiddleware) GetTeamTasksByUserID(ctx context.Context, id string, params TeamTaskQueryParams) (tasks []*types.Task, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get team tasks")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID":  id,
+				"results": len(tasks),
+				"took":    time.Since(start),
+			}).Info("GetTeamTasksByUserID successfully completed")
+		}
+	}(time.Now())
+	tasks, err = m.next.GetTeamTasksByUserID(ctx, id, params)
+	return tasks, err
+}
+func (m *TaskLogMiddleware) AssignTaskToTeam(ctx context.Context, id, userID string, params types.AssignTeamParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to assign task to team")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"taskID": id,
+				"teamID": params.TeamID,
+				"took":   time.Since(start),
+			}).Info("Task assigned to team successfully")
+		}
+	}(time.Now())
+	err = m.next.AssignTaskToTeam(ctx, id, userID, params)
+	return err
+}
@@ -3,19 +3,22 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/ficontini/gotasks/db"
 	"github.com/ficontini/gotasks/types"
 )
 
 type TaskGetter interface {
-	GetTaskByID(context.Context, string) (*types.Task, error)
+	GetTaskByID(context.Context, string, string) (*types.Task, error)
 	GetTasks(context.Context, *TaskQueryParams) ([]*types.Task, error)
 	GetTasksByUserID(context.Context, string, TaskQueryParams) ([]*types.Task, error)
+	GetTeamTasksByUserID(context.Context, string, TeamTaskQueryParams) ([]*types.Task, error)
 }
 
 type TaskCreator interface {
 	CreateTask(context.Context, types.NewTaskParams) (*types.Task, error)
+	DuplicateTask(context.Context, string, string) (*types.Task, error)
 }
 
 type TaskDeleter interface {
@@ -29,6 +32,7 @@ type TaskUpdater interface {
 type TaskAssigner interface {
 	AssignTaskToSelf(context.Context, types.UpdateTaskRequest) error
 	AssignTaskToUser(context.Context, types.UpdateTaskRequest) error
+	AssignTaskToTeam(context.Context, string, string, types.AssignTeamParams) error
 }
 
 type TaskServicer interface {
@@ -49,12 +53,12 @@ func NewTaskService(store *db.Store) TaskServicer {
 	}
 }
 
-func (svc *TaskService) GetTaskByID(ctx context.Context, id string) (*types.Task, error) {
-	task, err := svc.store.Task.GetTaskByID(ctx, id)
+func (svc *TaskService) GetTaskByID(ctx context.Context, id, userID string) (*types.Task, error) {
+	task, err := svc.getTask(ctx, id)
 	if err != nil {
-		if errors.Is(err, db.ErrorNotFound) {
-			return nil, ErrTaskNotFound
-		}
+		return nil, err
+	}
+	if err := svc.authorizeTask(ctx, task, userID, types.ProjectRoleViewer); err != nil {
 		return nil, err
 	}
 	return task, nil
@@ -65,6 +69,31 @@ func (svc *TaskService) CreateTask(ctx context.Context, params types.NewTaskPara
 	return insertedTask, err
 }
 
+// DuplicateTask copies the task, adding the copy to the task's project in the same transaction.
+func (svc *TaskService) DuplicateTask(ctx context.Context, id, userID string) (*types.Task, error) {
+	task, err := svc.getTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.authorizeTaskUpdate(ctx, task, userID, types.ProjectRoleEditor); err != nil {
+		return nil, err
+	}
+	duplicate := task.Duplicate()
+	duplicate.ID = svc.store.Project.NewID()
+	actions := []db.DBAction{db.NewTaskInsertAction(duplicate)}
+	if len(duplicate.ProjectID) > 0 {
+		action, err := db.NewProjectUpdateAction(duplicate.ProjectID, db.AddTaskToProjectUpdater{TaskID: duplicate.ID})
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action, db.NewTaskSummaryAction(duplicate.ProjectID, duplicate))
+	}
+	if err := svc.store.Project.Transact(ctx, actions); err != nil {
+		return nil, err
+	}
+	return duplicate, nil
+}
+
 type TaskQueryParams struct {
 	db.Pagination
 	Completed *bool
@@ -79,8 +108,33 @@ func (svc *TaskService) GetTasksByUserID(ctx context.Context, id string, params
 	filter := db.NewUserTasksFilter(params.Completed, id)
 	return svc.store.Task.GetTasks(ctx, filter, &params.Pagination)
 }
+
+// GetTeamTasksByUserID returns the tasks of every team the user is a member of.
+func (svc *TaskService) GetTeamTasksByUserID(ctx context.Context, id string, params TeamTaskQueryParams) ([]*types.Task, error) {
+	teams, err := getAllTeams(ctx, svc.store, db.NewUserTeamsFilter(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(teams) == 0 {
+		return []*types.Task{}, nil
+	}
+	teamIDs := make([]string, 0, len(teams))
+	for _, team := range teams {
+		teamIDs = append(teamIDs, team.ID)
+	}
+	filter := db.NewTeamTasksFilter(teamIDs, params.Completed, params.Unassigned)
+	return svc.store.Task.GetTasks(ctx, filter, &params.Pagination)
+}
 func (svc *TaskService) DeleteTask(ctx context.Context, id string) error {
-	if err := svc.store.Task.Delete(ctx, id); err != nil {
+	task, err := svc.getTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	actions := []db.DBAction{db.NewTaskDeleteAction(task.ID)}
+	if len(task.ProjectID) > 0 {
+		actions = append(actions, db.NewTaskSummaryRemoveAction(task.ProjectID, task.ID))
+	}
+	if err := svc.store.Project.Transact(ctx, actions); err != nil {
 		if errors.Is(err, db.ErrorNotFound) {
 			return ErrTaskNotFound
 		}
@@ -97,11 +151,36 @@ func (svc *TaskService) CompleteTask(ctx context.Context, params types.UpdateTas
 	if task.AssignedTo != params.UserID {
 		return ErrUnAuthorized
 	}
+	if err := svc.authorizeTaskUpdate(ctx, task, params.UserID, types.ProjectRoleEditor); err != nil {
+		return err
+	}
 	if task.Completed {
 		return ErrTaskAlreadyCompleted
 	}
-	update := db.TaskCompleteUpdater{Completed: true}
-	return svc.store.Task.Update(ctx, task.ID, update)
+	update := db.TaskCompleteUpdater{Completed: true, CompletedAt: time.Now().UTC()}
+	task.Completed, task.CompletedAt = update.Completed, &update.CompletedAt
+	return updateTask(ctx, svc.store, task, update)
+}
+
+// updateTask applies the update to the task, which the caller has already changed
+// the same way, refreshing its summary in the stats of its project in the same
+// transaction.
+func updateTask(ctx context.Context, store *db.Store, task *types.Task, update db.Update) error {
+	action, err := db.NewTaskUpdateAction(task.ID, update)
+	if err != nil {
+		return err
+	}
+	actions := []db.DBAction{action}
+	if len(task.ProjectID) > 0 {
+		actions = append(actions, db.NewTaskSummaryAction(task.ProjectID, task))
+	}
+	if err := store.Project.Transact(ctx, actions); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+	return nil
 }
 func (svc *TaskService) getTask(ctx context.Context, id string) (*types.Task, error) {
 	task, err := svc.store.Task.GetTaskByID(ctx, id)
@@ -114,42 +193,90 @@ func (svc *TaskService) getTask(ctx context.Context, id string) (*types.Task, er
 	return task, nil
 }
 func (svc *TaskService) AssignTaskToSelf(ctx context.Context, req types.UpdateTaskRequest) error {
-	return svc.assignTask(ctx, req.TaskID, req.UserID)
+	task, err := svc.getTask(ctx, req.TaskID)
+	if err != nil {
+		return err
+	}
+	if err := svc.authorizeTaskUpdate(ctx, task, req.UserID, types.ProjectRoleEditor); err != nil {
+		return err
+	}
+	if len(task.TeamID) > 0 {
+		if _, err := authorizeTeam(ctx, svc.store, task.TeamID, req.UserID); err != nil {
+			return err
+		}
+	}
+	return svc.assignTask(ctx, task, req.UserID)
+}
+
+// AssignTaskToTeam hands the task over to a team of the user, where any member can
+// pick it up.
+func (svc *TaskService) AssignTaskToTeam(ctx context.Context, id, userID string, params types.AssignTeamParams) error {
+	task, err := svc.getTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := svc.authorizeTaskUpdate(ctx, task, userID, types.ProjectRoleEditor); err != nil {
+		return err
+	}
+	if _, err := authorizeTeam(ctx, svc.store, params.TeamID, userID); err != nil {
+		return err
+	}
+	task.TeamID, task.AssignedTo = params.TeamID, ""
+	return updateTask(ctx, svc.store, task, db.TaskTeamUpdater{TeamID: params.TeamID})
+}
+
+// authorizeTask checks the user's role in the task's project, if it belongs to one.
+func (svc *TaskService) authorizeTask(ctx context.Context, task *types.Task, userID string, role types.ProjectRole) error {
+	if len(task.ProjectID) == 0 {
+		return nil
+	}
+	_, err := authorizeProject(ctx, svc.store, task.ProjectID, userID, role)
+	return err
+}
+func (svc *TaskService) authorizeTaskUpdate(ctx context.Context, task *types.Task, userID string, role types.ProjectRole) error {
+	if len(task.ProjectID) == 0 {
+		return nil
+	}
+	_, err := authorizeProjectUpdate(ctx, svc.store, task.ProjectID, userID, role)
+	return err
 }
+
+// AssignTaskToUser assigns the task to a user of the same workspace, as both are
+// looked up in the workspace of the context, who must be a member of its project.
 func (svc *TaskService) AssignTaskToUser(ctx context.Context, req types.UpdateTaskRequest) error {
+	task, err := svc.getTask(ctx, req.TaskID)
+	if err != nil {
+		return err
+	}
 	if _, err := svc.store.User.GetUserByID(ctx, req.UserID); err != nil {
 		if errors.Is(err, db.ErrorNotFound) {
 			return ErrUserNotFound
 		}
+		return err
 	}
-	return svc.assignTask(ctx, req.TaskID, req.UserID)
-}
-func (svc *TaskService) assignTask(ctx context.Context, taskID, userID string) error {
-	if err := svc.store.Task.Update(ctx, taskID, db.TaskAssignationUpdater{AssignedTo: userID}); err != nil {
-		if errors.Is(err, db.ErrorNotFound) {
-			return ErrTaskNotFound
-		}
+	if err := svc.authorizeTaskUpdate(ctx, task, req.UserID, types.ProjectRoleViewer); err != nil {
 		return err
 	}
-	return nil
-
+	return svc.assignTask(ctx, task, req.UserID)
+}
+func (svc *TaskService) assignTask(ctx context.Context, task *types.Task, userID string) error {
+	task.AssignedTo = userID
+	return updateTask(ctx, svc.store, task, db.TaskAssignationUpdater{AssignedTo: userID})
 }
 
 func (svc *TaskService) UpdateDueDate(ctx context.Context, id string, params types.UpdateDueDateTaskRequest) error {
-	task, err := svc.store.Task.GetTaskByID(ctx, id)
+	task, err := svc.getTask(ctx, id)
 	if err != nil {
 		return err
 	}
 	if task.AssignedTo != params.AssignedTo {
 		return ErrUnAuthorized
 	}
-	if err := svc.store.Task.Update(ctx, id, db.TaskDueDateUpdater{DueDate: params.DueDate}); err != nil {
-		if errors.Is(err, db.ErrorNotFound) {
-			return ErrTaskNotFound
-		}
+	if err := svc.authorizeTaskUpdate(ctx, task, params.AssignedTo, types.ProjectRoleEditor); err != nil {
 		return err
 	}
-	return nil
+	task.DueDate = params.DueDate
+	return updateTask(ctx, svc.store, task, db.TaskDueDateUpdater{DueDate: params.DueDate})
 }
 
 var (
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/types"
+	"github.com/sirupsen/logrus"
+)
+
+type TeamLogMiddleware struct {
+	next TeamServicer
+}
+
+func NewTeamLogMiddleware(next TeamServicer) TeamServicer {
+	return &TeamLogMiddleware{
+		next: next,
+	}
+}
+
+func (m *TeamLogMiddleware) CreateTeam(ctx context.Context, userID string, params types.NewTeamParams) (team *types.Team, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create team")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"teamID": team.ID,
+				"userID": userID,
+				"took":   time.Since(start),
+			}).Info("Team created successfully")
+		}
+	}(time.Now())
+	team, err = m.next.CreateTeam(ctx, userID, params)
+	return team, err
+}
+func (m *TeamLogMiddleware) GetTeamByID(ctx context.Context, id, userID string) (team *types.Team, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get team")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"teamID": id,
+				"took":   time.Since(start),
+			}).Info("GetTeamByID successfully completed")
+		}
+	}(time.Now())
+	team, err = m.next.GetTeamByID(ctx, id, userID)
+	return team, err
+}
+func (m *TeamLogMiddleware) GetTeams(ctx context.Context, userID string, pagination db.Pagination) (teams []*types.Team, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get teams")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID":  userID,
+				"results": len(teams),
+				"took":    time.Since(start),
+			}).Info("GetTeams successfully completed")
+		}
+	}(time.Now())
+	teams, err = m.next.GetTeams(ctx, userID, pagination)
+	return teams, err
+}
+func (m *TeamLogMiddleware) GetTeamTasks(ctx context.Context, id, userID string, params TeamTaskQueryParams) (tasks []*types.Task, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get team tasks")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"teamID":  id,
+				"results": len(tasks),
+				"took":    time.Since(start),
+			}).Info("GetTeamTasks successfully completed")
+		}
+	}(time.Now())
+	tasks, err = m.next.GetTeamTasks(ctx, id, userID, params)
+	return tasks, err
+}
+func (m *TeamLogMiddleware) UpdateTeam(ctx context.Context, id, userID string, params types.UpdateTeamParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to update team")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"teamID": id,
+				"took":   time.Since(start),
+			}).Info("Team updated successfully")
+		}
+	}(time.Now())
+	err = m.next.UpdateTeam(ctx, id, userID, params)
+	return err
+}
+func (m *TeamLogMiddleware) DeleteTeam(ctx context.Context, id, userID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to delete team")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"teamID": id,
+				"took":   time.Since(start),
+			}).Info("Team deleted successfully")
+		}
+	}(time.Now())
+	err = m.next.DeleteTeam(ctx, id, userID)
+	return err
+}
+func (m *TeamLogMiddleware) AddMember(ctx context.Context, id, userID string, params types.AddTeamMemberParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to add team member")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"teamID": id,
+				"email":  params.Email,
+				"took":   time.Since(start),
+			}).Info("Team member added successfully")
+		}
+	}(time.Now())
+	err = m.next.AddMember(ctx, id, userID, params)
+	return err
+}
+func (m *TeamLogMiddleware) RemoveMember(ctx context.Context, id, userID, memberID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to remove team member")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"teamID":   id,
+				"memberID": memberID,
+				"took":     time.Since(start),
+			}).Info("Team member removed successfully")
+		}
+	}(time.Now())
+	err = m.next.RemoveMember(ctx, id, userID, memberID)
+	return err
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/types"
+)
+
+var (
+	ErrTeamNotFound            = errors.New("team resource not found")
+	ErrTeamMemberAlreadyExists = errors.New("user is already a member of this team")
+	ErrTeamMemberNotFound      = errors.New("team member not found")
+	ErrTeamLeadCannotLeave     = errors.New("team lead can't leave the team, hand it over first")
+)
+
+type TeamGetter interface {
+	GetTeamByID(context.Context, string, string) (*types.Team, error)
+	GetTeams(context.Context, string, db.Pagination) ([]*types.Team, error)
+	GetTeamTasks(context.Context, string, string, TeamTaskQueryParams) ([]*types.Task, error)
+}
+type TeamCreator interface {
+	CreateTeam(context.Context, string, types.NewTeamParams) (*types.Team, error)
+}
+type TeamUpdater interface {
+	UpdateTeam(context.Context, string, string, types.UpdateTeamParams) error
+}
+type TeamDeleter interface {
+	DeleteTeam(context.Context, string, string) error
+}
+type TeamMemberManager interface {
+	AddMember(context.Context, string, string, types.AddTeamMemberParams) error
+	RemoveMember(context.Context, string, string, string) error
+}
+type TeamServicer interface {
+	TeamGetter
+	TeamCreator
+	TeamUpdater
+	TeamDeleter
+	TeamMemberManager
+}
+
+type TeamService struct {
+	store *db.Store
+}
+
+func NewTeamService(store *db.Store) TeamServicer {
+	return &TeamService{
+		store: store,
+	}
+}
+
+func (svc *TeamService) CreateTeam(ctx context.Context, userID string, params types.NewTeamParams) (*types.Team, error) {
+	return svc.store.Team.InsertTeam(ctx, types.NewTeamFromParams(userID, params))
+}
+func (svc *TeamService) GetTeamByID(ctx context.Context, id, userID string) (*types.Team, error) {
+	return authorizeTeam(ctx, svc.store, id, userID)
+}
+func (svc *TeamService) GetTeams(ctx context.Context, userID string, pagination db.Pagination) ([]*types.Team, error) {
+	return svc.store.Team.GetTeams(ctx, db.NewUserTeamsFilter(userID), &pagination)
+}
+
+type TeamTaskQueryParams struct {
+	TaskQueryParams
+	Unassigned bool
+}
+
+func (svc *TeamService) GetTeamTasks(ctx context.Context, id, userID string, params TeamTaskQueryParams) ([]*types.Task, error) {
+	if _, err := authorizeTeam(ctx, svc.store, id, userID); err != nil {
+		return nil, err
+	}
+	filter := db.NewTeamTasksFilter([]string{id}, params.Completed, params.Unassigned)
+	return svc.store.Task.GetTasks(ctx, filter, &params.Pagination)
+}
+
+// UpdateTeam lets the lead rename the team or hand it over to another member.
+func (svc *TeamService) UpdateTeam(ctx context.Context, id, userID string, params types.UpdateTeamParams) error {
+	team, err := authorizeTeamLead(ctx, svc.store, id, userID)
+	if err != nil {
+		return err
+	}
+	if len(params.LeadID) > 0 && !team.IsMember(params.LeadID) {
+		return ErrTeamMemberNotFound
+	}
+	update := db.TeamUpdater{
+		Name:        params.Name,
+		Description: params.Description,
+		LeadID:      params.LeadID,
+	}
+	return svc.store.Team.Update(ctx, id, update)
+}
+
+// DeleteTeam removes the team and releases its tasks in a single transaction. Tasks
+// a member already picked up stay assigned to that member.
+func (svc *TeamService) DeleteTeam(ctx context.Context, id, userID string) error {
+	team, err := authorizeTeamLead(ctx, svc.store, id, userID)
+	if err != nil {
+		return err
+	}
+	return deleteTeam(ctx, svc.store, team)
+}
+func (svc *TeamService) AddMember(ctx context.Context, id, userID string, params types.AddTeamMemberParams) error {
+	team, err := authorizeTeamLead(ctx, svc.store, id, userID)
+	if err != nil {
+		return err
+	}
+	member, err := svc.store.User.GetUserByEmail(ctx, params.Email)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if team.IsMember(member.ID) {
+		return ErrTeamMemberAlreadyExists
+	}
+	members := append(team.Members, member.ID)
+	return svc.store.Team.Update(ctx, id, db.TeamMembersUpdater{Members: members})
+}
+
+// RemoveMember lets the lead remove any member and any member leave the team.
+func (svc *TeamService) RemoveMember(ctx context.Context, id, userID, memberID string) error {
+	var (
+		team *types.Team
+		err  error
+	)
+	if userID == memberID {
+		team, err = authorizeTeam(ctx, svc.store, id, userID)
+	} else {
+		team, err = authorizeTeamLead(ctx, svc.store, id, userID)
+	}
+	if err != nil {
+		return err
+	}
+	if !team.IsMember(memberID) {
+		return ErrTeamMemberNotFound
+	}
+	if team.LeadID == memberID {
+		return ErrTeamLeadCannotLeave
+	}
+	return svc.store.Team.Update(ctx, id, db.TeamMembersUpdater{Members: removeMember(team.Members, memberID)})
+}
+
+func getTeam(ctx context.Context, store *db.Store, id string) (*types.Team, error) {
+	team, err := store.Team.GetTeamByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) || errors.Is(err, db.ErrInvalidID) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+	return team, nil
+}
+
+// authorizeTeam returns the team when the user is one of its members.
+func authorizeTeam(ctx context.Context, store *db.Store, id, userID string) (*types.Team, error) {
+	team, err := getTeam(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+	if !team.IsMember(userID) {
+		return nil, ErrUnAuthorized
+	}
+	return team, nil
+}
+func authorizeTeamLead(ctx context.Context, store *db.Store, id, userID string) (*types.Team, error) {
+	team, err := getTeam(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+	if team.LeadID != userID {
+		return nil, ErrUnAuthorized
+	}
+	return team, nil
+}
+func deleteTeam(ctx context.Context, store *db.Store, team *types.Team) error {
+	tasks, err := getAllTasks(ctx, store, db.NewTeamTasksFilter([]string{team.ID}, nil, false))
+	if err != nil {
+		return err
+	}
+	actions := make([]db.DBAction, 0, len(tasks)+1)
+	for _, task := range tasks {
+		action, err := db.NewTaskUpdateAction(task.ID, db.TaskTeamRemover{})
+		if err != nil {
+			return err
+		}
+		actions = append(actions, action)
+	}
+	actions = append(actions, db.NewTeamDeleteAction(team.ID))
+	return store.Project.Transact(ctx, actions)
+}
+
+// leaveTeam removes the user from the team, handing the lead over to the next member,
+// or deletes the team when the user is the last one.
+func leaveTeam(ctx context.Context, store *db.Store, team *types.Team, userID string) error {
+	members := removeMember(team.Members, userID)
+	if len(members) == 0 {
+		return deleteTeam(ctx, store, team)
+	}
+	if team.LeadID == userID {
+		if err := store.Team.Update(ctx, team.ID, db.TeamUpdater{LeadID: members[0]}); err != nil {
+			return err
+		}
+	}
+	return store.Team.Update(ctx, team.ID, db.TeamMembersUpdater{Members: members})
+}
+func getAllTeams(ctx context.Context, store *db.Store, filter db.Filter) ([]*types.Team, error) {
+	var teams []*types.Team
+	for page := int64(1); ; page++ {
+		pagination := db.Pagination{Page: page, Limit: maxPageLimit}
+		batch, err := store.Team.GetTeams(ctx, filter, &pagination)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, batch...)
+		if len(batch) < maxPageLimit {
+			return teams, nil
+		}
+	}
+}
+func removeMember(members []string, userID string) []string {
+	remaining := make([]string, 0, len(members))
+	for _, member := range members {
+		if member != userID {
+			remaining = append(remaining, member)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ficontini/gotasks/types"
+	"github.com/sirupsen/logrus"
+)
+
+type TemplateLogMiddleware struct {
+	next TemplateServicer
+}
+
+func NewTemplateLogMiddleware(next TemplateServicer) TemplateServicer {
+	return &TemplateLogMiddleware{
+		next: next,
+	}
+}
+
+func (m *TemplateLogMiddleware) GetTemplateByID(ctx context.Context, id, userID string) (template *types.Template, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get template")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"templateID": id,
+				"took":       time.Since(start),
+			}).Info("Template retrieved successfully")
+		}
+	}(time.Now())
+	template, err = m.next.GetTemplateByID(ctx, id, userID)
+	return template, err
+}
+func (m *TemplateLogMiddleware) GetTemplates(ctx context.Context, userID string, params TemplateQueryParams) (templates []*types.Template, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get templates")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": userID,
+				"took":   time.Since(start),
+			}).Info("Get templates")
+		}
+	}(time.Now())
+	templates, err = m.next.GetTemplates(ctx, userID, params)
+	return templates, err
+}
+func (m *TemplateLogMiddleware) CreateProjectTemplate(ctx context.Context, projectID, userID string, params types.NewTemplateParams) (template *types.Template, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create project template")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"templateID": template.ID,
+				"projectID":  projectID,
+				"took":       time.Since(start),
+			}).Info("Project template created successfully")
+		}
+	}(time.Now())
+	template, err = m.next.CreateProjectTemplate(ctx, projectID, userID, params)
+	return template, err
+}
+func (m *TemplateLogMiddleware) CreateTaskTemplate(ctx context.Context, taskID, userID string, params types.NewTemplateParams) (template *types.Template, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create task template")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"templateID": template.ID,
+				"taskID":     taskID,
+				"took":       time.Since(start),
+			}).Info("Task template created successfully")
+		}
+	}(time.Now())
+	template, err = m.next.CreateTaskTemplate(ctx, taskID, userID, params)
+	return template, err
+}
+func (m *TemplateLogMiddleware) DeleteTemplate(ctx context.Context, id, userID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to delete template")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"templateID": id,
+				"took":       time.Since(start),
+			}).Info("Template deleted successfully")
+		}
+	}(time.Now())
+	err = m.next.DeleteTemplate(ctx, id, userID)
+	return err
+}
+func (m *TemplateLogMiddleware) InstantiateProject(ctx context.Context, id, userID string, params types.InstantiateTemplateParams) (project *types.Project, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to instantiate project template")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"templateID": id,
+				"projectID":  project.ID,
+				"took":       time.Since(start),
+			}).Info("Project created from template successfully")
+		}
+	}(time.Now())
+	project, err = m.next.InstantiateProject(ctx, id, userID, params)
+	return project, err
+}
+func (m *TemplateLogMiddleware) InstantiateTask(ctx context.Context, id, userID string, params types.InstantiateTemplateParams) (task *types.Task, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to instantiate task template")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"templateID": id,
+				"taskID":     task.ID,
+				"took":       time.Since(start),
+			}).Info("Task created from template successfully")
+		}
+	}(time.Now())
+	task, err = m.next.InstantiateTask(ctx, id, userID, params)
+	return task, err
+}
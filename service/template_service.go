@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/types"
+)
+
+var (
+	ErrTemplateNotFound     = errors.New("template resource not found")
+	ErrTemplateKindMismatch = errors.New("template is not of the requested kind")
+)
+
+type TemplateGetter interface {
+	GetTemplateByID(context.Context, string, string) (*types.Template, error)
+	GetTemplates(context.Context, string, TemplateQueryParams) ([]*types.Template, error)
+}
+type TemplateCreator interface {
+	CreateProjectTemplate(context.Context, string, string, types.NewTemplateParams) (*types.Template, error)
+	CreateTaskTemplate(context.Context, string, string, types.NewTemplateParams) (*types.Template, error)
+}
+type TemplateDeleter interface {
+	DeleteTemplate(context.Context, string, string) error
+}
+type TemplateInstantiator interface {
+	InstantiateProject(context.Context, string, string, types.InstantiateTemplateParams) (*types.Project, error)
+	InstantiateTask(context.Context, string, string, types.InstantiateTemplateParams) (*types.Task, error)
+}
+type TemplateServicer interface {
+	TemplateGetter
+	TemplateCreator
+	TemplateDeleter
+	TemplateInstantiator
+}
+
+type TemplateService struct {
+	store *db.Store
+}
+
+func NewTemplateService(store *db.Store) TemplateServicer {
+	return &TemplateService{
+		store: store,
+	}
+}
+
+type TemplateQueryParams struct {
+	db.Pagination
+	Kind types.TemplateKind
+}
+
+func (svc *TemplateService) GetTemplateByID(ctx context.Context, id, userID string) (*types.Template, error) {
+	template, err := svc.store.Template.GetTemplateByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) || errors.Is(err, db.ErrInvalidID) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, ErrUnAuthorized
+	}
+	return template, nil
+}
+func (svc *TemplateService) GetTemplates(ctx context.Context, userID string, params TemplateQueryParams) ([]*types.Template, error) {
+	filter := db.NewUserTemplatesFilter(userID, params.Kind)
+	return svc.store.Template.GetTemplates(ctx, filter, &params.Pagination)
+}
+func (svc *TemplateService) CreateProjectTemplate(ctx context.Context, projectID, userID string, params types.NewTemplateParams) (*types.Template, error) {
+	project, err := authorizeProject(ctx, svc.store, projectID, userID, types.ProjectRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := getAllTasks(ctx, svc.store, db.NewProjectTasksFilter(project.ID, nil, ""))
+	if err != nil {
+		return nil, err
+	}
+	template := types.NewProjectTemplate(project, tasks, userID, params)
+	return svc.store.Template.InsertTemplate(ctx, template)
+}
+func (svc *TemplateService) CreateTaskTemplate(ctx context.Context, taskID, userID string, params types.NewTemplateParams) (*types.Template, error) {
+	task, err := svc.store.Task.GetTaskByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) || errors.Is(err, db.ErrInvalidID) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	if len(task.ProjectID) > 0 {
+		if _, err := authorizeProject(ctx, svc.store, task.ProjectID, userID, types.ProjectRoleViewer); err != nil {
+			return nil, err
+		}
+	}
+	template := types.NewTaskTemplate(task, userID, params)
+	return svc.store.Template.InsertTemplate(ctx, template)
+}
+func (svc *TemplateService) DeleteTemplate(ctx context.Context, id, userID string) error {
+	if _, err := svc.GetTemplateByID(ctx, id, userID); err != nil {
+		return err
+	}
+	return svc.store.Template.Delete(ctx, id)
+}
+
+// InstantiateProject creates a project owned by the user with the template tasks,
+// due the template offsets after the given start date.
+func (svc *TemplateService) InstantiateProject(ctx context.Context, id, userID string, params types.InstantiateTemplateParams) (*types.Project, error) {
+	template, err := svc.getTemplateOfKind(ctx, id, userID, types.ProjectTemplate)
+	if err != nil {
+		return nil, err
+	}
+	project := types.NewProjectFromParams(types.NewProjectParams{
+		Name:        valueOrDefault(params.Name, template.Name),
+		Description: valueOrDefault(params.Description, template.Description),
+	})
+	project.UserID = userID
+	project.Members[userID] = types.ProjectRoleOwner
+	tasks := make([]*types.Task, 0, len(template.Tasks))
+	for _, templateTask := range template.Tasks {
+		tasks = append(tasks, templateTask.NewTask(params.StartDate))
+	}
+	if err := insertProjectWithTasks(ctx, svc.store, project, tasks); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+func (svc *TemplateService) InstantiateTask(ctx context.Context, id, userID string, params types.InstantiateTemplateParams) (*types.Task, error) {
+	template, err := svc.getTemplateOfKind(ctx, id, userID, types.TaskTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if len(template.Tasks) == 0 {
+		return nil, ErrTemplateKindMismatch
+	}
+	task := template.Tasks[0].NewTask(params.StartDate)
+	task.Name = valueOrDefault(params.Name, task.Name)
+	task.Description = valueOrDefault(params.Description, task.Description)
+	return svc.store.Task.InsertTask(ctx, task)
+}
+func (svc *TemplateService) getTemplateOfKind(ctx context.Context, id, userID string, kind types.TemplateKind) (*types.Template, error) {
+	template, err := svc.GetTemplateByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if template.Kind != kind {
+		return nil, ErrTemplateKindMismatch
+	}
+	return template, nil
+}
+
+func valueOrDefault(value, def string) string {
+	if len(value) > 0 {
+		return value
+	}
+	return def
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	challengeTokenTTL = 5 * time.Minute
+	challengeAudience = "2fa-challenge"
+)
+
+// newChallengeToken proves the user signed in with its password, so the sign in
+// can be completed with the second factor only.
+func newChallengeToken(user *types.User) (string, error) {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   user.ID,
+		Audience:  jwt.ClaimStrings{challengeAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(challengeTokenTTL)),
+	}
+	return signWithDerivedKey(claims, challengeAudience)
+}
+func parseChallengeToken(tokenStr string) (*jwt.RegisteredClaims, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return deriveKey(challengeAudience)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(challengeAudience))
+	if err != nil {
+		return nil, ErrInvalidChallengeToken
+	}
+	return claims, nil
+}
+
+// verifyTOTP checks the code and records its step, so it can't be used again.
+func verifyTOTP(ctx context.Context, store *db.Store, user *types.User, code string) (int64, error) {
+	step, ok := types.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return 0, ErrInvalidTwoFactorCode
+	}
+	if user.TOTPEnabled {
+		if err := store.User.Update(ctx, user.ID, db.TOTPStepUpdater{LastStep: step}); err != nil {
+			return 0, err
+		}
+	}
+	return step, nil
+}
+
+// verifySecondFactor accepts a TOTP code or one of the user recovery codes, which is
+// removed once used.
+func verifySecondFactor(ctx context.Context, store *db.Store, user *types.User, code string) error {
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	code = strings.ToLower(strings.TrimSpace(code))
+	if _, err := verifyTOTP(ctx, store, user, code); err == nil || !errors.Is(err, ErrInvalidTwoFactorCode) {
+		return err
+	}
+	hash := types.HashToken(code)
+	for i, recoveryCode := range user.RecoveryCodes {
+		if recoveryCode != hash {
+			continue
+		}
+		remaining := append(append([]string{}, user.RecoveryCodes[:i]...), user.RecoveryCodes[i+1:]...)
+		return store.User.Update(ctx, user.ID, db.RecoveryCodesUpdater{RecoveryCodes: remaining})
+	}
+	return ErrInvalidTwoFactorCode
+}
+
+// EnrollTOTP provisions a new secret for the user. It isn't required to sign in
+// until it is confirmed with a code.
+func (svc *UserService) EnrollTOTP(ctx context.Context, user *types.User) (*types.TOTPEnrollment, error) {
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	enrollment, err := types.NewTOTPEnrollment(user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.store.User.Update(ctx, user.ID, db.TwoFactorUpdater{Secret: enrollment.Secret}); err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+// ConfirmTOTP turns two-factor authentication on and returns the recovery codes,
+// which are only shown this once.
+func (svc *UserService) ConfirmTOTP(ctx context.Context, user *types.User, params types.TOTPParams) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if len(user.TOTPSecret) == 0 {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	step, err := verifyTOTP(ctx, svc.store, user, params.Code)
+	if err != nil {
+		return nil, err
+	}
+	codes, hashes, err := types.NewRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	update := db.TwoFactorUpdater{
+		Secret:        user.TOTPSecret,
+		Enabled:       true,
+		LastStep:      step,
+		RecoveryCodes: hashes,
+	}
+	if err := svc.store.User.Update(ctx, user.ID, update); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+func (svc *UserService) DisableTwoFactor(ctx context.Context, user *types.User, params types.DisableTwoFactorParams) error {
+	if !user.IsPasswordValid(params.CurrentPassword) {
+		return ErrCurrentPassword
+	}
+	if err := verifySecondFactor(ctx, svc.store, user, params.Code); err != nil {
+		return err
+	}
+	return svc.store.User.Update(ctx, user.ID, db.TwoFactorUpdater{})
+}
+
+// ResetTwoFactor lets an admin turn off the two-factor authentication of a user who
+// lost both its authenticator and its recovery codes.
+func (svc *UserService) ResetTwoFactor(ctx context.Context, id string) error {
+	user, err := svc.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled && len(user.TOTPSecret) == 0 {
+		return ErrUserStateUnchanged
+	}
+	return svc.store.User.Update(ctx, user.ID, db.TwoFactorUpdater{})
+}
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode    = errors.New("two-factor code is invalid")
+	ErrInvalidChallengeToken   = errors.New("challenge token is invalid or expired")
+)
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/types"
+)
+
+// EnableUsers enables the users in a single transaction, returning the ones that
+// were disabled.
+func (svc *UserService) EnableUsers(ctx context.Context, params types.BulkUserParams) ([]string, error) {
+	return svc.setUsersEnabled(ctx, params.IDs, true)
+}
+func (svc *UserService) DisableUsers(ctx context.Context, adminID string, params types.BulkUserParams) ([]string, error) {
+	if err := svc.checkUsersRemoval(ctx, adminID, params.IDs); err != nil {
+		return nil, err
+	}
+	return svc.setUsersEnabled(ctx, params.IDs, false)
+}
+func (svc *UserService) setUsersEnabled(ctx context.Context, ids []string, enabled bool) ([]string, error) {
+	users, err := svc.getUsers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	changed := make([]*types.User, 0, len(users))
+	for _, user := range users {
+		if user.Enabled != enabled {
+			changed = append(changed, user)
+		}
+	}
+	return svc.updateUsers(ctx, changed, db.StatusUpdater{Enabled: enabled})
+}
+
+// AssignRolesToUsers replaces the roles of the users in a single transaction,
+// returning the ones whose roles changed.
+func (svc *UserService) AssignRolesToUsers(ctx context.Context, adminID string, params types.BulkRolesParams) ([]string, error) {
+	if slices.Contains(params.IDs, adminID) {
+		return nil, ErrOwnRoles
+	}
+	users, err := svc.getUsers(ctx, params.IDs)
+	if err != nil {
+		return nil, err
+	}
+	changed := make([]*types.User, 0, len(users))
+	for _, user := range users {
+		if !slices.Equal(user.Roles, params.Roles) {
+			changed = append(changed, user)
+		}
+	}
+	return svc.updateUsers(ctx, changed, db.RolesUpdater{Roles: params.Roles})
+}
+
+// DeleteUsers deletes the users one after another, like DeleteUser, since deleting
+// a user also updates its tasks, projects and teams. A failure doesn't stop it, the
+// result of every user tells whether it was deleted.
+func (svc *UserService) DeleteUsers(ctx context.Context, adminID string, params types.BulkUserParams, deleteParams types.DeleteUserParams) ([]types.BulkUserResult, error) {
+	if slices.Contains(params.IDs, deleteParams.ReassignTo) {
+		return nil, ErrInvalidReassignee
+	}
+	if len(deleteParams.ReassignTo) > 0 {
+		if _, err := svc.GetUserByID(ctx, deleteParams.ReassignTo); err != nil {
+			return nil, err
+		}
+	}
+	if err := svc.checkUsersRemoval(ctx, adminID, params.IDs); err != nil {
+		return nil, err
+	}
+	results := make([]types.BulkUserResult, 0, len(params.IDs))
+	for _, id := range params.IDs {
+		result := types.BulkUserResult{ID: id}
+		if err := svc.DeleteUser(ctx, id, deleteParams); err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// checkUsersRemoval keeps admins from disabling or deleting their own account, and
+// the workspace from losing all of its enabled admins.
+func (svc *UserService) checkUsersRemoval(ctx context.Context, adminID string, ids []string) error {
+	if slices.Contains(ids, adminID) {
+		return ErrOwnAccount
+	}
+	admins, err := getAllUsers(ctx, svc.store, db.NewUsersFilter(db.NewEnabledFieldFilterer(true), db.NewRoleFieldFilterer(types.RoleAdmin)))
+	if err != nil {
+		return err
+	}
+	for _, admin := range admins {
+		if !slices.Contains(ids, admin.ID) {
+			return nil
+		}
+	}
+	if len(admins) > 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// getUsers returns every user of the IDs, failing with the first one not found.
+func (svc *UserService) getUsers(ctx context.Context, ids []string) ([]*types.User, error) {
+	users := make([]*types.User, 0, len(ids))
+	for _, id := range ids {
+		user, err := svc.GetUserByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, ErrUserNotFound) || errors.Is(err, db.ErrInvalidID) {
+				return nil, fmt.Errorf("%w: %s", ErrUserNotFound, id)
+			}
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func getAllUsers(ctx context.Context, store *db.Store, filter db.Filter) ([]*types.User, error) {
+	var users []*types.User
+	for page := int64(1); ; page++ {
+		pagination := db.Pagination{Page: page, Limit: maxPageLimit}
+		batch, err := store.User.GetUsers(ctx, filter, &pagination)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, batch...)
+		if len(batch) < maxPageLimit {
+			return users, nil
+		}
+	}
+}
+
+// updateUsers applies the update to the users in a single transaction, returning
+// their IDs.
+func (svc *UserService) updateUsers(ctx context.Context, users []*types.User, update db.Update) ([]string, error) {
+	if len(users) == 0 {
+		return nil, ErrUserStateUnchanged
+	}
+	ids := make([]string, 0, len(users))
+	actions := make([]db.DBAction, 0, len(users))
+	for _, user := range users {
+		action, err := db.NewUserUpdateAction(user.ID, update)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+		ids = append(ids, user.ID)
+	}
+	if err := svc.store.Project.Transact(ctx, actions); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -47,6 +47,21 @@ func (m *UserLogMiddleware) EnableUser(ctx context.Context, id string) (err erro
 	err = m.next.EnableUser(ctx, id)
 	return err
 }
+func (m *UserLogMiddleware) UnlockUser(ctx context.Context, id string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to unlock user")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": id,
+				"took":   time.Since(start),
+			}).Info("UnlockUser successfully completed")
+		}
+	}(time.Now())
+
+	err = m.next.UnlockUser(ctx, id)
+	return err
+}
 func (m *UserLogMiddleware) DisableUser(ctx context.Context, id string) (err error) {
 	defer func(start time.Time) {
 		if err != nil {
@@ -122,3 +137,339 @@ func (m *UserLogMiddleware) GetUserByID(ctx context.Context, id string) (user *t
 	user, err = m.next.GetUserByID(ctx, id)
 	return user, err
 }
+func (m *UserLogMiddleware) UpdateUser(ctx context.Context, user *types.User, params types.UpdateUserParams) (updated *types.User, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to update user")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": user.ID,
+				"took":   time.Since(start),
+			}).Info("UpdateUser successfully completed")
+		}
+	}(time.Now())
+	updated, err = m.next.UpdateUser(ctx, user, params)
+	return updated, err
+}
+func (m *UserLogMiddleware) DeleteAccount(ctx context.Context, user *types.User, account types.DeleteAccountParams, params types.DeleteUserParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to delete account")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID":     user.ID,
+				"reassignTo": params.ReassignTo,
+				"took":       time.Since(start),
+			}).Info("DeleteAccount successfully completed")
+		}
+	}(time.Now())
+	err = m.next.DeleteAccount(ctx, user, account, params)
+	return err
+}
+func (m *UserLogMiddleware) DeleteUser(ctx context.Context, id string, params types.DeleteUserParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to delete user")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID":     id,
+				"reassignTo": params.ReassignTo,
+				"took":       time.Since(start),
+			}).Info("DeleteUser successfully completed")
+		}
+	}(time.Now())
+	err = m.next.DeleteUser(ctx, id, params)
+	return err
+}
+func (m *UserLogMiddleware) ExportUser(ctx context.Context, user *types.User) (export *types.UserExport, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to export user data")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": user.ID,
+				"took":   time.Since(start),
+			}).Info("ExportUser successfully completed")
+		}
+	}(time.Now())
+	export, err = m.next.ExportUser(ctx, user)
+	return export, err
+}
+func (m *UserLogMiddleware) VerifyEmail(ctx context.Context, token string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to verify email")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"took": time.Since(start),
+			}).Info("VerifyEmail successfully completed")
+		}
+	}(time.Now())
+	err = m.next.VerifyEmail(ctx, token)
+	return err
+}
+func (m *UserLogMiddleware) ResendVerification(ctx context.Context, email string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to resend verification email")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"email": email,
+				"took":  time.Since(start),
+			}).Info("ResendVerification successfully completed")
+		}
+	}(time.Now())
+	err = m.next.ResendVerification(ctx, email)
+	return err
+}
+func (m *UserLogMiddleware) RequestPasswordReset(ctx context.Context, email string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to request password reset")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"email": email,
+				"took":  time.Since(start),
+			}).Info("RequestPasswordReset successfully completed")
+		}
+	}(time.Now())
+	err = m.next.RequestPasswordReset(ctx, email)
+	return err
+}
+func (m *UserLogMiddleware) ResetForgottenPassword(ctx context.Context, params types.ForgottenPasswordResetParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to reset forgotten password")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"took": time.Since(start),
+			}).Info("ResetForgottenPassword successfully completed")
+		}
+	}(time.Now())
+	err = m.next.ResetForgottenPassword(ctx, params)
+	return err
+}
+func (m *UserLogMiddleware) GetSessions(ctx context.Context, current *types.Auth) (sessions []*types.Session, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get sessions")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID":   current.UserID,
+				"sessions": len(sessions),
+				"took":     time.Since(start),
+			}).Info("GetSessions successfully completed")
+		}
+	}(time.Now())
+	sessions, err = m.next.GetSessions(ctx, current)
+	return sessions, err
+}
+func (m *UserLogMiddleware) RevokeSession(ctx context.Context, userID, authUUID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to revoke session")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID":   userID,
+				"authUUID": authUUID,
+				"took":     time.Since(start),
+			}).Info("RevokeSession successfully completed")
+		}
+	}(time.Now())
+	err = m.next.RevokeSession(ctx, userID, authUUID)
+	return err
+}
+func (m *UserLogMiddleware) RevokeSessions(ctx context.Context, userID string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to revoke sessions")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": userID,
+				"took":   time.Since(start),
+			}).Info("RevokeSessions successfully completed")
+		}
+	}(time.Now())
+	err = m.next.RevokeSessions(ctx, userID)
+	return err
+}
+
+func (m *UserLogMiddleware) EnrollTOTP(ctx context.Context, user *types.User) (enrollment *types.TOTPEnrollment, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to enroll TOTP")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": user.ID,
+				"took":   time.Since(start),
+			}).Info("EnrollTOTP successfully completed")
+		}
+	}(time.Now())
+	enrollment, err = m.next.EnrollTOTP(ctx, user)
+	return enrollment, err
+}
+func (m *UserLogMiddleware) ConfirmTOTP(ctx context.Context, user *types.User, params types.TOTPParams) (codes []string, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to confirm TOTP")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": user.ID,
+				"took":   time.Since(start),
+			}).Info("ConfirmTOTP successfully completed")
+		}
+	}(time.Now())
+	codes, err = m.next.ConfirmTOTP(ctx, user, params)
+	return codes, err
+}
+func (m *UserLogMiddleware) DisableTwoFactor(ctx context.Context, user *types.User, params types.DisableTwoFactorParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to disable two-factor authentication")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": user.ID,
+				"took":   time.Since(start),
+			}).Info("DisableTwoFactor successfully completed")
+		}
+	}(time.Now())
+	err = m.next.DisableTwoFactor(ctx, user, params)
+	return err
+}
+func (m *UserLogMiddleware) ResetTwoFactor(ctx context.Context, id string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to reset two-factor authentication")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": id,
+				"took":   time.Since(start),
+			}).Info("ResetTwoFactor successfully completed")
+		}
+	}(time.Now())
+	err = m.next.ResetTwoFactor(ctx, id)
+	return err
+}
+
+func (m *UserLogMiddleware) CreateAccessToken(ctx context.Context, userID string, params types.CreateAccessTokenParams) (token *types.CreatedAccessToken, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create access token")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": userID,
+				"token":  token.Prefix,
+				"took":   time.Since(start),
+			}).Info("CreateAccessToken successfully completed")
+		}
+	}(time.Now())
+	token, err = m.next.CreateAccessToken(ctx, userID, params)
+	return token, err
+}
+func (m *UserLogMiddleware) GetAccessTokens(ctx context.Context, userID string) (tokens []*types.AccessToken, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get access tokens")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID":  userID,
+				"results": len(tokens),
+				"took":    time.Since(start),
+			}).Info("GetAccessTokens successfully completed")
+		}
+	}(time.Now())
+	tokens, err = m.next.GetAccessTokens(ctx, userID)
+	return tokens, err
+}
+func (m *UserLogMiddleware) RevokeAccessToken(ctx context.Context, userID, id string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to revoke access token")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userID": userID,
+				"took":   time.Since(start),
+			}).Info("RevokeAccessToken successfully completed")
+		}
+	}(time.Now())
+	err = m.next.RevokeAccessToken(ctx, userID, id)
+	return err
+}
+
+func (m *UserLogMiddleware) AssignRoles(ctx context.Context, adminID, id string, params types.AssignRolesParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to assign roles")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"adminID": adminID,
+				"userID":  id,
+				"roles":   params.Roles,
+				"took":    time.Since(start),
+			}).Info("AssignRoles successfully completed")
+		}
+	}(time.Now())
+	err = m.next.AssignRoles(ctx, adminID, id, params)
+	return err
+}
+func (m *UserLogMiddleware) EnableUsers(ctx context.Context, params types.BulkUserParams) (ids []string, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to enable users")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"userIDs": ids,
+				"took":    time.Since(start),
+			}).Info("EnableUsers successfully completed")
+		}
+	}(time.Now())
+	ids, err = m.next.EnableUsers(ctx, params)
+	return ids, err
+}
+func (m *UserLogMiddleware) DisableUsers(ctx context.Context, adminID string, params types.BulkUserParams) (ids []string, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to disable users")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"adminID": adminID,
+				"userIDs": ids,
+				"took":    time.Since(start),
+			}).Info("DisableUsers successfully completed")
+		}
+	}(time.Now())
+	ids, err = m.next.DisableUsers(ctx, adminID, params)
+	return ids, err
+}
+func (m *UserLogMiddleware) AssignRolesToUsers(ctx context.Context, adminID string, params types.BulkRolesParams) (ids []string, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to assign roles to users")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"adminID": adminID,
+				"userIDs": ids,
+				"roles":   params.Roles,
+				"took":    time.Since(start),
+			}).Info("AssignRolesToUsers successfully completed")
+		}
+	}(time.Now())
+	ids, err = m.next.AssignRolesToUsers(ctx, adminID, params)
+	return ids, err
+}
+func (m *UserLogMiddleware) DeleteUsers(ctx context.Context, adminID string, params types.BulkUserParams, deleteParams types.DeleteUserParams) (results []types.BulkUserResult, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to delete users")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"adminID":    adminID,
+				"results":    results,
+				"reassignTo": deleteParams.ReassignTo,
+				"took":       time.Since(start),
+			}).Info("DeleteUsers successfully completed")
+		}
+	}(time.Now())
+	results, err = m.next.DeleteUsers(ctx, adminID, params, deleteParams)
+	return results, err
+}
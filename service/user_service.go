@@ -3,14 +3,25 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/mailer"
 	"github.com/ficontini/gotasks/types"
+	"github.com/sirupsen/logrus"
 )
 
 type UserInserter interface {
 	CreateUser(context.Context, types.CreateUserParams) (*types.User, error)
 }
+type UserVerifier interface {
+	VerifyEmail(context.Context, string) error
+	ResendVerification(context.Context, string) error
+}
 type UserGetter interface {
 	GetUsers(context.Context, UserQueryParams) ([]*types.User, error)
 	GetUserByID(context.Context, string) (*types.User, error)
@@ -18,21 +29,70 @@ type UserGetter interface {
 type UserUpdater interface {
 	EnableUser(context.Context, string) error
 	DisableUser(context.Context, string) error
+	UnlockUser(context.Context, string) error
+	UpdateUser(context.Context, *types.User, types.UpdateUserParams) (*types.User, error)
 	ResetPassword(context.Context, *types.User, types.ResetPasswordParams) error
+	RequestPasswordReset(context.Context, string) error
+	ResetForgottenPassword(context.Context, types.ForgottenPasswordResetParams) error
 	InvalidateJWT(context.Context, *types.Auth) error
 }
+type UserDeleter interface {
+	DeleteAccount(context.Context, *types.User, types.DeleteAccountParams, types.DeleteUserParams) error
+	DeleteUser(context.Context, string, types.DeleteUserParams) error
+}
+type UserSessionManager interface {
+	GetSessions(context.Context, *types.Auth) ([]*types.Session, error)
+	RevokeSession(context.Context, string, string) error
+	RevokeSessions(context.Context, string) error
+}
+type UserTwoFactorManager interface {
+	EnrollTOTP(context.Context, *types.User) (*types.TOTPEnrollment, error)
+	ConfirmTOTP(context.Context, *types.User, types.TOTPParams) ([]string, error)
+	DisableTwoFactor(context.Context, *types.User, types.DisableTwoFactorParams) error
+	ResetTwoFactor(context.Context, string) error
+}
+type UserAccessTokenManager interface {
+	CreateAccessToken(context.Context, string, types.CreateAccessTokenParams) (*types.CreatedAccessToken, error)
+	GetAccessTokens(context.Context, string) ([]*types.AccessToken, error)
+	RevokeAccessToken(context.Context, string, string) error
+}
+type UserRoleManager interface {
+	AssignRoles(context.Context, string, string, types.AssignRolesParams) error
+}
+type UserBulkManager interface {
+	EnableUsers(context.Context, types.BulkUserParams) ([]string, error)
+	DisableUsers(context.Context, string, types.BulkUserParams) ([]string, error)
+	AssignRolesToUsers(context.Context, string, types.BulkRolesParams) ([]string, error)
+	DeleteUsers(context.Context, string, types.BulkUserParams, types.DeleteUserParams) ([]types.BulkUserResult, error)
+}
+type UserExporter interface {
+	ExportUser(context.Context, *types.User) (*types.UserExport, error)
+}
 type UserServicer interface {
 	UserInserter
+	UserVerifier
 	UserGetter
 	UserUpdater
+	UserDeleter
+	UserSessionManager
+	UserTwoFactorManager
+	UserAccessTokenManager
+	UserRoleManager
+	UserBulkManager
+	UserExporter
 }
+
+const passwordResetTokenTTL = time.Hour
+
 type UserService struct {
-	store *db.Store
+	store  *db.Store
+	mailer mailer.Mailer
 }
 
-func NewUserService(store *db.Store) UserServicer {
+func NewUserService(store *db.Store, mailer mailer.Mailer) UserServicer {
 	return &UserService{
-		store: store,
+		store:  store,
+		mailer: mailer,
 	}
 }
 
@@ -44,21 +104,67 @@ func (svc *UserService) CreateUser(ctx context.Context, params types.CreateUserP
 	if err != nil {
 		return nil, err
 	}
-	return svc.store.User.InsertUser(ctx, user)
+	user, err = svc.store.User.InsertUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	svc.sendVerification(ctx, user)
+	return user, nil
+}
+
+func (svc *UserService) VerifyEmail(ctx context.Context, tokenStr string) error {
+	claims, err := parseVerificationToken(tokenStr)
+	if err != nil {
+		return err
+	}
+	user, err := svc.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		return err
+	}
+	if user.Email != claims.Email {
+		return ErrInvalidVerificationToken
+	}
+	if user.Verified {
+		return ErrUserStateUnchanged
+	}
+	return svc.store.User.Update(ctx, user.ID, db.VerifiedUpdater{Verified: true})
+}
+
+// ResendVerification sends a new link to unverified users. It does not tell whether
+// the email is registered.
+func (svc *UserService) ResendVerification(ctx context.Context, email string) error {
+	user, err := svc.store.User.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.Verified {
+		svc.sendVerification(ctx, user)
+	}
+	return nil
+}
 
+// sendVerification is best effort: the user can ask for a new link if it fails.
+func (svc *UserService) sendVerification(ctx context.Context, user *types.User) {
+	token, err := newVerificationToken(user)
+	if err == nil {
+		err = svc.mailer.Send(ctx, newVerificationMessage(user, token))
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("userID", user.ID).Warn("Failed to send verification email")
+	}
 }
 
 func (svc *UserService) isEmailAlreadyInUse(ctx context.Context, email string) bool {
-	user, _ := svc.store.User.GetUserByEmail(ctx, email)
-	return user != nil
+	return isEmailAlreadyInUse(ctx, svc.store, email)
 }
 
 func (svc *UserService) setEnabled(ctx context.Context, id string, enabled bool) error {
-	user, err := svc.store.User.GetUserByID(ctx, id)
+	user, err := svc.GetUserByID(ctx, id)
 	if err != nil {
-		if errors.Is(err, db.ErrorNotFound) {
-			return ErrUserNotFound
-		}
+		return err
 	}
 	if user.Enabled == enabled {
 		return ErrUserStateUnchanged
@@ -80,10 +186,83 @@ func (svc *UserService) DisableUser(ctx context.Context, id string) error {
 	return svc.setEnabled(ctx, id, false)
 }
 
+// UnlockUser forgets the failed sign-ins of the user, lifting its lockout.
+func (svc *UserService) UnlockUser(ctx context.Context, id string) error {
+	user, err := svc.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	key := emailAttemptsKey(user.Email)
+	attempts, err := svc.store.LoginAttempt.GetLoginAttempts(ctx, key)
+	if err != nil {
+		return err
+	}
+	if attempts.Failures == 0 {
+		return ErrUserStateUnchanged
+	}
+	return svc.store.LoginAttempt.ResetLoginAttempts(ctx, key)
+}
+
+// AssignRoles replaces the roles of the user. Admins can't change their own roles, so
+// they can't lock themselves out.
+func (svc *UserService) AssignRoles(ctx context.Context, adminID, id string, params types.AssignRolesParams) error {
+	if adminID == id {
+		return ErrOwnRoles
+	}
+	if err := svc.store.User.Update(ctx, id, db.RolesUpdater{Roles: params.Roles}); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateUser changes the user profile. Changing the email requires the current
+// password and verifying the new address.
+func (svc *UserService) UpdateUser(ctx context.Context, user *types.User, params types.UpdateUserParams) (*types.User, error) {
+	update := db.UserProfileUpdater{}
+	if len(params.FirstName) > 0 && params.FirstName != user.FirstName {
+		update.FirstName = params.FirstName
+	}
+	if len(params.LastName) > 0 && params.LastName != user.LastName {
+		update.LastName = params.LastName
+	}
+	if len(params.Email) > 0 && params.Email != user.Email {
+		if !user.IsPasswordValid(params.CurrentPassword) {
+			return nil, ErrCurrentPassword
+		}
+		if svc.isEmailAlreadyInUse(ctx, params.Email) {
+			return nil, ErrEmailAlreadyInUse
+		}
+		update.Email = params.Email
+	}
+	if update == (db.UserProfileUpdater{}) {
+		return nil, ErrUserStateUnchanged
+	}
+	if err := svc.store.User.Update(ctx, user.ID, update); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	updatedUser, err := svc.GetUserByID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(update.Email) > 0 {
+		svc.sendVerification(ctx, updatedUser)
+	}
+	return updatedUser, nil
+}
+
 func (svc *UserService) ResetPassword(ctx context.Context, user *types.User, params types.ResetPasswordParams) error {
 	if !user.IsPasswordValid(params.CurrentPassword) {
 		return ErrCurrentPassword
 	}
+	if err := types.CheckPassword(params.NewPassword, user.Email, user.FirstName, user.LastName); err != nil {
+		return err
+	}
 	enpw, err := params.GeneratePassword()
 	if err != nil {
 		return err
@@ -93,6 +272,77 @@ func (svc *UserService) ResetPassword(ctx context.Context, user *types.User, par
 	}
 	return nil
 }
+
+// RequestPasswordReset emails a reset token to the user. It does not tell whether
+// the email is registered.
+func (svc *UserService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := svc.store.User.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil
+		}
+		return err
+	}
+	token, secret, err := types.NewToken(user.ID, types.PasswordResetToken, passwordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+	if err := svc.store.Token.InsertToken(ctx, token); err != nil {
+		return err
+	}
+	return svc.mailer.Send(ctx, newPasswordResetMessage(user, secret))
+}
+
+// ResetForgottenPassword sets the new password and revokes all the user sessions.
+func (svc *UserService) ResetForgottenPassword(ctx context.Context, params types.ForgottenPasswordResetParams) error {
+	id := types.HashToken(params.Token)
+	token, err := svc.store.Token.GetToken(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if !token.IsValid(types.PasswordResetToken, time.Now()) {
+		return ErrInvalidResetToken
+	}
+	user, err := svc.store.User.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if err := types.CheckPassword(params.NewPassword, user.Email, user.FirstName, user.LastName); err != nil {
+		return err
+	}
+	if _, err := svc.store.Token.ConsumeToken(ctx, id); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	enpw, err := params.GeneratePassword()
+	if err != nil {
+		return err
+	}
+	if err := svc.store.User.Update(ctx, token.UserID, db.PasswordUpdater{EncryptedPassword: enpw}); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	return svc.store.Auth.DeleteByUserID(ctx, token.UserID)
+}
+func newPasswordResetMessage(user *types.User, secret string) mailer.Message {
+	link := fmt.Sprintf("%s/reset-password?token=%s", os.Getenv(AppBaseURLEnvName), url.QueryEscape(secret))
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. Open the link below within %d minutes to choose a new one:\n\n%s\n\nIf it wasn't you, you can ignore this email.\n",
+			user.FirstName, int(passwordResetTokenTTL.Minutes()), link),
+	}
+}
 func (svc *UserService) InvalidateJWT(ctx context.Context, auth *types.Auth) error {
 	filter := &types.AuthFilter{
 		UserID:   auth.UserID,
@@ -104,12 +354,255 @@ func (svc *UserService) InvalidateJWT(ctx context.Context, auth *types.Auth) err
 	return nil
 }
 
+func (svc *UserService) DeleteAccount(ctx context.Context, user *types.User, account types.DeleteAccountParams, params types.DeleteUserParams) error {
+	if !user.IsPasswordValid(account.CurrentPassword) {
+		return ErrCurrentPassword
+	}
+	return svc.DeleteUser(ctx, user.ID, params)
+}
+
+// DeleteUser unassigns or reassigns the user tasks, hands the user projects and teams
+// over to another member, deleting the ones nobody else is part of, and removes the
+// user along with all its auth tokens.
+func (svc *UserService) DeleteUser(ctx context.Context, id string, params types.DeleteUserParams) error {
+	if _, err := svc.GetUserByID(ctx, id); err != nil {
+		return err
+	}
+	if len(params.ReassignTo) > 0 {
+		if params.ReassignTo == id {
+			return ErrInvalidReassignee
+		}
+		if _, err := svc.GetUserByID(ctx, params.ReassignTo); err != nil {
+			return err
+		}
+	}
+	projects, err := getAllProjects(ctx, svc.store, db.NewMemberProjectsFilter(id))
+	if err != nil {
+		return err
+	}
+	if err := svc.releaseTasks(ctx, id, params.ReassignTo); err != nil {
+		return err
+	}
+	for _, project := range projects {
+		if err := svc.leaveProject(ctx, project, id); err != nil {
+			return err
+		}
+	}
+	teams, err := getAllTeams(ctx, svc.store, db.NewUserTeamsFilter(id))
+	if err != nil {
+		return err
+	}
+	for _, team := range teams {
+		if err := leaveTeam(ctx, svc.store, team, id); err != nil {
+			return err
+		}
+	}
+	if err := svc.store.Auth.DeleteByUserID(ctx, id); err != nil {
+		return err
+	}
+	if err := svc.store.AccessToken.DeleteByUserID(ctx, id); err != nil {
+		return err
+	}
+	if err := svc.store.User.Delete(ctx, id); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// releaseTasks moves the user tasks to the reassignee, unless the task belongs to a
+// project the reassignee is not a member of, in which case it is left unassigned.
+func (svc *UserService) releaseTasks(ctx context.Context, userID, reassignTo string) error {
+	tasks, err := getAllTasks(ctx, svc.store, db.NewUserTasksFilter(nil, userID))
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		var update db.Update = db.TaskUnassignUpdater{}
+		task.AssignedTo = ""
+		if len(reassignTo) > 0 && svc.canBeAssigned(ctx, task, reassignTo) {
+			update = db.TaskAssignationUpdater{AssignedTo: reassignTo}
+			task.AssignedTo = reassignTo
+		}
+		if err := updateTask(ctx, svc.store, task, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (svc *UserService) canBeAssigned(ctx context.Context, task *types.Task, userID string) bool {
+	if len(task.ProjectID) == 0 {
+		return true
+	}
+	project, err := svc.store.Project.GetProjectByID(ctx, task.ProjectID)
+	if err != nil {
+		return false
+	}
+	_, ok := project.GetRole(userID)
+	return ok
+}
+func (svc *UserService) leaveProject(ctx context.Context, project *types.Project, userID string) error {
+	role, _ := project.GetRole(userID)
+	successor, ok := project.Successor(userID)
+	switch {
+	case !ok:
+		return deleteProject(ctx, svc.store, project, types.DeleteProjectParams{Tasks: types.DetachProjectTasks})
+	case project.UserID == userID || (role == types.ProjectRoleOwner && project.CountOwners() <= 1):
+		return svc.store.Project.Update(ctx, project.ID, db.ProjectOwnerUpdater{UserID: successor, PreviousUserID: userID})
+	default:
+		return svc.store.Project.Update(ctx, project.ID, db.ProjectMemberRemover{UserID: userID})
+	}
+}
+func getAllProjects(ctx context.Context, store *db.Store, filter db.Filter) ([]*types.Project, error) {
+	var projects []*types.Project
+	for page := int64(1); ; page++ {
+		pagination := db.Pagination{Page: page, Limit: maxPageLimit}
+		batch, err := store.Project.GetProjects(ctx, filter, &pagination)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, batch...)
+		if len(batch) < maxPageLimit {
+			return projects, nil
+		}
+	}
+}
+
+// ExportUser returns the user profile, the tasks assigned to the user and the projects it owns.
+func (svc *UserService) ExportUser(ctx context.Context, user *types.User) (*types.UserExport, error) {
+	tasks, err := getAllTasks(ctx, svc.store, db.NewUserTasksFilter(nil, user.ID))
+	if err != nil {
+		return nil, err
+	}
+	projects, err := getAllProjects(ctx, svc.store, db.NewMemberProjectsFilter(user.ID))
+	if err != nil {
+		return nil, err
+	}
+	return types.NewUserExport(user, tasks, projects), nil
+}
+
+// GetSessions lists the unexpired sessions of the user the auth belongs to.
+func (svc *UserService) GetSessions(ctx context.Context, current *types.Auth) ([]*types.Session, error) {
+	auths, err := svc.store.Auth.GetByUserID(ctx, current.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewSessions(auths, current, time.Now()), nil
+}
+func (svc *UserService) RevokeSession(ctx context.Context, userID, authUUID string) error {
+	filter := &types.AuthFilter{
+		UserID:   userID,
+		AuthUUID: authUUID,
+	}
+	if _, err := svc.store.Auth.Get(ctx, filter); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	return svc.store.Auth.Delete(ctx, filter)
+}
+func (svc *UserService) RevokeSessions(ctx context.Context, userID string) error {
+	if _, err := svc.GetUserByID(ctx, userID); err != nil {
+		return err
+	}
+	return svc.store.Auth.DeleteByUserID(ctx, userID)
+}
+
+func (svc *UserService) CreateAccessToken(ctx context.Context, userID string, params types.CreateAccessTokenParams) (*types.CreatedAccessToken, error) {
+	token, secret, err := types.NewAccessToken(userID, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.store.AccessToken.InsertAccessToken(ctx, token); err != nil {
+		return nil, err
+	}
+	return &types.CreatedAccessToken{AccessToken: token, Token: secret}, nil
+}
+
+// GetAccessTokens lists the unexpired access tokens of the user.
+func (svc *UserService) GetAccessTokens(ctx context.Context, userID string) ([]*types.AccessToken, error) {
+	tokens, err := svc.store.AccessToken.GetAccessTokensByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	active := make([]*types.AccessToken, 0, len(tokens))
+	for _, token := range tokens {
+		if !token.IsExpired(now) {
+			active = append(active, token)
+		}
+	}
+	return active, nil
+}
+func (svc *UserService) RevokeAccessToken(ctx context.Context, userID, id string) error {
+	if err := svc.store.AccessToken.DeleteAccessToken(ctx, id, userID); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrAccessTokenNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// UserQueryParams filters the users. Search matches their email or name, and the
+// creation range goes from CreatedFrom, inclusive, to CreatedTo, exclusive, given as
+// RFC 3339 times or dates like 2006-01-02.
 type UserQueryParams struct {
 	db.Pagination
+	Enabled     *bool
+	Role        types.Role
+	Search      string
+	CreatedFrom string
+	CreatedTo   string
+}
+
+func (p UserQueryParams) filter() (db.Filter, error) {
+	fields := []db.FieldFilterer{}
+	if p.Enabled != nil {
+		fields = append(fields, db.NewEnabledFieldFilterer(*p.Enabled))
+	}
+	if len(p.Role) > 0 {
+		if !p.Role.IsValid() {
+			return nil, fmt.Errorf("%w: role %s is invalid", ErrInvalidUserQuery, p.Role)
+		}
+		fields = append(fields, db.NewRoleFieldFilterer(p.Role))
+	}
+	if search := strings.TrimSpace(p.Search); len(search) > 0 {
+		fields = append(fields, db.NewUserSearchFieldFilterer(search))
+	}
+	from, err := parseQueryTime("createdFrom", p.CreatedFrom)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseQueryTime("createdTo", p.CreatedTo)
+	if err != nil {
+		return nil, err
+	}
+	if !from.IsZero() || !to.IsZero() {
+		fields = append(fields, db.NewCreatedAtFieldFilterer(from, to))
+	}
+	return db.NewUsersFilter(fields...), nil
+}
+func parseQueryTime(name, value string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Truncate(time.Second), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %s must be a date like 2006-01-02 or an RFC 3339 time", ErrInvalidUserQuery, name)
 }
 
 func (svc *UserService) GetUsers(ctx context.Context, params UserQueryParams) ([]*types.User, error) {
-	filter := db.EmptyFilter{DataType: &db.DataType{DataType: types.UserDataType}}
+	filter, err := params.filter()
+	if err != nil {
+		return nil, err
+	}
 	return svc.store.User.GetUsers(ctx, filter, &params.Pagination)
 }
 func (svc *UserService) GetUserByID(ctx context.Context, id string) (*types.User, error) {
@@ -124,8 +617,18 @@ func (svc *UserService) GetUserByID(ctx context.Context, id string) (*types.User
 }
 
 var (
-	ErrEmailAlreadyInUse  = errors.New("email already in use")
-	ErrUserStateUnchanged = errors.New("user state unchanged")
-	ErrUserNotFound       = errors.New("user resource not found")
-	ErrCurrentPassword    = errors.New("current password is not valid")
+	ErrEmailAlreadyInUse   = errors.New("email already in use")
+	ErrUserStateUnchanged  = errors.New("user state unchanged")
+	ErrUserNotFound        = errors.New("user resource not found")
+	ErrCurrentPassword     = errors.New("current password is not valid")
+	ErrInvalidReassignee   = errors.New("tasks cannot be reassigned to the deleted user")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrOwnRoles            = errors.New("admins can't change their own roles")
+	ErrOwnAccount          = errors.New("admins can't disable or delete their own account")
+	ErrLastAdmin           = errors.New("the workspace must keep an enabled admin")
+	ErrInvalidUserQuery    = errors.New("invalid user query")
+
+	ErrInvalidVerificationToken = errors.New("verification token is invalid or expired")
+	ErrInvalidResetToken        = errors.New("password reset token is invalid or expired")
 )
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AppBaseURLEnvName    = "APP_BASE_URL"
+	verificationTokenTTL = 24 * time.Hour
+	verificationAudience = "email-verification"
+)
+
+// verificationClaims bind the token to the address being verified, so links sent
+// to a previous email stop working once it changes.
+type verificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+func newVerificationToken(user *types.User) (string, error) {
+	now := time.Now()
+	claims := verificationClaims{
+		Email: user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{verificationAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(verificationTokenTTL)),
+		},
+	}
+	return signWithDerivedKey(claims, verificationAudience)
+}
+func parseVerificationToken(tokenStr string) (*verificationClaims, error) {
+	claims := &verificationClaims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return deriveKey(verificationAudience)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(verificationAudience))
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	return claims, nil
+}
+
+func newVerificationMessage(user *types.User, token string) mailer.Message {
+	link := fmt.Sprintf("%s/api/user/verify?token=%s", os.Getenv(AppBaseURLEnvName), url.QueryEscape(token))
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease verify your email address by opening the link below within %d hours:\n\n%s\n",
+			user.FirstName, int(verificationTokenTTL.Hours()), link),
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/types"
+	"github.com/sirupsen/logrus"
+)
+
+type WorkspaceLogMiddleware struct {
+	next WorkspaceServicer
+}
+
+func NewWorkspaceLogMiddleware(next WorkspaceServicer) WorkspaceServicer {
+	return &WorkspaceLogMiddleware{
+		next: next,
+	}
+}
+
+func (m *WorkspaceLogMiddleware) GetWorkspace(ctx context.Context, user *types.User) (workspace *types.Workspace, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get workspace")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"workspaceID": user.WorkspaceID,
+				"userID":      user.ID,
+				"took":        time.Since(start),
+			}).Info("Workspace fetched")
+		}
+	}(time.Now())
+	workspace, err = m.next.GetWorkspace(ctx, user)
+	return workspace, err
+}
+func (m *WorkspaceLogMiddleware) CreateWorkspace(ctx context.Context, user *types.User, params types.NewWorkspaceParams) (workspace *types.Workspace, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create workspace")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"workspaceID": workspace.ID,
+				"userID":      user.ID,
+				"took":        time.Since(start),
+			}).Info("Workspace created")
+		}
+	}(time.Now())
+	workspace, err = m.next.CreateWorkspace(ctx, user, params)
+	return workspace, err
+}
+func (m *WorkspaceLogMiddleware) UpdateWorkspace(ctx context.Context, user *types.User, params types.UpdateWorkspaceParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to update workspace")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"workspaceID": user.WorkspaceID,
+				"userID":      user.ID,
+				"took":        time.Since(start),
+			}).Info("Workspace updated")
+		}
+	}(time.Now())
+	err = m.next.UpdateWorkspace(ctx, user, params)
+	return err
+}
+func (m *WorkspaceLogMiddleware) InviteUser(ctx context.Context, user *types.User, params types.InviteUserParams) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to invite user")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"workspaceID": user.WorkspaceID,
+				"userID":      user.ID,
+				"roles":       params.Roles,
+				"took":        time.Since(start),
+			}).Info("User invited")
+		}
+	}(time.Now())
+	err = m.next.InviteUser(ctx, user, params)
+	return err
+}
+func (m *WorkspaceLogMiddleware) GetInvitations(ctx context.Context, pagination db.Pagination) (invitations []*types.Invitation, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to get invitations")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"count": len(invitations),
+				"took":  time.Since(start),
+			}).Info("Invitations fetched")
+		}
+	}(time.Now())
+	invitations, err = m.next.GetInvitations(ctx, pagination)
+	return invitations, err
+}
+func (m *WorkspaceLogMiddleware) RevokeInvitation(ctx context.Context, id string) (err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to revoke invitation")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"invitationID": id,
+				"took":         time.Since(start),
+			}).Info("Invitation revoked")
+		}
+	}(time.Now())
+	err = m.next.RevokeInvitation(ctx, id)
+	return err
+}
+func (m *WorkspaceLogMiddleware) AcceptInvitation(ctx context.Context, params types.AcceptInvitationParams) (user *types.User, err error) {
+	defer func(start time.Time) {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to accept invitation")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"workspaceID": user.WorkspaceID,
+				"userID":      user.ID,
+				"took":        time.Since(start),
+			}).Info("Invitation accepted")
+		}
+	}(time.Now())
+	user, err = m.next.AcceptInvitation(ctx, params)
+	return user, err
+}
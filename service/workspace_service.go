@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ficontini/gotasks/db"
+	"github.com/ficontini/gotasks/mailer"
+	"github.com/ficontini/gotasks/types"
+)
+
+var (
+	ErrWorkspaceNotFound      = errors.New("workspace resource not found")
+	ErrDefaultWorkspace       = errors.New("the default workspace can't be changed")
+	ErrWorkspaceCreation      = errors.New("only admins of the default workspace can create workspaces")
+	ErrInvalidInvitationToken = errors.New("invitation token is invalid or expired")
+	ErrInvitationNotFound     = errors.New("invitation resource not found")
+)
+
+type WorkspaceGetter interface {
+	GetWorkspace(context.Context, *types.User) (*types.Workspace, error)
+}
+type WorkspaceCreator interface {
+	CreateWorkspace(context.Context, *types.User, types.NewWorkspaceParams) (*types.Workspace, error)
+}
+type WorkspaceUpdater interface {
+	UpdateWorkspace(context.Context, *types.User, types.UpdateWorkspaceParams) error
+}
+type WorkspaceInviter interface {
+	InviteUser(context.Context, *types.User, types.InviteUserParams) error
+	GetInvitations(context.Context, db.Pagination) ([]*types.Invitation, error)
+	RevokeInvitation(context.Context, string) error
+	AcceptInvitation(context.Context, types.AcceptInvitationParams) (*types.User, error)
+}
+type WorkspaceServicer interface {
+	WorkspaceGetter
+	WorkspaceCreator
+	WorkspaceUpdater
+	WorkspaceInviter
+}
+
+type WorkspaceService struct {
+	store  *db.Store
+	mailer mailer.Mailer
+	policy *Policy
+}
+
+func NewWorkspaceService(store *db.Store, mailer mailer.Mailer, policy *Policy) WorkspaceServicer {
+	return &WorkspaceService{
+		store:  store,
+		mailer: mailer,
+		policy: policy,
+	}
+}
+
+func (svc *WorkspaceService) GetWorkspace(ctx context.Context, user *types.User) (*types.Workspace, error) {
+	return getWorkspace(ctx, svc.store, user.WorkspaceID)
+}
+
+// CreateWorkspace creates a workspace for a new tenant and invites its first admin.
+// Only the admins of the default workspace, who run the deployment, create them.
+func (svc *WorkspaceService) CreateWorkspace(ctx context.Context, user *types.User, params types.NewWorkspaceParams) (*types.Workspace, error) {
+	if len(user.WorkspaceID) > 0 {
+		return nil, ErrWorkspaceCreation
+	}
+	if isEmailAlreadyInUse(ctx, svc.store, params.AdminEmail) {
+		return nil, ErrEmailAlreadyInUse
+	}
+	workspace, err := svc.store.Workspace.InsertWorkspace(ctx, types.NewWorkspaceFromParams(params))
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.invite(ctx, workspace, user.ID, params.AdminEmail, []types.Role{types.RoleAdmin}); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+func (svc *WorkspaceService) UpdateWorkspace(ctx context.Context, user *types.User, params types.UpdateWorkspaceParams) error {
+	if len(user.WorkspaceID) == 0 {
+		return ErrDefaultWorkspace
+	}
+	return svc.store.Workspace.Update(ctx, user.WorkspaceID, db.WorkspaceUpdater{Name: params.Name})
+}
+
+// InviteUser invites a new user to the workspace of the user. Inviting with roles
+// needs the permission to assign them.
+func (svc *WorkspaceService) InviteUser(ctx context.Context, user *types.User, params types.InviteUserParams) error {
+	if len(params.Roles) > 0 {
+		if err := svc.policy.Authorize(user, types.PermissionRoleAssign); err != nil {
+			return err
+		}
+	}
+	if isEmailAlreadyInUse(ctx, svc.store, params.Email) {
+		return ErrEmailAlreadyInUse
+	}
+	workspace, err := getWorkspace(ctx, svc.store, user.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	return svc.invite(ctx, workspace, user.ID, params.Email, params.Roles)
+}
+
+// GetInvitations returns the pending invitations of the workspace.
+func (svc *WorkspaceService) GetInvitations(ctx context.Context, pagination db.Pagination) ([]*types.Invitation, error) {
+	return svc.store.Invitation.GetInvitations(ctx, db.NewInvitationsFilter(), &pagination)
+}
+
+// RevokeInvitation deletes a pending invitation, so its link can't be used anymore.
+func (svc *WorkspaceService) RevokeInvitation(ctx context.Context, id string) error {
+	if err := svc.store.Invitation.Delete(ctx, id); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return ErrInvitationNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// AcceptInvitation signs the invited user up in the workspace of the invitation. The
+// invitation is consumed last, so it can be retried when the checks fail.
+func (svc *WorkspaceService) AcceptInvitation(ctx context.Context, params types.AcceptInvitationParams) (*types.User, error) {
+	id := types.HashToken(params.Token)
+	invitation, err := svc.store.Invitation.GetInvitationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, ErrInvalidInvitationToken
+		}
+		return nil, err
+	}
+	if !invitation.IsValid(time.Now()) {
+		return nil, ErrInvalidInvitationToken
+	}
+	if isEmailAlreadyInUse(ctx, svc.store, invitation.Email) {
+		return nil, ErrEmailAlreadyInUse
+	}
+	if err := types.CheckPassword(params.Password, invitation.Email); err != nil {
+		return nil, err
+	}
+	if _, err := getWorkspace(ctx, svc.store, invitation.WorkspaceID); err != nil {
+		return nil, err
+	}
+	user, err := types.NewInvitedUser(invitation.Email, invitation.Roles, params)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := svc.store.Invitation.ConsumeInvitation(ctx, id); err != nil {
+		if errors.Is(err, db.ErrorNotFound) {
+			return nil, ErrInvalidInvitationToken
+		}
+		return nil, err
+	}
+	return svc.store.User.InsertUser(db.WithWorkspace(ctx, invitation.WorkspaceID), user)
+}
+
+func (svc *WorkspaceService) invite(ctx context.Context, workspace *types.Workspace, invitedBy, email string, roles []types.Role) error {
+	invitation, secret, err := types.NewInvitation(email, roles, invitedBy, invitationTTL)
+	if err != nil {
+		return err
+	}
+	if err := svc.store.Invitation.InsertInvitation(db.WithWorkspace(ctx, workspace.ID), invitation); err != nil {
+		return err
+	}
+	return svc.mailer.Send(ctx, newInvitationMessage(workspace, email, secret))
+}
+
+func getWorkspace(ctx context.Context, store *db.Store, id string) (*types.Workspace, error) {
+	if len(id) == 0 {
+		return &types.Workspace{Name: types.DefaultWorkspaceName}, nil
+	}
+	workspace, err := store.Workspace.GetWorkspaceByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrorNotFound) || errors.Is(err, db.ErrInvalidID) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// isEmailAlreadyInUse looks the email up in every workspace, since users sign in with
+// their email before their workspace is known.
+func isEmailAlreadyInUse(ctx context.Context, store *db.Store, email string) bool {
+	user, _ := store.User.GetUserByEmail(db.WithoutWorkspace(ctx), email)
+	return user != nil
+}
@@ -0,0 +1,127 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+type Scope string
+
+const (
+	ScopeTasksRead      Scope = "tasks:read"
+	ScopeTasksWrite     Scope = "tasks:write"
+	ScopeProjectsRead   Scope = "projects:read"
+	ScopeProjectsWrite  Scope = "projects:write"
+	ScopeTemplatesRead  Scope = "templates:read"
+	ScopeTemplatesWrite Scope = "templates:write"
+	ScopeUserRead       Scope = "user:read"
+)
+
+var Scopes = []Scope{
+	ScopeTasksRead,
+	ScopeTasksWrite,
+	ScopeProjectsRead,
+	ScopeProjectsWrite,
+	ScopeTemplatesRead,
+	ScopeTemplatesWrite,
+	ScopeUserRead,
+}
+
+func (s Scope) IsValid() bool {
+	for _, scope := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// AccessTokenPrefix tells personal access tokens apart from session JWTs.
+	AccessTokenPrefix           = "gtp_"
+	defaultAccessTokenTTLDays   = 30
+	maxAccessTokenTTLDays       = 365
+	minAccessTokenNameLen       = 3
+	accessTokenSecretLen        = 32
+	accessTokenDisplayPrefixLen = len(AccessTokenPrefix) + 4
+)
+
+// AccessToken is a personal access token for scripts and integrations. Like Token,
+// only the hash of its secret is stored and it is identified by it.
+type AccessToken struct {
+	ID             string    `bson:"_id" dynamodbav:"ID" json:"id"`
+	UserID         string    `bson:"userID" dynamodbav:"userID" json:"-"`
+	Name           string    `bson:"name" dynamodbav:"name" json:"name"`
+	Prefix         string    `bson:"prefix" dynamodbav:"prefix" json:"prefix"`
+	Scopes         []Scope   `bson:"scopes" dynamodbav:"scopes" json:"scopes"`
+	CreatedAt      time.Time `bson:"createdAt" dynamodbav:"createdAt" json:"createdAt"`
+	ExpirationTime int64     `bson:"expirationTime" dynamodbav:"expirationTime" json:"expirationTime"`
+}
+
+// NewAccessToken returns the token to store along with its secret, which is only
+// shown to the user once.
+func NewAccessToken(userID string, params CreateAccessTokenParams) (*AccessToken, string, error) {
+	b := make([]byte, accessTokenSecretLen)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	secret := AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	days := params.ExpiresInDays
+	if days == 0 {
+		days = defaultAccessTokenTTLDays
+	}
+	now := time.Now().UTC()
+	return &AccessToken{
+		ID:             HashToken(secret),
+		UserID:         userID,
+		Name:           params.Name,
+		Prefix:         secret[:accessTokenDisplayPrefixLen],
+		Scopes:         params.Scopes,
+		CreatedAt:      now,
+		ExpirationTime: now.AddDate(0, 0, days).Unix(),
+	}, secret, nil
+}
+func (t *AccessToken) IsExpired(now time.Time) bool {
+	return now.Unix() >= t.ExpirationTime
+}
+func (t *AccessToken) HasScope(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateAccessTokenParams struct {
+	Name          string  `json:"name"`
+	Scopes        []Scope `json:"scopes"`
+	ExpiresInDays int     `json:"expiresInDays"`
+}
+
+func (p CreateAccessTokenParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.Name) < minAccessTokenNameLen {
+		errors["name"] = fmt.Sprintf("name length must be at least %d characters", minAccessTokenNameLen)
+	}
+	if len(p.Scopes) == 0 {
+		errors["scopes"] = "at least one scope is required"
+	}
+	for _, scope := range p.Scopes {
+		if !scope.IsValid() {
+			errors["scopes"] = fmt.Sprintf("scope %s is invalid", scope)
+		}
+	}
+	if p.ExpiresInDays < 0 || p.ExpiresInDays > maxAccessTokenTTLDays {
+		errors["expiresInDays"] = fmt.Sprintf("expiresInDays must be between 1 and %d", maxAccessTokenTTLDays)
+	}
+	return errors
+}
+
+// CreatedAccessToken is the created token along with its secret.
+type CreatedAccessToken struct {
+	*AccessToken
+	Token string `json:"token"`
+}
@@ -7,18 +7,71 @@ import (
 	"github.com/twinj/uuid"
 )
 
+// RefreshTokenTTL is how long a session can be refreshed for after signing in, and
+// AccessTokenTTL how long each access token of the session is valid.
+var (
+	RefreshTokenTTL = 7 * 24 * time.Hour
+	AccessTokenTTL  = 15 * time.Minute
+)
+
+// Auth is a session of a user. RefreshGeneration counts the refresh token rotations
+// of the session: only the refresh token of the current generation is valid.
 type Auth struct {
-	UserID         string `bson:"userID" dynamodbav:"userID"`
-	AuthUUID       string `bson:"authUUID" dynamodbav:"authUUID"`
-	ExpirationTime int64  `bson:"expirationTime" dynamodbav:"expirationTime"`
+	UserID            string    `bson:"userID" dynamodbav:"userID" json:"-"`
+	AuthUUID          string    `bson:"authUUID" dynamodbav:"authUUID" json:"authUUID"`
+	ExpirationTime    int64     `bson:"expirationTime" dynamodbav:"expirationTime" json:"expirationTime"`
+	IP                string    `bson:"ip" dynamodbav:"ip" json:"ip"`
+	UserAgent         string    `bson:"userAgent" dynamodbav:"userAgent" json:"userAgent"`
+	CreatedAt         time.Time `bson:"createdAt" dynamodbav:"createdAt" json:"createdAt"`
+	RefreshGeneration int       `bson:"refreshGeneration" dynamodbav:"refreshGeneration" json:"-"`
+	ImpersonatorID    string    `bson:"impersonatorID,omitempty" dynamodbav:"impersonatorID,omitempty" json:"impersonatorID,omitempty"`
 }
 
 func NewAuth(userID string) *Auth {
+	now := time.Now()
 	return &Auth{
 		UserID:         userID,
 		AuthUUID:       uuid.NewV4().String(),
-		ExpirationTime: time.Now().Add(time.Hour * 4).Unix(),
+		ExpirationTime: now.Add(RefreshTokenTTL).Unix(),
+		CreatedAt:      now.UTC(),
+	}
+}
+func (a *Auth) IsExpired(now time.Time) bool {
+	return now.Unix() >= a.ExpirationTime
+}
+
+// AccessTokenExpiration returns when an access token issued now expires, which is
+// never after the session does. Impersonations can't be refreshed, so their access
+// token lasts as long as the session.
+func (a *Auth) AccessTokenExpiration(now time.Time) int64 {
+	if a.IsImpersonation() {
+		return a.ExpirationTime
+	}
+	exp := now.Add(AccessTokenTTL).Unix()
+	if exp > a.ExpirationTime {
+		return a.ExpirationTime
 	}
+	return exp
+}
+
+// Session is an auth of the user as listed to it, flagging the one making the request.
+type Session struct {
+	*Auth
+	Current bool `json:"current"`
+}
+
+func NewSessions(auths []*Auth, current *Auth, now time.Time) []*Session {
+	sessions := []*Session{}
+	for _, auth := range auths {
+		if auth.IsExpired(now) {
+			continue
+		}
+		sessions = append(sessions, &Session{
+			Auth:    auth,
+			Current: auth.AuthUUID == current.AuthUUID,
+		})
+	}
+	return sessions
 }
 
 type AuthFilter struct {
@@ -27,8 +80,10 @@ type AuthFilter struct {
 }
 
 type AuthParams struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 func (p AuthParams) Validate() map[string]string {
@@ -43,5 +98,18 @@ func (p AuthParams) Validate() map[string]string {
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RefreshParams struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (p RefreshParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.RefreshToken) == 0 {
+		errors["refreshToken"] = "refreshToken is required"
+	}
+	return errors
 }
@@ -0,0 +1,40 @@
+package types
+
+import "time"
+
+// ImpersonationTTL is how long an admin can act as another user before starting a
+// new impersonation.
+var ImpersonationTTL = 30 * time.Minute
+
+// NewImpersonationAuth returns a session of the user for the impersonator, the
+// admin acting as the user.
+func NewImpersonationAuth(userID, impersonatorID string) *Auth {
+	auth := NewAuth(userID)
+	auth.ImpersonatorID = impersonatorID
+	auth.ExpirationTime = time.Now().Add(ImpersonationTTL).Unix()
+	return auth
+}
+func (a *Auth) IsImpersonation() bool {
+	return len(a.ImpersonatorID) > 0
+}
+
+type ImpersonationResponse struct {
+	Token          string `json:"token"`
+	ExpirationTime int64  `json:"expirationTime"`
+}
+
+// CurrentUser is the authenticated user, flagged when an admin is impersonating it
+// so clients can show a banner.
+type CurrentUser struct {
+	*User
+	Impersonated   bool   `json:"impersonated"`
+	ImpersonatedBy string `json:"impersonatedBy,omitempty"`
+}
+
+func NewCurrentUser(user *User, auth *Auth) *CurrentUser {
+	return &CurrentUser{
+		User:           user,
+		Impersonated:   auth.IsImpersonation(),
+		ImpersonatedBy: auth.ImpersonatorID,
+	}
+}
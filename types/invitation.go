@@ -0,0 +1,41 @@
+package types
+
+import "time"
+
+const InvitationDataType = "invitation"
+
+// Invitation invites an email to sign up in a workspace with the roles. Like tokens,
+// it is identified by the hash of the secret sent to the email, and it is deleted
+// once accepted or revoked.
+type Invitation struct {
+	ID             string `bson:"_id" dynamodbav:"ID" json:"id"`
+	Email          string `bson:"email" dynamodbav:"email" json:"email"`
+	Roles          []Role `bson:"roles,omitempty" dynamodbav:"roles,omitempty" json:"roles,omitempty"`
+	InvitedBy      string `bson:"invitedBy" dynamodbav:"invitedBy" json:"invitedBy"`
+	ExpirationTime int64  `bson:"expirationTime" dynamodbav:"expirationTime" json:"expirationTime"`
+	WorkspaceID    string `bson:"workspaceID,omitempty" dynamodbav:"workspaceID,omitempty" json:"-"`
+	DataType       string `bson:"-" dynamodbav:"dataType" json:"-"`
+}
+
+// NewInvitation returns the invitation to store along with the secret to send to the email.
+func NewInvitation(email string, roles []Role, invitedBy string, ttl time.Duration) (*Invitation, string, error) {
+	secret, err := newTokenSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	return &Invitation{
+		ID:             HashToken(secret),
+		Email:          email,
+		Roles:          roles,
+		InvitedBy:      invitedBy,
+		ExpirationTime: time.Now().Add(ttl).Unix(),
+		DataType:       InvitationDataType,
+	}, secret, nil
+}
+func (i *Invitation) SetWorkspace(workspaceID string) {
+	i.WorkspaceID = workspaceID
+	i.DataType = WorkspaceDataType(workspaceID, InvitationDataType)
+}
+func (i *Invitation) IsValid(now time.Time) bool {
+	return now.Unix() < i.ExpirationTime
+}
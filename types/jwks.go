@@ -0,0 +1,124 @@
+package types
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public key as published in a JSON Web Key Set (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK returns the JWK of an RSA or Ed25519 public key, identified by its
+// RFC 7638 thumbprint.
+func NewJWK(key crypto.PublicKey) (JWK, error) {
+	var jwk JWK
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		jwk = JWK{
+			Kty: "RSA",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		jwk = JWK{
+			Kty: "OKP",
+			Alg: "EdDSA",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}
+	default:
+		return JWK{}, errors.New("unsupported key type, expected an RSA or Ed25519 key")
+	}
+	jwk.Use = "sig"
+	jwk.Kid = jwk.thumbprint()
+	return jwk, nil
+}
+
+// PublicKey returns the RSA, P-256 or Ed25519 public key of the JWK.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case k.Kty == "EC" && k.Crv == "P-256":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s %s", k.Kty, k.Crv)
+	}
+}
+
+// thumbprint hashes the required members of the key in lexicographic order.
+func (k JWK) thumbprint() string {
+	var members any
+	if k.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{k.Crv, k.Kty, k.X}
+	}
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,41 @@
+package types
+
+import "time"
+
+// LoginAttempts counts the failed sign-ins of a key, an email or an IP, since the
+// last successful one.
+type LoginAttempts struct {
+	Key         string    `json:"-"`
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"lastFailure"`
+}
+
+// LockoutPolicy slows password guessing down. After FreeAttempts failures each new
+// attempt waits twice as long as the previous one, from BaseDelay up to MaxDelay,
+// and after LockoutThreshold failures the key is locked for LockoutDuration.
+type LockoutPolicy struct {
+	FreeAttempts     int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+}
+
+// RetryAt returns when the next attempt is allowed, which is the zero time when
+// it is allowed right away.
+func (p LockoutPolicy) RetryAt(attempts *LoginAttempts) time.Time {
+	switch {
+	case attempts.Failures >= p.LockoutThreshold:
+		return attempts.LastFailure.Add(p.LockoutDuration)
+	case attempts.Failures <= p.FreeAttempts:
+		return time.Time{}
+	}
+	delay := p.MaxDelay
+	if shift := attempts.Failures - p.FreeAttempts - 1; shift < 32 {
+		delay = min(p.BaseDelay<<shift, p.MaxDelay)
+	}
+	return attempts.LastFailure.Add(delay)
+}
+func (p LockoutPolicy) IsLocked(attempts *LoginAttempts, now time.Time) bool {
+	return attempts.Failures >= p.LockoutThreshold && now.Before(p.RetryAt(attempts))
+}
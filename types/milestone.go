@@ -0,0 +1,136 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const MilestoneDataType = "milestone"
+
+type Milestone struct {
+	ID          string    `bson:"_id,omitempty" dynamodbav:"ID" json:"id,omitempty"`
+	ProjectID   string    `bson:"projectID" dynamodbav:"projectID" json:"projectID"`
+	Name        string    `bson:"name" dynamodbav:"name" json:"name"`
+	Description string    `bson:"description" dynamodbav:"description" json:"description"`
+	TargetDate  time.Time `bson:"targetDate" dynamodbav:"targetDate" json:"targetDate"`
+	WorkspaceID string    `bson:"workspaceID,omitempty" dynamodbav:"workspaceID,omitempty" json:"-"`
+	DataType    string    `bson:"-" dynamodbav:"dataType" json:"-"`
+}
+
+func (m *Milestone) SetWorkspace(workspaceID string) {
+	m.WorkspaceID = workspaceID
+	m.DataType = WorkspaceDataType(workspaceID, MilestoneDataType)
+}
+
+type NewMilestoneParams struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	TargetDate  time.Time `json:"targetDate"`
+}
+
+func NewMilestoneFromParams(projectID string, params NewMilestoneParams) *Milestone {
+	return &Milestone{
+		ProjectID:   projectID,
+		Name:        params.Name,
+		Description: params.Description,
+		TargetDate:  params.TargetDate,
+		DataType:    MilestoneDataType,
+	}
+}
+func (params NewMilestoneParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(params.Name) < minNameLen {
+		errors["name"] = fmt.Sprintf("Name length should be at least %d", minNameLen)
+	}
+	if len(params.Description) > 0 && len(params.Description) < minDescriptionLen {
+		errors["description"] = fmt.Sprintf("Description length should be at least %d", minDescriptionLen)
+	}
+	if !isDateValid(params.TargetDate) {
+		errors["targetDate"] = fmt.Sprintf("date %v is not valid", params.TargetDate)
+	}
+	return errors
+}
+
+type UpdateMilestoneParams struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	TargetDate  time.Time `json:"targetDate"`
+}
+
+func (params UpdateMilestoneParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(params.Name) == 0 && len(params.Description) == 0 && params.TargetDate.IsZero() {
+		errors["milestone"] = "at least one of name, description or targetDate must be given"
+	}
+	if len(params.Name) > 0 && len(params.Name) < minNameLen {
+		errors["name"] = fmt.Sprintf("Name length should be at least %d", minNameLen)
+	}
+	if len(params.Description) > 0 && len(params.Description) < minDescriptionLen {
+		errors["description"] = fmt.Sprintf("Description length should be at least %d", minDescriptionLen)
+	}
+	if !params.TargetDate.IsZero() && !isDateValid(params.TargetDate) {
+		errors["targetDate"] = fmt.Sprintf("date %v is not valid", params.TargetDate)
+	}
+	return errors
+}
+
+type AssignMilestoneParams struct {
+	TaskID string `json:"taskID"`
+}
+
+func (params AssignMilestoneParams) Validate() error {
+	if len(params.TaskID) == 0 {
+		return fmt.Errorf("taskID is required")
+	}
+	return nil
+}
+
+type MilestoneProgress struct {
+	MilestoneID     string     `json:"milestoneID"`
+	TargetDate      time.Time  `json:"targetDate"`
+	Total           int        `json:"total"`
+	Completed       int        `json:"completed"`
+	Open            int        `json:"open"`
+	PercentComplete float64    `json:"percentComplete"`
+	ProjectedDate   *time.Time `json:"projectedDate,omitempty"`
+	SlipDays        int        `json:"slipDays"`
+	OnTrack         bool       `json:"onTrack"`
+}
+
+// NewMilestoneProgress reports how far along a milestone is. The projected date
+// is the latest due date among its open tasks, where overdue tasks count as due now.
+func NewMilestoneProgress(milestone *Milestone, tasks []*Task, now time.Time) *MilestoneProgress {
+	progress := &MilestoneProgress{
+		MilestoneID: milestone.ID,
+		TargetDate:  milestone.TargetDate,
+	}
+	var projected time.Time
+	for _, task := range tasks {
+		progress.Total++
+		if task.Completed {
+			progress.Completed++
+			continue
+		}
+		progress.Open++
+		expected := task.DueDate
+		if expected.Before(now) {
+			expected = now
+		}
+		if expected.After(projected) {
+			projected = expected
+		}
+	}
+	if progress.Total > 0 {
+		percent := float64(progress.Completed) * 100 / float64(progress.Total)
+		progress.PercentComplete = math.Round(percent*100) / 100
+	}
+	if progress.Open > 0 {
+		progress.ProjectedDate = &projected
+		if slip := projected.Sub(milestone.TargetDate); slip > 0 {
+			progress.SlipDays = int(math.Ceil(slip.Hours() / 24))
+		}
+	}
+	progress.OnTrack = progress.SlipDays == 0
+	return progress
+}
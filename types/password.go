@@ -0,0 +1,133 @@
+package types
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxPasswordBytes is the longest password bcrypt hashes, it ignores the bytes past it.
+	MaxPasswordBytes      = 72
+	DefaultMinPasswordLen = 8
+	minPersonalInfoLen    = 3
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// PasswordPolicy tells which new passwords users can choose. Passwords used to sign
+// in are not checked against it, so existing passwords keep working when it changes.
+type PasswordPolicy struct {
+	MinLength int
+	// MinCharClasses is how many of lowercase letters, uppercase letters, digits and
+	// symbols a password has to mix.
+	MinCharClasses int
+	// Common holds the lowercased passwords that are too common to be accepted.
+	Common map[string]bool
+}
+
+func NewPasswordPolicy(minLength, minCharClasses int) *PasswordPolicy {
+	common, _ := ReadCommonPasswords(strings.NewReader(commonPasswords))
+	return &PasswordPolicy{
+		MinLength:      minLength,
+		MinCharClasses: minCharClasses,
+		Common:         common,
+	}
+}
+
+// ReadCommonPasswords reads a list of passwords, one per line.
+func ReadCommonPasswords(r io.Reader) (map[string]bool, error) {
+	common := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); len(password) > 0 {
+			common[strings.ToLower(password)] = true
+		}
+	}
+	return common, scanner.Err()
+}
+
+var passwordPolicy = NewPasswordPolicy(DefaultMinPasswordLen, 0)
+
+// UsePasswordPolicy sets the policy new passwords are checked with.
+func UsePasswordPolicy(p *PasswordPolicy) {
+	passwordPolicy = p
+}
+
+// WeakPasswordError tells why a password is rejected by the policy.
+type WeakPasswordError struct {
+	Reason string
+}
+
+func (e WeakPasswordError) Error() string {
+	return e.Reason
+}
+
+// CheckPassword checks a new password with the policy in use. The personal info,
+// like the email and the name of the user, can't be part of the password.
+func CheckPassword(password string, personalInfo ...string) error {
+	return passwordPolicy.Check(password, personalInfo...)
+}
+
+func (p *PasswordPolicy) Check(password string, personalInfo ...string) error {
+	if len([]rune(password)) < p.MinLength {
+		return WeakPasswordError{fmt.Sprintf("password length must be at least %d characters", p.MinLength)}
+	}
+	if len(password) > MaxPasswordBytes {
+		return WeakPasswordError{fmt.Sprintf("password length must be at most %d bytes", MaxPasswordBytes)}
+	}
+	if charClasses(password) < p.MinCharClasses {
+		return WeakPasswordError{fmt.Sprintf("password must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.MinCharClasses)}
+	}
+	lower := strings.ToLower(password)
+	if p.Common[lower] {
+		return WeakPasswordError{"password is too common"}
+	}
+	for _, info := range personalInfo {
+		for _, part := range personalInfoParts(info) {
+			if strings.Contains(lower, part) {
+				return WeakPasswordError{"password must not contain your name or email"}
+			}
+		}
+	}
+	return nil
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// personalInfoParts splits the info into the words a password can't contain, like the
+// local part of an email, skipping those too short to matter.
+func personalInfoParts(info string) []string {
+	info = strings.ToLower(info)
+	if at := strings.LastIndex(info, "@"); at >= 0 {
+		info = info[:at]
+	}
+	parts := []string{}
+	for _, part := range strings.FieldsFunc(info, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(part) >= minPersonalInfoLen {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
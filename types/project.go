@@ -2,21 +2,99 @@ package types
 
 import "fmt"
 
+const ProjectDataType = "project"
+
+type ProjectRole string
+
+const (
+	ProjectRoleOwner  ProjectRole = "owner"
+	ProjectRoleEditor ProjectRole = "editor"
+	ProjectRoleViewer ProjectRole = "viewer"
+)
+
+var projectRoleRanks = map[ProjectRole]int{
+	ProjectRoleViewer: 1,
+	ProjectRoleEditor: 2,
+	ProjectRoleOwner:  3,
+}
+
+func (r ProjectRole) IsValid() bool {
+	_, ok := projectRoleRanks[r]
+	return ok
+}
+
+// Includes reports whether r grants at least the permissions of other.
+func (r ProjectRole) Includes(other ProjectRole) bool {
+	return projectRoleRanks[r] >= projectRoleRanks[other]
+}
+
 type Project struct {
-	ID          string   `bson:"_id,omitempty" dynamodbav:"ID" json:"id,omitempty"`
-	Name        string   `bson:"name" dynamodbav:"name" json:"name"`
-	Description string   `bson:"description" dynamodbav:"description" json:"description"`
-	UserID      string   `bson:"userID" dynamodbav:"userID" json:"userID"`
-	Tasks       []string `bson:"tasks" dynamodbav:"tasks" json:"tasks"`
+	ID          string                 `bson:"_id,omitempty" dynamodbav:"ID" json:"id,omitempty"`
+	Name        string                 `bson:"name" dynamodbav:"name" json:"name"`
+	Description string                 `bson:"description" dynamodbav:"description" json:"description"`
+	UserID      string                 `bson:"userID" dynamodbav:"userID" json:"userID"`
+	Tasks       []string               `bson:"tasks" dynamodbav:"tasks" json:"tasks"`
+	Members     map[string]ProjectRole `bson:"members" dynamodbav:"members" json:"members"`
+	Archived    bool                   `bson:"archived" dynamodbav:"archived" json:"archived"`
+	WorkspaceID string                 `bson:"workspaceID,omitempty" dynamodbav:"workspaceID,omitempty" json:"-"`
+	DataType    string                 `bson:"-" dynamodbav:"dataType" json:"-"`
+}
+
+func (project *Project) SetWorkspace(workspaceID string) {
+	project.WorkspaceID = workspaceID
+	project.DataType = WorkspaceDataType(workspaceID, ProjectDataType)
 }
 
 func (project *Project) ContainsTask(taskID string) bool {
-	for _, id := range project.Tasks {
+	return project.TaskIndex(taskID) >= 0
+}
+func (project *Project) TaskIndex(taskID string) int {
+	for i, id := range project.Tasks {
 		if id == taskID {
-			return true
+			return i
+		}
+	}
+	return -1
+}
+func (project *Project) GetRole(userID string) (ProjectRole, bool) {
+	if len(project.Members) == 0 && project.UserID == userID {
+		return ProjectRoleOwner, true
+	}
+	role, ok := project.Members[userID]
+	return role, ok
+}
+func (project *Project) HasRole(userID string, role ProjectRole) bool {
+	memberRole, ok := project.GetRole(userID)
+	if !ok {
+		return false
+	}
+	return memberRole.Includes(role)
+}
+func (project *Project) CountOwners() int {
+	owners := 0
+	for _, role := range project.Members {
+		if role == ProjectRoleOwner {
+			owners++
 		}
 	}
-	return false
+	return owners
+}
+
+// Successor returns the member with the highest role other than userID, the one
+// who takes over the project when userID leaves it.
+func (project *Project) Successor(userID string) (string, bool) {
+	var successor string
+	for memberID, role := range project.Members {
+		if memberID == userID {
+			continue
+		}
+		current := project.Members[successor]
+		if len(successor) == 0 || projectRoleRanks[role] > projectRoleRanks[current] ||
+			(role == current && memberID < successor) {
+			successor = memberID
+		}
+	}
+	return successor, len(successor) > 0
 }
 
 type NewProjectParams struct {
@@ -29,6 +107,8 @@ func NewProjectFromParams(params NewProjectParams) *Project {
 		Name:        params.Name,
 		Description: params.Description,
 		Tasks:       []string{},
+		Members:     map[string]ProjectRole{},
+		DataType:    ProjectDataType,
 	}
 }
 func (params NewProjectParams) Validate() map[string]string {
@@ -42,6 +122,98 @@ func (params NewProjectParams) Validate() map[string]string {
 	return errors
 }
 
+type UpdateProjectParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (params UpdateProjectParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(params.Name) == 0 && len(params.Description) == 0 {
+		errors["project"] = "at least one of name or description must be given"
+	}
+	if len(params.Name) > 0 && len(params.Name) < minNameLen {
+		errors["title"] = fmt.Sprintf("Title length should be at least %d", minNameLen)
+	}
+	if len(params.Description) > 0 && len(params.Description) < minDescriptionLen {
+		errors["description"] = fmt.Sprintf("Description length should be at least %d", minDescriptionLen)
+	}
+	return errors
+}
+
+const (
+	DetachProjectTasks = "detach"
+	DeleteProjectTasks = "delete"
+)
+
+type DeleteProjectParams struct {
+	Tasks string `query:"tasks"`
+}
+
+func (params DeleteProjectParams) Validate() error {
+	switch params.Tasks {
+	case DetachProjectTasks, DeleteProjectTasks:
+		return nil
+	default:
+		return fmt.Errorf("tasks must be either %s or %s", DetachProjectTasks, DeleteProjectTasks)
+	}
+}
+
+type CloneProjectParams struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	IncludeTasks bool   `json:"includeTasks"`
+}
+
+func (params CloneProjectParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(params.Name) > 0 && len(params.Name) < minNameLen {
+		errors["title"] = fmt.Sprintf("Title length should be at least %d", minNameLen)
+	}
+	if len(params.Description) > 0 && len(params.Description) < minDescriptionLen {
+		errors["description"] = fmt.Sprintf("Description length should be at least %d", minDescriptionLen)
+	}
+	return errors
+}
+
 type AddTaskParams struct {
 	TaskID string `json:"taskID"`
 }
+
+type MoveTaskParams struct {
+	ProjectID string `json:"projectID"`
+}
+
+func (params MoveTaskParams) Validate() error {
+	if len(params.ProjectID) == 0 {
+		return fmt.Errorf("projectID is required")
+	}
+	return nil
+}
+
+type AddMemberParams struct {
+	Email string      `json:"email"`
+	Role  ProjectRole `json:"role"`
+}
+
+func (params AddMemberParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if !isEmailValid(params.Email) {
+		errors["email"] = fmt.Sprintf("email %s is invalid", params.Email)
+	}
+	if !params.Role.IsValid() {
+		errors["role"] = fmt.Sprintf("role %s is invalid", params.Role)
+	}
+	return errors
+}
+
+type UpdateMemberRoleParams struct {
+	Role ProjectRole `json:"role"`
+}
+
+func (params UpdateMemberRoleParams) Validate() error {
+	if !params.Role.IsValid() {
+		return fmt.Errorf("role %s is invalid", params.Role)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package types
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	ProjectStatsTrendDays = 30
+	UnassignedTasksKey    = "unassigned"
+	trendDateLayout       = "2006-01-02"
+)
+
+// TaskSummary is the subset of a task needed to compute project statistics.
+type TaskSummary struct {
+	Completed   bool       `dynamodbav:"completed"`
+	AssignedTo  string     `dynamodbav:"assignedTo"`
+	DueDate     time.Time  `dynamodbav:"dueDate"`
+	CompletedAt *time.Time `dynamodbav:"completedAt,omitempty"`
+}
+
+func NewTaskSummary(task *Task) TaskSummary {
+	return TaskSummary{
+		Completed:   task.Completed,
+		AssignedTo:  task.AssignedTo,
+		DueDate:     task.DueDate,
+		CompletedAt: task.CompletedAt,
+	}
+}
+
+type AssigneeStats struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Open      int `json:"open"`
+}
+
+type TrendPoint struct {
+	Date      string `json:"date"`
+	Completed int    `json:"completed"`
+}
+
+type ProjectStats struct {
+	ProjectID       string                    `json:"projectID"`
+	Total           int                       `json:"total"`
+	Completed       int                       `json:"completed"`
+	Open            int                       `json:"open"`
+	PercentComplete float64                   `json:"percentComplete"`
+	Overdue         int                       `json:"overdue"`
+	DueThisWeek     int                       `json:"dueThisWeek"`
+	ByAssignee      map[string]*AssigneeStats `json:"byAssignee"`
+	Trend           []TrendPoint              `json:"trend"`
+}
+
+func NewEmptyProjectStats(projectID string) *ProjectStats {
+	return &ProjectStats{
+		ProjectID:  projectID,
+		ByAssignee: map[string]*AssigneeStats{},
+		Trend:      []TrendPoint{},
+	}
+}
+
+// NewProjectStats computes the statistics of a project from its task summaries.
+func NewProjectStats(projectID string, tasks []TaskSummary, now time.Time) *ProjectStats {
+	var (
+		stats       = NewEmptyProjectStats(projectID)
+		weekEnd     = now.AddDate(0, 0, 7)
+		trendStart  = TrendStart(now)
+		completions = map[string]int{}
+	)
+	for _, task := range tasks {
+		assignee := task.AssignedTo
+		if len(assignee) == 0 {
+			assignee = UnassignedTasksKey
+		}
+		if _, ok := stats.ByAssignee[assignee]; !ok {
+			stats.ByAssignee[assignee] = &AssigneeStats{}
+		}
+		stats.ByAssignee[assignee].Total++
+		stats.Total++
+		if task.Completed {
+			stats.Completed++
+			stats.ByAssignee[assignee].Completed++
+			if task.CompletedAt != nil && !task.CompletedAt.Before(trendStart) {
+				completions[task.CompletedAt.UTC().Format(trendDateLayout)]++
+			}
+			continue
+		}
+		stats.Open++
+		stats.ByAssignee[assignee].Open++
+		switch {
+		case task.DueDate.Before(now):
+			stats.Overdue++
+		case task.DueDate.Before(weekEnd):
+			stats.DueThisWeek++
+		}
+	}
+	stats.SetPercentComplete()
+	stats.SetTrend(completions, now)
+	return stats
+}
+
+func (stats *ProjectStats) SetPercentComplete() {
+	if stats.Total == 0 {
+		stats.PercentComplete = 0
+		return
+	}
+	percent := float64(stats.Completed) * 100 / float64(stats.Total)
+	stats.PercentComplete = math.Round(percent*100) / 100
+}
+
+// SetTrend fills the daily completion trend, keyed by day, including days without completions.
+func (stats *ProjectStats) SetTrend(completions map[string]int, now time.Time) {
+	stats.Trend = make([]TrendPoint, 0, ProjectStatsTrendDays)
+	for day := TrendStart(now); !day.After(now); day = day.AddDate(0, 0, 1) {
+		date := day.Format(trendDateLayout)
+		stats.Trend = append(stats.Trend, TrendPoint{Date: date, Completed: completions[date]})
+	}
+}
+
+func TrendStart(now time.Time) time.Time {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return today.AddDate(0, 0, -(ProjectStatsTrendDays - 1))
+}
@@ -0,0 +1,101 @@
+package types
+
+import "fmt"
+
+type Role string
+
+const (
+	// RoleAdmin holds every permission.
+	RoleAdmin Role = "admin"
+	// RoleSupport manages the accounts of users, but not their tasks.
+	RoleSupport Role = "support"
+)
+
+var Roles = []Role{
+	RoleAdmin,
+	RoleSupport,
+}
+
+func (r Role) IsValid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type Permission string
+
+const (
+	PermissionTaskReadAny        Permission = "task.read.any"
+	PermissionTaskAssign         Permission = "task.assign"
+	PermissionTaskDelete         Permission = "task.delete"
+	PermissionProjectReadAny     Permission = "project.read.any"
+	PermissionUserRead           Permission = "user.read"
+	PermissionUserEnable         Permission = "user.enable"
+	PermissionUserDisable        Permission = "user.disable"
+	PermissionUserUnlock         Permission = "user.unlock"
+	PermissionUserImpersonate    Permission = "user.impersonate"
+	PermissionUserDelete         Permission = "user.delete"
+	PermissionUserSessionRevoke  Permission = "user.session.revoke"
+	PermissionUserTwoFactorReset Permission = "user.2fa.reset"
+	PermissionRoleAssign         Permission = "role.assign"
+	PermissionUserInvite         Permission = "user.invite"
+	PermissionWorkspaceManage    Permission = "workspace.manage"
+	PermissionWorkspaceCreate    Permission = "workspace.create"
+)
+
+var Permissions = []Permission{
+	PermissionTaskReadAny,
+	PermissionTaskAssign,
+	PermissionTaskDelete,
+	PermissionProjectReadAny,
+	PermissionUserRead,
+	PermissionUserEnable,
+	PermissionUserDisable,
+	PermissionUserUnlock,
+	PermissionUserImpersonate,
+	PermissionUserDelete,
+	PermissionUserSessionRevoke,
+	PermissionUserTwoFactorReset,
+	PermissionRoleAssign,
+	PermissionUserInvite,
+	PermissionWorkspaceManage,
+	PermissionWorkspaceCreate,
+}
+
+type RoleDefinition struct {
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+type AssignRolesParams struct {
+	Roles []Role `json:"roles"`
+}
+
+func (p AssignRolesParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if p.Roles == nil {
+		errors["roles"] = "roles are required, send an empty list to remove them all"
+	}
+	for _, role := range p.Roles {
+		if !role.IsValid() {
+			errors["roles"] = fmt.Sprintf("role %s is invalid", role)
+		}
+	}
+	return errors
+}
+
+type BulkRolesParams struct {
+	BulkUserParams
+	Roles []Role `json:"roles"`
+}
+
+func (p BulkRolesParams) Validate() map[string]string {
+	errors := p.BulkUserParams.Validate()
+	for field, err := range (AssignRolesParams{Roles: p.Roles}).Validate() {
+		errors[field] = err
+	}
+	return errors
+}
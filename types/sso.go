@@ -0,0 +1,36 @@
+package types
+
+// SSOLogin is where to send the user to sign in with the identity provider, along
+// with the state to keep in its browser until it comes back.
+type SSOLogin struct {
+	URL        string
+	StateToken string
+}
+
+type SSOCallbackParams struct {
+	Code             string `query:"code"`
+	State            string `query:"state"`
+	Error            string `query:"error"`
+	ErrorDescription string `query:"error_description"`
+	StateToken       string `query:"-"`
+	IP               string `query:"-"`
+	UserAgent        string `query:"-"`
+}
+
+func (p SSOCallbackParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.Error) > 0 {
+		errors["error"] = p.Error
+		if len(p.ErrorDescription) > 0 {
+			errors["error_description"] = p.ErrorDescription
+		}
+		return errors
+	}
+	if len(p.Code) == 0 {
+		errors["code"] = "code is required"
+	}
+	if len(p.State) == 0 {
+		errors["state"] = "state is required"
+	}
+	return errors
+}
@@ -8,20 +8,59 @@ import (
 const TaskDataType = "task"
 
 type Task struct {
-	ID          string    `bson:"_id,omitempty" dynamodbav:"ID" json:"id,omitempty"`
-	Name        string    `bson:"name" dynamodbav:"name" json:"name"`
-	Description string    `bson:"description,omitempty" dynamodbav:"description" json:"description,omitempty"`
-	DueDate     time.Time `bson:"dueDate" dynamodbav:"dueDate" json:"dueDate"`
-	Completed   bool      `bson:"completed" dynamodbav:"completed" json:"completed"`
-	AssignedTo  string    `bson:"assignedTo" dynamodbav:"assignedTo" json:"assignedTo,omitempty"`
-	ProjectID   string    `bson:"projectID" dynamodbav:"projectID" json:"projectID,omitempty"`
-	DataType    string    `bson:"-" dynamodbav:"dataType" json:"-"`
+	ID          string          `bson:"_id,omitempty" dynamodbav:"ID" json:"id,omitempty"`
+	Name        string          `bson:"name" dynamodbav:"name" json:"name"`
+	Description string          `bson:"description,omitempty" dynamodbav:"description" json:"description,omitempty"`
+	DueDate     time.Time       `bson:"dueDate" dynamodbav:"dueDate" json:"dueDate"`
+	Completed   bool            `bson:"completed" dynamodbav:"completed" json:"completed"`
+	CompletedAt *time.Time      `bson:"completedAt,omitempty" dynamodbav:"completedAt,omitempty" json:"completedAt,omitempty"`
+	AssignedTo  string          `bson:"assignedTo" dynamodbav:"assignedTo" json:"assignedTo,omitempty"`
+	TeamID      string          `bson:"teamID,omitempty" dynamodbav:"teamID,omitempty" json:"teamID,omitempty"`
+	ProjectID   string          `bson:"projectID" dynamodbav:"projectID,omitempty" json:"projectID,omitempty"`
+	MilestoneID string          `bson:"milestoneID,omitempty" dynamodbav:"milestoneID,omitempty" json:"milestoneID,omitempty"`
+	Labels      []string        `bson:"labels,omitempty" dynamodbav:"labels,omitempty" json:"labels,omitempty"`
+	Checklist   []ChecklistItem `bson:"checklist,omitempty" dynamodbav:"checklist,omitempty" json:"checklist,omitempty"`
+	WorkspaceID string          `bson:"workspaceID,omitempty" dynamodbav:"workspaceID,omitempty" json:"-"`
+	DataType    string          `bson:"-" dynamodbav:"dataType" json:"-"`
+}
+
+func (task *Task) SetWorkspace(workspaceID string) {
+	task.WorkspaceID = workspaceID
+	task.DataType = WorkspaceDataType(workspaceID, TaskDataType)
+}
+
+// Duplicate returns a copy of the task without its ID, completion and assignee. The
+// copy stays in the project and team of the task.
+func (task *Task) Duplicate() *Task {
+	checklist := make([]ChecklistItem, 0, len(task.Checklist))
+	for _, item := range task.Checklist {
+		checklist = append(checklist, ChecklistItem{Text: item.Text})
+	}
+	labels := make([]string, len(task.Labels))
+	copy(labels, task.Labels)
+	duplicate := NewTaskFromParams(NewTaskParams{
+		Name:        task.Name,
+		Description: task.Description,
+		DueDate:     task.DueDate,
+		Labels:      labels,
+		Checklist:   checklist,
+	})
+	duplicate.ProjectID = task.ProjectID
+	duplicate.TeamID = task.TeamID
+	return duplicate
+}
+
+type ChecklistItem struct {
+	Text string `bson:"text" dynamodbav:"text" json:"text"`
+	Done bool   `bson:"done" dynamodbav:"done" json:"done"`
 }
 
 type NewTaskParams struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	DueDate     time.Time `json:"dueDate"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	DueDate     time.Time       `json:"dueDate"`
+	Labels      []string        `json:"labels"`
+	Checklist   []ChecklistItem `json:"checklist"`
 }
 
 func NewTaskFromParams(params NewTaskParams) *Task {
@@ -29,6 +68,8 @@ func NewTaskFromParams(params NewTaskParams) *Task {
 		Name:        params.Name,
 		Description: params.Description,
 		DueDate:     params.DueDate,
+		Labels:      params.Labels,
+		Checklist:   params.Checklist,
 		DataType:    TaskDataType,
 	}
 }
@@ -43,6 +84,16 @@ func (params NewTaskParams) Validate() map[string]string {
 	if !isDateValid(params.DueDate) {
 		errors["dueDate"] = fmt.Sprintf("date %v is not valid", params.DueDate)
 	}
+	for _, label := range params.Labels {
+		if len(label) == 0 {
+			errors["labels"] = "labels cannot be empty"
+		}
+	}
+	for _, item := range params.Checklist {
+		if len(item.Text) == 0 {
+			errors["checklist"] = "checklist items need a text"
+		}
+	}
 	return errors
 }
 func isDateValid(date time.Time) bool {
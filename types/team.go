@@ -0,0 +1,99 @@
+package types
+
+import "fmt"
+
+const TeamDataType = "team"
+
+// Team is a group of users that can own tasks until one of its members picks them up.
+type Team struct {
+	ID          string   `bson:"_id,omitempty" dynamodbav:"ID" json:"id,omitempty"`
+	Name        string   `bson:"name" dynamodbav:"name" json:"name"`
+	Description string   `bson:"description" dynamodbav:"description" json:"description"`
+	LeadID      string   `bson:"leadID" dynamodbav:"leadID" json:"leadID"`
+	Members     []string `bson:"members" dynamodbav:"members" json:"members"`
+	WorkspaceID string   `bson:"workspaceID,omitempty" dynamodbav:"workspaceID,omitempty" json:"-"`
+	DataType    string   `bson:"-" dynamodbav:"dataType" json:"-"`
+}
+
+func (t *Team) SetWorkspace(workspaceID string) {
+	t.WorkspaceID = workspaceID
+	t.DataType = WorkspaceDataType(workspaceID, TeamDataType)
+}
+
+// NewTeamFromParams returns a team led by the user, its first member.
+func NewTeamFromParams(leadID string, params NewTeamParams) *Team {
+	return &Team{
+		Name:        params.Name,
+		Description: params.Description,
+		LeadID:      leadID,
+		Members:     []string{leadID},
+		DataType:    TeamDataType,
+	}
+}
+func (t *Team) IsMember(userID string) bool {
+	for _, member := range t.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+type NewTeamParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (params NewTeamParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(params.Name) < minNameLen {
+		errors["name"] = fmt.Sprintf("Name length should be at least %d", minNameLen)
+	}
+	if len(params.Description) > 0 && len(params.Description) < minDescriptionLen {
+		errors["description"] = fmt.Sprintf("Description length should be at least %d", minDescriptionLen)
+	}
+	return errors
+}
+
+type UpdateTeamParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	LeadID      string `json:"leadID"`
+}
+
+func (params UpdateTeamParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(params.Name) == 0 && len(params.Description) == 0 && len(params.LeadID) == 0 {
+		errors["team"] = "at least one of name, description or leadID must be given"
+	}
+	if len(params.Name) > 0 && len(params.Name) < minNameLen {
+		errors["name"] = fmt.Sprintf("Name length should be at least %d", minNameLen)
+	}
+	if len(params.Description) > 0 && len(params.Description) < minDescriptionLen {
+		errors["description"] = fmt.Sprintf("Description length should be at least %d", minDescriptionLen)
+	}
+	return errors
+}
+
+type AddTeamMemberParams struct {
+	Email string `json:"email"`
+}
+
+func (params AddTeamMemberParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if !isEmailValid(params.Email) {
+		errors["email"] = fmt.Sprintf("email %s is invalid", params.Email)
+	}
+	return errors
+}
+
+type AssignTeamParams struct {
+	TeamID string `json:"teamID"`
+}
+
+func (params AssignTeamParams) Validate() error {
+	if len(params.TeamID) == 0 {
+		return fmt.Errorf("teamID is required")
+	}
+	return nil
+}
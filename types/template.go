@@ -0,0 +1,147 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const TemplateDataType = "template"
+
+type TemplateKind string
+
+const (
+	ProjectTemplate TemplateKind = "project"
+	TaskTemplate    TemplateKind = "task"
+)
+
+func (k TemplateKind) IsValid() bool {
+	return k == ProjectTemplate || k == TaskTemplate
+}
+
+// TemplateTask is a task stripped of its state. Its due date is kept as an
+// offset in days from the start date the template is instantiated with.
+type TemplateTask struct {
+	Name          string          `bson:"name" dynamodbav:"name" json:"name"`
+	Description   string          `bson:"description" dynamodbav:"description" json:"description"`
+	DueOffsetDays int             `bson:"dueOffsetDays" dynamodbav:"dueOffsetDays" json:"dueOffsetDays"`
+	Labels        []string        `bson:"labels,omitempty" dynamodbav:"labels,omitempty" json:"labels,omitempty"`
+	Checklist     []ChecklistItem `bson:"checklist,omitempty" dynamodbav:"checklist,omitempty" json:"checklist,omitempty"`
+}
+
+func NewTemplateTask(task *Task, start time.Time) TemplateTask {
+	offset := int(math.Round(task.DueDate.Sub(start).Hours() / 24))
+	if offset < 0 {
+		offset = 0
+	}
+	checklist := make([]ChecklistItem, 0, len(task.Checklist))
+	for _, item := range task.Checklist {
+		checklist = append(checklist, ChecklistItem{Text: item.Text})
+	}
+	return TemplateTask{
+		Name:          task.Name,
+		Description:   task.Description,
+		DueOffsetDays: offset,
+		Labels:        task.Labels,
+		Checklist:     checklist,
+	}
+}
+
+// NewTask returns a fresh task due offset days after start.
+func (t TemplateTask) NewTask(start time.Time) *Task {
+	checklist := make([]ChecklistItem, len(t.Checklist))
+	copy(checklist, t.Checklist)
+	return NewTaskFromParams(NewTaskParams{
+		Name:        t.Name,
+		Description: t.Description,
+		DueDate:     start.AddDate(0, 0, t.DueOffsetDays),
+		Labels:      t.Labels,
+		Checklist:   checklist,
+	})
+}
+
+type Template struct {
+	ID          string         `bson:"_id,omitempty" dynamodbav:"ID" json:"id,omitempty"`
+	Kind        TemplateKind   `bson:"kind" dynamodbav:"kind" json:"kind"`
+	Name        string         `bson:"name" dynamodbav:"name" json:"name"`
+	Description string         `bson:"description" dynamodbav:"description" json:"description"`
+	UserID      string         `bson:"userID" dynamodbav:"userID" json:"userID"`
+	Tasks       []TemplateTask `bson:"tasks" dynamodbav:"tasks" json:"tasks"`
+	WorkspaceID string         `bson:"workspaceID,omitempty" dynamodbav:"workspaceID,omitempty" json:"-"`
+	DataType    string         `bson:"-" dynamodbav:"dataType" json:"-"`
+}
+
+func (t *Template) SetWorkspace(workspaceID string) {
+	t.WorkspaceID = workspaceID
+	t.DataType = WorkspaceDataType(workspaceID, TemplateDataType)
+}
+
+// NewProjectTemplate keeps the tasks due dates relative to the earliest one.
+func NewProjectTemplate(project *Project, tasks []*Task, userID string, params NewTemplateParams) *Template {
+	var start time.Time
+	for _, task := range tasks {
+		if start.IsZero() || task.DueDate.Before(start) {
+			start = task.DueDate
+		}
+	}
+	template := newTemplate(ProjectTemplate, project.Name, project.Description, userID, params)
+	for _, task := range tasks {
+		template.Tasks = append(template.Tasks, NewTemplateTask(task, start))
+	}
+	return template
+}
+
+// NewTaskTemplate keeps the task due date relative to the moment it is saved.
+func NewTaskTemplate(task *Task, userID string, params NewTemplateParams) *Template {
+	template := newTemplate(TaskTemplate, task.Name, task.Description, userID, params)
+	template.Tasks = append(template.Tasks, NewTemplateTask(task, time.Now()))
+	return template
+}
+
+func newTemplate(kind TemplateKind, name, description, userID string, params NewTemplateParams) *Template {
+	if len(params.Name) > 0 {
+		name = params.Name
+	}
+	if len(params.Description) > 0 {
+		description = params.Description
+	}
+	return &Template{
+		Kind:        kind,
+		Name:        name,
+		Description: description,
+		UserID:      userID,
+		Tasks:       []TemplateTask{},
+		DataType:    TemplateDataType,
+	}
+}
+
+type NewTemplateParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (params NewTemplateParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(params.Name) > 0 && len(params.Name) < minNameLen {
+		errors["name"] = fmt.Sprintf("Name length should be at least %d", minNameLen)
+	}
+	if len(params.Description) > 0 && len(params.Description) < minDescriptionLen {
+		errors["description"] = fmt.Sprintf("Description length should be at least %d", minDescriptionLen)
+	}
+	return errors
+}
+
+type InstantiateTemplateParams struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	StartDate   time.Time `json:"startDate"`
+}
+
+func (params InstantiateTemplateParams) Validate() map[string]string {
+	errors := NewTemplateParams{Name: params.Name, Description: params.Description}.Validate()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if params.StartDate.Before(today) {
+		errors["startDate"] = fmt.Sprintf("date %v is not valid", params.StartDate)
+	}
+	return errors
+}
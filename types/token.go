@@ -0,0 +1,53 @@
+package types
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+type TokenPurpose string
+
+const (
+	PasswordResetToken TokenPurpose = "password-reset"
+	tokenSecretLen                  = 32
+)
+
+// Token is a single-use token sent to a user. Only the hash of its secret is
+// stored, so the secret can't be recovered from the database.
+type Token struct {
+	ID             string       `bson:"_id" dynamodbav:"ID"`
+	UserID         string       `bson:"userID" dynamodbav:"userID"`
+	Purpose        TokenPurpose `bson:"purpose" dynamodbav:"purpose"`
+	ExpirationTime int64        `bson:"expirationTime" dynamodbav:"expirationTime"`
+}
+
+// NewToken returns the token to store along with the secret to send to the user.
+func NewToken(userID string, purpose TokenPurpose, ttl time.Duration) (*Token, string, error) {
+	secret, err := newTokenSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	return &Token{
+		ID:             HashToken(secret),
+		UserID:         userID,
+		Purpose:        purpose,
+		ExpirationTime: time.Now().Add(ttl).Unix(),
+	}, secret, nil
+}
+func newTokenSecret() (string, error) {
+	b := make([]byte, tokenSecretLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+func (t *Token) IsValid(purpose TokenPurpose, now time.Time) bool {
+	return t.Purpose == purpose && now.Unix() < t.ExpirationTime
+}
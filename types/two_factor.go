@@ -0,0 +1,175 @@
+package types
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	TOTPIssuer        = "gotasks"
+	totpPeriod        = 30
+	totpDigits        = 6
+	totpModulo        = 1000000
+	totpSecretLen     = 20
+	totpSkewSteps     = 1
+	recoveryCodeCount = 10
+	recoveryCodeLen   = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPEnrollment is the secret to add to an authenticator app, also as an
+// otpauth URI to show as a QR code.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+func NewTOTPEnrollment(email string) (*TOTPEnrollment, error) {
+	b := make([]byte, totpSecretLen)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	secret := totpEncoding.EncodeToString(b)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", TOTPIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + TOTPIssuer + ":" + email,
+		RawQuery: query.Encode(),
+	}
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    uri.String(),
+	}, nil
+}
+
+// ValidateTOTP checks the code against the steps around now (RFC 6238). Steps up to
+// lastStep were already used and are rejected, so a code can't be replayed. It
+// returns the step the code belongs to.
+func ValidateTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkewSteps; step <= current+totpSkewSteps; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// TOTPCode returns the code of the secret for the given time.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+	return totpCode(key, t.Unix()/totpPeriod), nil
+}
+func totpCode(key []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulo)
+}
+
+// NewRecoveryCodes returns single-use codes to sign in without the authenticator,
+// along with their hashes to store.
+func NewRecoveryCodes() ([]string, []string, error) {
+	var (
+		codes  = make([]string, 0, recoveryCodeCount)
+		hashes = make([]string, 0, recoveryCodeCount)
+	)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeLen)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(b)[:recoveryCodeLen])
+		code = code[:recoveryCodeLen/2] + "-" + code[recoveryCodeLen/2:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorChallenge is returned by the sign in instead of the tokens when the user
+// has to complete it with a second factor.
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+}
+
+type TOTPParams struct {
+	Code string `json:"code"`
+}
+
+func (p TOTPParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.Code) != totpDigits {
+		errors["code"] = fmt.Sprintf("code must be %d digits", totpDigits)
+	}
+	return errors
+}
+
+// TwoFactorAuthParams completes a sign in with a TOTP or a recovery code.
+type TwoFactorAuthParams struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+	IP             string `json:"-"`
+	UserAgent      string `json:"-"`
+}
+
+func (p TwoFactorAuthParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.ChallengeToken) == 0 {
+		errors["challengeToken"] = "challengeToken is required"
+	}
+	if len(p.Code) == 0 {
+		errors["code"] = "code is required"
+	}
+	return errors
+}
+
+type DisableTwoFactorParams struct {
+	CurrentPassword string `json:"currentPassword"`
+	Code            string `json:"code"`
+}
+
+func (p DisableTwoFactorParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.CurrentPassword) == 0 {
+		errors["currentPassword"] = "currentPassword is required"
+	}
+	if len(p.Code) == 0 {
+		errors["code"] = "code is required"
+	}
+	return errors
+}
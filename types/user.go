@@ -3,6 +3,7 @@ package types
 import (
 	"fmt"
 	"regexp"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -13,6 +14,7 @@ const (
 	minFirstNameLen     = 5
 	minLastNameLen      = 5
 	defaultCost     int = 12
+	maxBulkUsers        = 100
 )
 
 type User struct {
@@ -21,9 +23,26 @@ type User struct {
 	LastName          string `bson:"lastName" dynamodbav:"lastName" json:"lastName"`
 	Email             string `bson:"email" dynamodbav:"email" json:"email"`
 	EncryptedPassword string `bson:"encryptedPassword" dynamodbav:"encryptedPassword" json:"-"`
-	IsAdmin           bool   `bson:"isAdmin" dynamodbav:"isAdmin" json:"-"`
 	Enabled           bool   `bson:"enabled" dynamodbav:"enabled" json:"-"`
+	Verified          bool   `bson:"verified" dynamodbav:"verified" json:"verified"`
+	WorkspaceID       string `bson:"workspaceID,omitempty" dynamodbav:"workspaceID,omitempty" json:"workspaceID,omitempty"`
 	DataType          string `bson:"-" dynamodbav:"dataType" json:"-"`
+
+	TOTPSecret    string   `bson:"totpSecret,omitempty" dynamodbav:"totpSecret,omitempty" json:"-"`
+	TOTPEnabled   bool     `bson:"totpEnabled" dynamodbav:"totpEnabled" json:"twoFactorEnabled"`
+	TOTPLastStep  int64    `bson:"totpLastStep" dynamodbav:"totpLastStep" json:"-"`
+	RecoveryCodes []string `bson:"recoveryCodes,omitempty" dynamodbav:"recoveryCodes,omitempty" json:"-"`
+
+	SSOSubject string `bson:"ssoSubject,omitempty" dynamodbav:"ssoSubject,omitempty" json:"-"`
+
+	Roles []Role `bson:"roles,omitempty" dynamodbav:"roles,omitempty" json:"roles,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt" dynamodbav:"createdAt" json:"createdAt"`
+}
+
+func (u *User) SetWorkspace(workspaceID string) {
+	u.WorkspaceID = workspaceID
+	u.DataType = WorkspaceDataType(workspaceID, UserDataType)
 }
 
 func NewUserFromParams(params CreateUserParams) (*User, error) {
@@ -37,8 +56,38 @@ func NewUserFromParams(params CreateUserParams) (*User, error) {
 		Email:             params.Email,
 		EncryptedPassword: encpw,
 		DataType:          UserDataType,
+		CreatedAt:         newCreatedAt(),
 	}, nil
 }
+
+// NewSSOUser returns a user signed up through the identity provider, which already
+// verified its email. It has no password, so it can only sign in with single sign-on.
+func NewSSOUser(subject, email, firstName, lastName string) *User {
+	return &User{
+		FirstName:  firstName,
+		LastName:   lastName,
+		Email:      email,
+		Enabled:    true,
+		Verified:   true,
+		SSOSubject: subject,
+		DataType:   UserDataType,
+		CreatedAt:  newCreatedAt(),
+	}
+}
+
+// newCreatedAt truncates the time to the second, so DynamoDB can compare the stored
+// RFC 3339 strings of creation times.
+func newCreatedAt() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+func (u *User) HasRole(role Role) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
 func (u *User) IsPasswordValid(pw string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(u.EncryptedPassword), []byte(pw)) == nil
 
@@ -53,14 +102,14 @@ type CreateUserParams struct {
 
 func (p CreateUserParams) Validate() map[string]string {
 	errors := map[string]string{}
-	if len(p.Password) < minFirstNameLen {
+	if len(p.FirstName) < minFirstNameLen {
 		errors["firstName"] = fmt.Sprintf("firstName length must be at least %d characters", minFirstNameLen)
 	}
-	if len(p.Password) < minLastNameLen {
+	if len(p.LastName) < minLastNameLen {
 		errors["lastName"] = fmt.Sprintf("lastName length must be at least %d characters", minLastNameLen)
 	}
-	if len(p.Password) < minPasswordLen {
-		errors["password"] = fmt.Sprintf("password length must be at least %d characters", minPasswordLen)
+	if err := CheckPassword(p.Password, p.Email, p.FirstName, p.LastName); err != nil {
+		errors["password"] = err.Error()
 	}
 	if !isEmailValid(p.Email) {
 		errors["email"] = fmt.Sprintf("email %s is invalid", p.Email)
@@ -79,6 +128,18 @@ func generateEncryptedPassword(password string) (string, error) {
 	return string(encpw), nil
 }
 
+type ResendVerificationParams struct {
+	Email string `json:"email"`
+}
+
+func (p ResendVerificationParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if !isEmailValid(p.Email) {
+		errors["email"] = fmt.Sprintf("email %s is invalid", p.Email)
+	}
+	return errors
+}
+
 type ResetPasswordParams struct {
 	CurrentPassword string `json:"currentPassword"`
 	NewPassword     string `json:"newPassword"`
@@ -89,11 +150,108 @@ func (p ResetPasswordParams) Validate() map[string]string {
 	if len(p.CurrentPassword) < minPasswordLen {
 		errors["currentPassword"] = fmt.Sprintf("current password length must be at least %d characters", minPasswordLen)
 	}
-	if len(p.NewPassword) < minPasswordLen {
-		errors["newPassword"] = fmt.Sprintf("new password length must be at least %d characters", minPasswordLen)
+	if err := CheckPassword(p.NewPassword); err != nil {
+		errors["newPassword"] = err.Error()
 	}
 	return errors
 }
 func (p ResetPasswordParams) GeneratePassword() (string, error) {
 	return generateEncryptedPassword(p.NewPassword)
 }
+
+type UpdateUserParams struct {
+	FirstName       string `json:"firstName"`
+	LastName        string `json:"lastName"`
+	Email           string `json:"email"`
+	CurrentPassword string `json:"currentPassword"`
+}
+
+func (p UpdateUserParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.FirstName) > 0 && len(p.FirstName) < minFirstNameLen {
+		errors["firstName"] = fmt.Sprintf("firstName length must be at least %d characters", minFirstNameLen)
+	}
+	if len(p.LastName) > 0 && len(p.LastName) < minLastNameLen {
+		errors["lastName"] = fmt.Sprintf("lastName length must be at least %d characters", minLastNameLen)
+	}
+	if len(p.Email) > 0 && !isEmailValid(p.Email) {
+		errors["email"] = fmt.Sprintf("email %s is invalid", p.Email)
+	}
+	return errors
+}
+
+type DeleteAccountParams struct {
+	CurrentPassword string `json:"currentPassword"`
+}
+
+func (p DeleteAccountParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.CurrentPassword) < minPasswordLen {
+		errors["currentPassword"] = fmt.Sprintf("current password length must be at least %d characters", minPasswordLen)
+	}
+	return errors
+}
+
+// DeleteUserParams tells what happens to the tasks assigned to a deleted user:
+// they are reassigned to ReassignTo when set, or left unassigned otherwise.
+type DeleteUserParams struct {
+	ReassignTo string `query:"reassignTo"`
+}
+
+// BulkUserParams selects the users of a bulk action. There can be at most 100 of
+// them, so the action fits in a single DynamoDB transaction.
+type BulkUserParams struct {
+	IDs []string `json:"ids"`
+}
+
+func (p BulkUserParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.IDs) == 0 || len(p.IDs) > maxBulkUsers {
+		errors["ids"] = fmt.Sprintf("ids must have between 1 and %d users", maxBulkUsers)
+	}
+	seen := map[string]bool{}
+	for _, id := range p.IDs {
+		if len(id) == 0 || seen[id] {
+			errors["ids"] = "ids must not be empty or repeated"
+		}
+		seen[id] = true
+	}
+	return errors
+}
+
+// BulkUserResult tells whether a bulk action succeeded for one of the users.
+type BulkUserResult struct {
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+type ForgotPasswordParams struct {
+	Email string `json:"email"`
+}
+
+func (p ForgotPasswordParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if !isEmailValid(p.Email) {
+		errors["email"] = fmt.Sprintf("email %s is invalid", p.Email)
+	}
+	return errors
+}
+
+type ForgottenPasswordResetParams struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+func (p ForgottenPasswordResetParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.Token) == 0 {
+		errors["token"] = "token is required"
+	}
+	if err := CheckPassword(p.NewPassword); err != nil {
+		errors["newPassword"] = err.Error()
+	}
+	return errors
+}
+func (p ForgottenPasswordResetParams) GeneratePassword() (string, error) {
+	return generateEncryptedPassword(p.NewPassword)
+}
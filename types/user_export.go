@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// UserExport gathers the data tied to a user for data portability requests.
+type UserExport struct {
+	Profile    *User      `json:"profile"`
+	Tasks      []*Task    `json:"tasks"`
+	Projects   []*Project `json:"projects"`
+	ExportedAt time.Time  `json:"exportedAt"`
+}
+
+func NewUserExport(user *User, tasks []*Task, projects []*Project) *UserExport {
+	export := &UserExport{
+		Profile:    user,
+		Tasks:      []*Task{},
+		Projects:   []*Project{},
+		ExportedAt: time.Now().UTC(),
+	}
+	export.Tasks = append(export.Tasks, tasks...)
+	for _, project := range projects {
+		if project.HasRole(user.ID, ProjectRoleOwner) {
+			export.Projects = append(export.Projects, project)
+		}
+	}
+	return export
+}
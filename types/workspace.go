@@ -0,0 +1,129 @@
+package types
+
+import "fmt"
+
+const (
+	// DefaultWorkspaceName names the workspace with an empty ID, which owns the data
+	// created before workspaces existed and the users who sign up on their own.
+	DefaultWorkspaceName = "default"
+	minWorkspaceNameLen  = 3
+)
+
+// Workspace owns the users, projects, tasks and teams of a tenant.
+type Workspace struct {
+	ID   string `bson:"_id,omitempty" dynamodbav:"ID" json:"id"`
+	Name string `bson:"name" dynamodbav:"name" json:"name"`
+}
+
+func NewWorkspaceFromParams(params NewWorkspaceParams) *Workspace {
+	return &Workspace{
+		Name: params.Name,
+	}
+}
+func (w *Workspace) IsDefault() bool {
+	return len(w.ID) == 0
+}
+
+// WorkspaceScoped is implemented by the items stored in a workspace.
+type WorkspaceScoped interface {
+	SetWorkspace(string)
+}
+
+// WorkspaceDataType prefixes the data type with the workspace, so each workspace
+// has its own partition. The default workspace keeps the plain data type.
+func WorkspaceDataType(workspaceID, dataType string) string {
+	if len(workspaceID) == 0 {
+		return dataType
+	}
+	return workspaceID + "#" + dataType
+}
+
+type NewWorkspaceParams struct {
+	Name       string `json:"name"`
+	AdminEmail string `json:"adminEmail"`
+}
+
+func (p NewWorkspaceParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.Name) < minWorkspaceNameLen {
+		errors["name"] = fmt.Sprintf("name length must be at least %d characters", minWorkspaceNameLen)
+	}
+	if !isEmailValid(p.AdminEmail) {
+		errors["adminEmail"] = fmt.Sprintf("email %s is invalid", p.AdminEmail)
+	}
+	return errors
+}
+
+type UpdateWorkspaceParams struct {
+	Name string `json:"name"`
+}
+
+func (p UpdateWorkspaceParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.Name) < minWorkspaceNameLen {
+		errors["name"] = fmt.Sprintf("name length must be at least %d characters", minWorkspaceNameLen)
+	}
+	return errors
+}
+
+// InviteUserParams invites a new user to the workspace, who gets the roles once
+// the invitation is accepted.
+type InviteUserParams struct {
+	Email string `json:"email"`
+	Roles []Role `json:"roles"`
+}
+
+func (p InviteUserParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if !isEmailValid(p.Email) {
+		errors["email"] = fmt.Sprintf("email %s is invalid", p.Email)
+	}
+	for _, role := range p.Roles {
+		if !role.IsValid() {
+			errors["roles"] = fmt.Sprintf("role %s is invalid", role)
+		}
+	}
+	return errors
+}
+
+type AcceptInvitationParams struct {
+	Token     string `json:"token"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Password  string `json:"password"`
+}
+
+func (p AcceptInvitationParams) Validate() map[string]string {
+	errors := map[string]string{}
+	if len(p.Token) == 0 {
+		errors["token"] = "token is required"
+	}
+	if len(p.FirstName) < minFirstNameLen {
+		errors["firstName"] = fmt.Sprintf("firstName length must be at least %d characters", minFirstNameLen)
+	}
+	if len(p.LastName) < minLastNameLen {
+		errors["lastName"] = fmt.Sprintf("lastName length must be at least %d characters", minLastNameLen)
+	}
+	if err := CheckPassword(p.Password, p.FirstName, p.LastName); err != nil {
+		errors["password"] = err.Error()
+	}
+	return errors
+}
+
+// NewInvitedUser returns the user of an accepted invitation. The invitation was sent
+// to its email, so the user is enabled and verified.
+func NewInvitedUser(email string, roles []Role, params AcceptInvitationParams) (*User, error) {
+	user, err := NewUserFromParams(CreateUserParams{
+		FirstName: params.FirstName,
+		LastName:  params.LastName,
+		Email:     email,
+		Password:  params.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.Enabled = true
+	user.Verified = true
+	user.Roles = roles
+	return user, nil
+}